@@ -0,0 +1,114 @@
+// Command s3bench measures gateway throughput and latency using the same
+// config file and helpers as the compatibility suite.
+//
+//	go run ./cmd/s3bench -config config.yaml -workers 16 -duration 1m -mix put=20,get=70,list=10
+//
+// Flags override the bench section of the config file. The report lists
+// ops/s, MiB/s and latency percentiles per operation, with failures grouped
+// by S3 error code.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+func main() {
+
+	configPath := flag.String("config", "", "path to the suite config file (default: $"+helpers.ConfigEnv+", then config.yaml found in . or ..)")
+	bucket := flag.String("bucket", "", "bucket to run against (default: a new prefixed bucket)")
+	workers := flag.Int("workers", 0, "number of concurrent workers")
+	duration := flag.Duration("duration", 0, "how long to run")
+	size := flag.String("size", "", "object size for put, e.g. 64KiB")
+	multipartSize := flag.String("multipart-size", "", "object size for multipart uploads, e.g. 10MiB")
+	keys := flag.Int("keys", 0, "number of distinct keys to spread operations over")
+	mix := flag.String("mix", "", "operation weights, e.g. put=30,get=50,head=10,list=5,delete=5,multipart=0")
+	keep := flag.Bool("keep", false, "keep the bucket and its objects after the run")
+	flag.Parse()
+
+	configErr := helpers.ConfigErr()
+	if *configPath != "" {
+		configErr = helpers.LoadConfigFile(*configPath)
+	}
+	if configErr != nil {
+		fatalf("loading config: %v", configErr)
+	}
+
+	conf, err := helpers.GetBenchConfig()
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if *bucket != "" {
+		conf.Bucket = *bucket
+	}
+	if *workers > 0 {
+		conf.Workers = *workers
+	}
+	if *duration > 0 {
+		conf.Duration = *duration
+	}
+	if *keys > 0 {
+		conf.Keys = *keys
+	}
+	if *size != "" {
+		if conf.ObjectSize, err = helpers.ParseSize(*size); err != nil {
+			fatalf("-size: %v", err)
+		}
+	}
+	if *multipartSize != "" {
+		if conf.MultipartSize, err = helpers.ParseSize(*multipartSize); err != nil {
+			fatalf("-multipart-size: %v", err)
+		}
+	}
+	if *mix != "" {
+		if conf.Mix, err = helpers.ParseMix(*mix); err != nil {
+			fatalf("-mix: %v", err)
+		}
+	}
+	if conf.Seed == 0 {
+		conf.Seed = time.Now().UnixNano()
+	}
+
+	svc := helpers.NewConn()
+
+	created := false
+	if conf.Bucket == "" {
+		conf.Bucket = helpers.GetBucketName()
+		if err := helpers.CreateBucket(svc, conf.Bucket); err != nil {
+			fatalf("creating bucket %s: %v", conf.Bucket, err)
+		}
+		created = true
+	}
+
+	fmt.Printf("bucket %s, %d workers, %v, %d keys, mix %v\n", conf.Bucket, conf.Workers, conf.Duration, conf.Keys, conf.Mix)
+
+	result, runErr := helpers.RunBench(svc, conf)
+
+	if !*keep {
+		if err := helpers.EmptyBucket(svc, conf.Bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "emptying bucket %s: %v\n", conf.Bucket, err)
+		}
+		if created {
+			if err := helpers.DeleteBucket(svc, conf.Bucket); err != nil {
+				fmt.Fprintf(os.Stderr, "deleting bucket %s: %v\n", conf.Bucket, err)
+			}
+		}
+	}
+
+	if runErr != nil {
+		fatalf("%v", runErr)
+	}
+
+	result.Report(os.Stdout)
+}
+
+func fatalf(format string, args ...interface{}) {
+
+	fmt.Fprintf(os.Stderr, "s3bench: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Predefined S3 groups that can be granted permissions.
+const (
+	AllUsersURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	AuthenticatedUsersURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	LogDeliveryURI        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+)
+
+// ACLPermissions lists the grantable permissions, weakest first.
+var ACLPermissions = []string{
+	s3.PermissionRead,
+	s3.PermissionWrite,
+	s3.PermissionReadAcp,
+	s3.PermissionWriteAcp,
+	s3.PermissionFullControl,
+}
+
+// GetOwner returns the canonical user svc is authenticated as, taken from
+// the owner of its bucket list.
+func GetOwner(svc *s3.S3) (*s3.Owner, error) {
+
+	out, err := svc.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+	if out.Owner == nil || aws.StringValue(out.Owner.ID) == "" {
+		return nil, fmt.Errorf("ListBuckets returned no owner")
+	}
+
+	return out.Owner, nil
+}
+
+// CanonicalGrant grants permission to the user with canonical id.
+func CanonicalGrant(id string, permission string) *s3.Grant {
+
+	return &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: aws.String(id)},
+		Permission: aws.String(permission),
+	}
+}
+
+// EmailGrant grants permission to the user registered with email.
+func EmailGrant(email string, permission string) *s3.Grant {
+
+	return &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeAmazonCustomerByEmail), EmailAddress: aws.String(email)},
+		Permission: aws.String(permission),
+	}
+}
+
+// GroupGrant grants permission to the group with uri, e.g. AllUsersURI.
+func GroupGrant(uri string, permission string) *s3.Grant {
+
+	return &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeGroup), URI: aws.String(uri)},
+		Permission: aws.String(permission),
+	}
+}
+
+// HasGrant reports whether grants contain permission for the grantee of
+// want, matched by ID, URI or email according to its type.
+func HasGrant(grants []*s3.Grant, want *s3.Grant) bool {
+
+	for _, g := range grants {
+		if g.Grantee == nil || aws.StringValue(g.Permission) != aws.StringValue(want.Permission) {
+			continue
+		}
+		switch aws.StringValue(want.Grantee.Type) {
+		case s3.TypeCanonicalUser:
+			if aws.StringValue(g.Grantee.ID) == aws.StringValue(want.Grantee.ID) {
+				return true
+			}
+		case s3.TypeGroup:
+			if aws.StringValue(g.Grantee.URI) == aws.StringValue(want.Grantee.URI) {
+				return true
+			}
+		case s3.TypeAmazonCustomerByEmail:
+			if strings.EqualFold(aws.StringValue(g.Grantee.EmailAddress), aws.StringValue(want.Grantee.EmailAddress)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// GetBucketACL returns the access control policy of bucket.
+func GetBucketACL(svc *s3.S3, bucket string) (*s3.GetBucketAclOutput, error) {
+
+	return svc.GetBucketAcl(&s3.GetBucketAclInput{Bucket: aws.String(bucket)})
+}
+
+// GetObjectACL returns the access control policy of bucket/key.
+func GetObjectACL(svc *s3.S3, bucket string, key string) (*s3.GetObjectAclOutput, error) {
+
+	return svc.GetObjectAcl(&s3.GetObjectAclInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+}
+
+// PutBucketGrants replaces the ACL of bucket with owner and grants.
+func PutBucketGrants(svc *s3.S3, bucket string, owner *s3.Owner, grants ...*s3.Grant) error {
+
+	_, err := svc.PutBucketAcl(&s3.PutBucketAclInput{
+		Bucket:              aws.String(bucket),
+		AccessControlPolicy: &s3.AccessControlPolicy{Owner: owner, Grants: grants},
+	})
+
+	return err
+}
+
+// PutObjectGrants replaces the ACL of bucket/key with owner and grants.
+func PutObjectGrants(svc *s3.S3, bucket string, key string, owner *s3.Owner, grants ...*s3.Grant) error {
+
+	_, err := svc.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket:              aws.String(bucket),
+		Key:                 aws.String(key),
+		AccessControlPolicy: &s3.AccessControlPolicy{Owner: owner, Grants: grants},
+	})
+
+	return err
+}
+
+// GrantHeaders are the x-amz-grant-* headers of an ACL request. Each value
+// is a comma-separated list of grantees, see Grantees.
+type GrantHeaders struct {
+	Read        string
+	Write       string
+	ReadACP     string
+	WriteACP    string
+	FullControl string
+}
+
+// Grantees formats grants for a GrantHeaders field, e.g.
+// `id="1234", uri="http://acs.amazonaws.com/groups/global/AllUsers"`. The
+// permission of each grant is ignored.
+func Grantees(grants ...*s3.Grant) string {
+
+	values := make([]string, 0, len(grants))
+	for _, g := range grants {
+		switch aws.StringValue(g.Grantee.Type) {
+		case s3.TypeCanonicalUser:
+			values = append(values, fmt.Sprintf("id=%q", aws.StringValue(g.Grantee.ID)))
+		case s3.TypeGroup:
+			values = append(values, fmt.Sprintf("uri=%q", aws.StringValue(g.Grantee.URI)))
+		case s3.TypeAmazonCustomerByEmail:
+			values = append(values, fmt.Sprintf("emailAddress=%q", aws.StringValue(g.Grantee.EmailAddress)))
+		}
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func optionalString(s string) *string {
+
+	if s == "" {
+		return nil
+	}
+
+	return aws.String(s)
+}
+
+// PutBucketGrantHeaders replaces the ACL of bucket with the grants of h.
+func PutBucketGrantHeaders(svc *s3.S3, bucket string, h GrantHeaders) error {
+
+	_, err := svc.PutBucketAcl(&s3.PutBucketAclInput{
+		Bucket:           aws.String(bucket),
+		GrantRead:        optionalString(h.Read),
+		GrantWrite:       optionalString(h.Write),
+		GrantReadACP:     optionalString(h.ReadACP),
+		GrantWriteACP:    optionalString(h.WriteACP),
+		GrantFullControl: optionalString(h.FullControl),
+	})
+
+	return err
+}
+
+// PutObjectGrantHeaders replaces the ACL of bucket/key with the grants of h.
+func PutObjectGrantHeaders(svc *s3.S3, bucket string, key string, h GrantHeaders) error {
+
+	_, err := svc.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket:           aws.String(bucket),
+		Key:              aws.String(key),
+		GrantRead:        optionalString(h.Read),
+		GrantWrite:       optionalString(h.Write),
+		GrantReadACP:     optionalString(h.ReadACP),
+		GrantWriteACP:    optionalString(h.WriteACP),
+		GrantFullControl: optionalString(h.FullControl),
+	})
+
+	return err
+}
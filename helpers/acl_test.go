@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasGrant(t *testing.T) {
+
+	assert := assert.New(t)
+
+	grants := []*s3.Grant{
+		CanonicalGrant("owner", s3.PermissionFullControl),
+		GroupGrant(AllUsersURI, s3.PermissionRead),
+		EmailGrant("Alt@Example.com", s3.PermissionWrite),
+	}
+
+	assert.True(HasGrant(grants, CanonicalGrant("owner", s3.PermissionFullControl)))
+	assert.False(HasGrant(grants, CanonicalGrant("owner", s3.PermissionRead)))
+	assert.False(HasGrant(grants, CanonicalGrant("other", s3.PermissionFullControl)))
+	assert.True(HasGrant(grants, GroupGrant(AllUsersURI, s3.PermissionRead)))
+	assert.False(HasGrant(grants, GroupGrant(AuthenticatedUsersURI, s3.PermissionRead)))
+	assert.True(HasGrant(grants, EmailGrant("alt@example.com", s3.PermissionWrite)))
+}
+
+func TestGrantees(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal(`id="1234", uri="`+AllUsersURI+`", emailAddress="a@b.c"`, Grantees(
+		CanonicalGrant("1234", s3.PermissionRead),
+		GroupGrant(AllUsersURI, s3.PermissionRead),
+		EmailGrant("a@b.c", s3.PermissionRead)))
+	assert.Equal("", Grantees())
+}
+
+func TestGrantRequests(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var header http.Header
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	assert.Nil(PutObjectGrantHeaders(client, "bucket", "key", GrantHeaders{
+		Read:        Grantees(GroupGrant(AllUsersURI, "")),
+		FullControl: Grantees(CanonicalGrant("owner", "")),
+	}))
+	assert.Equal(`uri="`+AllUsersURI+`"`, header.Get("X-Amz-Grant-Read"))
+	assert.Equal(`id="owner"`, header.Get("X-Amz-Grant-Full-Control"))
+	_, ok := header["X-Amz-Grant-Write"]
+	assert.False(ok)
+
+	owner := &s3.Owner{ID: aws.String("owner")}
+	assert.Nil(PutBucketGrants(client, "bucket", owner, CanonicalGrant("owner", s3.PermissionFullControl), GroupGrant(AllUsersURI, s3.PermissionRead)))
+	assert.Contains(body, `<Owner><ID>owner</ID></Owner>`)
+	assert.Contains(body, `xsi:type="CanonicalUser"`)
+	assert.Contains(body, `<URI>`+AllUsersURI+`</URI>`)
+}
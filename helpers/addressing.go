@@ -0,0 +1,129 @@
+package helpers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/spf13/viper"
+)
+
+// AddressingStyle is where a client puts the bucket name in a request.
+type AddressingStyle string
+
+const (
+	// PathStyle sends bucket requests to endpoint/bucket/key.
+	PathStyle AddressingStyle = "path"
+	// VirtualHostedStyle sends them to bucket.domain/key.
+	VirtualHostedStyle AddressingStyle = "virtual"
+)
+
+// AddressingStyles returns the styles the suite runs in, from
+// s3main.addressing_style: path (the default), virtual or both. An unknown
+// style falls back to path; the config validation reports it.
+func AddressingStyles() []AddressingStyle {
+
+	switch viper.GetString("s3main.addressing_style") {
+	case string(VirtualHostedStyle):
+		return []AddressingStyle{VirtualHostedStyle}
+	case "both":
+		return []AddressingStyle{PathStyle, VirtualHostedStyle}
+	default:
+		return []AddressingStyle{PathStyle}
+	}
+}
+
+// VirtualHostDomain returns the domain the gateway serves virtual-hosted
+// buckets under, s3main.vhost_domain, defaulting to the endpoint host.
+func VirtualHostDomain() string {
+
+	if domain := viper.GetString("s3main.vhost_domain"); domain != "" {
+		return domain
+	}
+
+	host, _, err := net.SplitHostPort(viper.GetString("s3main.endpoint"))
+	if err != nil {
+		return viper.GetString("s3main.endpoint")
+	}
+
+	return host
+}
+
+// HasVirtualHosting reports whether virtual-hosted requests can be made:
+// bucket names cannot be prefixed to an IP address.
+func HasVirtualHosting() bool {
+
+	return net.ParseIP(VirtualHostDomain()) == nil
+}
+
+// VirtualHost returns the Host of virtual-hosted requests to bucket, with
+// the endpoint port; an empty bucket gives the bare domain.
+func VirtualHost(bucket string) string {
+
+	host := VirtualHostDomain()
+	if bucket != "" {
+		host = bucket + "." + host
+	}
+	if _, port, err := net.SplitHostPort(viper.GetString("s3main.endpoint")); err == nil {
+		host = net.JoinHostPort(host, port)
+	}
+
+	return host
+}
+
+// VirtualHostDialer dials connections for the virtual host domain and any
+// of its subdomains to the configured endpoint, so bucket hostnames work
+// without DNS. Other addresses are dialed as given.
+func VirtualHostDialer() func(ctx context.Context, network string, addr string) (net.Conn, error) {
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	domain := strings.ToLower(VirtualHostDomain())
+	endpoint := viper.GetString("s3main.endpoint")
+
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		host = strings.ToLower(host)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			addr = endpoint
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+func newStyledConfig(creds *credentials.Credentials, style AddressingStyle) *aws.Config {
+
+	config := newConfig(creds)
+	if style != VirtualHostedStyle {
+		return config
+	}
+
+	return config.
+		WithEndpoint(VirtualHost("")).
+		WithS3ForcePathStyle(false).
+		WithHTTPClient(&http.Client{Transport: newTransport(VirtualHostDialer())})
+}
+
+// NewStyledConn returns a client signing with creds that addresses buckets
+// in style. Its traffic is recorded like that of the shared clients.
+func NewStyledConn(creds *credentials.Credentials, style AddressingStyle) *s3.S3 {
+
+	client := s3.New(sess, newStyledConfig(creds, style).WithLogLevel(logLevel()))
+	Cassettes.Install(&client.Handlers)
+
+	return client
+}
+
+// NewAnonConn returns a client that sends unsigned requests, addressing
+// buckets like the shared clients.
+func NewAnonConn() *s3.S3 {
+
+	return NewStyledConn(credentials.AnonymousCredentials, AddressingStyles()[0])
+}
@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// withConfig sets config keys for the duration of a test.
+func withConfig(t *testing.T, values map[string]string) {
+
+	old := map[string]interface{}{}
+	for key, value := range values {
+		old[key] = viper.Get(key)
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for key, value := range old {
+			viper.Set(key, value)
+		}
+	})
+}
+
+func TestAddressingStyles(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for style, want := range map[string][]AddressingStyle{
+		"":        {PathStyle},
+		"path":    {PathStyle},
+		"virtual": {VirtualHostedStyle},
+		"both":    {PathStyle, VirtualHostedStyle},
+	} {
+		withConfig(t, map[string]string{"s3main.addressing_style": style})
+		assert.Equal(want, AddressingStyles(), style)
+	}
+
+	withConfig(t, map[string]string{"s3main.addressing_style": "dns"})
+	assert.Equal([]AddressingStyle{PathStyle}, AddressingStyles())
+	assert.Contains(validateConfig(), `s3main.addressing_style: "dns" is not one of path, virtual, both`)
+}
+
+func TestVirtualStyleNeedsDomain(t *testing.T) {
+
+	assert := assert.New(t)
+	want := `s3main.addressing_style virtual needs a domain for bucket hostnames, but "127.0.0.1" is an IP address: set s3main.vhost_domain`
+
+	withConfig(t, map[string]string{"s3main.endpoint": "127.0.0.1:8000", "s3main.vhost_domain": "", "s3main.addressing_style": "virtual"})
+	assert.Contains(validateConfig(), want)
+
+	withConfig(t, map[string]string{"s3main.addressing_style": "path"})
+	assert.NotContains(validateConfig(), want)
+
+	withConfig(t, map[string]string{"s3main.addressing_style": "both", "s3main.vhost_domain": "s3.local"})
+	for _, problem := range validateConfig() {
+		assert.False(strings.HasPrefix(problem, "s3main.addressing_style"), problem)
+	}
+}
+
+func TestVirtualHost(t *testing.T) {
+
+	assert := assert.New(t)
+
+	withConfig(t, map[string]string{"s3main.endpoint": "127.0.0.1:8000", "s3main.vhost_domain": ""})
+	assert.Equal("127.0.0.1", VirtualHostDomain())
+	assert.False(HasVirtualHosting())
+
+	withConfig(t, map[string]string{"s3main.vhost_domain": "s3.local"})
+	assert.True(HasVirtualHosting())
+	assert.Equal("s3.local:8000", VirtualHost(""))
+	assert.Equal("my.bucket.s3.local:8000", VirtualHost("my.bucket"))
+
+	r := NewVirtualRawRequest("PUT", "bucket", "a b/c", "bar")
+	assert.Equal("/a%20b/c", r.Path)
+	assert.Equal("bucket.s3.local:8000", r.Host)
+
+	c := testRawClient("127.0.0.1:8000")
+	head, err := c.Encode(r, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Nil(err)
+	lines := strings.Split(string(head), "\r\n")
+	assert.Equal("PUT /a%20b/c HTTP/1.1", lines[0])
+	assert.Equal("Host: bucket.s3.local:8000", lines[1])
+}
+
+func TestVirtualHostDialer(t *testing.T) {
+
+	assert := assert.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	withConfig(t, map[string]string{"s3main.endpoint": ln.Addr().String(), "s3main.vhost_domain": "s3.invalid"})
+	dial := VirtualHostDialer()
+
+	for _, addr := range []string{"s3.invalid:" + port, "bucket.s3.invalid:" + port, "A.B.S3.INVALID:" + port} {
+		conn, err := dial(context.Background(), "tcp", addr)
+		if assert.Nil(err, addr) {
+			assert.Equal(ln.Addr().String(), conn.RemoteAddr().String())
+			conn.Close()
+		}
+	}
+
+	_, err = dial(context.Background(), "tcp", "elsewhere.invalid:"+port)
+	assert.NotNil(err)
+}
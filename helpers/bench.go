@@ -0,0 +1,427 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/spf13/viper"
+)
+
+// Operations the load generator knows how to issue.
+const (
+	OpPut       = "put"
+	OpGet       = "get"
+	OpHead      = "head"
+	OpList      = "list"
+	OpDelete    = "delete"
+	OpMultipart = "multipart"
+)
+
+var benchOps = []string{OpPut, OpGet, OpHead, OpList, OpDelete, OpMultipart}
+
+// BenchConfig describes one load run.
+type BenchConfig struct {
+	Bucket        string
+	Workers       int
+	Duration      time.Duration
+	ObjectSize    int64
+	MultipartSize int64
+	Keys          int
+	Mix           map[string]int
+	Prefill       bool
+	Seed          int64
+}
+
+// ParseMix parses an operation mix such as "put=40,get=50,list=10" into
+// relative weights.
+func ParseMix(s string) (map[string]int, error) {
+
+	mix := map[string]int{}
+
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		kv := strings.SplitN(term, "=", 2)
+		op := strings.ToLower(strings.TrimSpace(kv[0]))
+		if !Contains(benchOps, op) {
+			return nil, fmt.Errorf("unknown operation %q in mix", op)
+		}
+
+		weight := 1
+		if len(kv) == 2 {
+			w, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight %q for %s", kv[1], op)
+			}
+			weight = w
+		}
+		mix[op] = weight
+	}
+
+	total := 0
+	for _, w := range mix {
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("operation mix %q has no weight", s)
+	}
+
+	return mix, nil
+}
+
+func viperDefault(key string, value interface{}) interface{} {
+
+	if viper.IsSet(key) {
+		return viper.Get(key)
+	}
+
+	return value
+}
+
+// GetBenchConfig reads the bench section of the config, filling in defaults.
+func GetBenchConfig() (BenchConfig, error) {
+
+	conf := BenchConfig{
+		Bucket:  viper.GetString("bench.bucket"),
+		Workers: viper.GetInt("bench.workers"),
+		Keys:    viper.GetInt("bench.keys"),
+		Prefill: !viper.IsSet("bench.prefill") || viper.GetBool("bench.prefill"),
+		Seed:    viper.GetInt64("bench.seed"),
+	}
+
+	if conf.Workers <= 0 {
+		conf.Workers = 8
+	}
+	if conf.Keys <= 0 {
+		conf.Keys = 100
+	}
+
+	var err error
+	if conf.Duration, err = time.ParseDuration(fmt.Sprint(viperDefault("bench.duration", "30s"))); err != nil {
+		return conf, fmt.Errorf("bench.duration: %v", err)
+	}
+	if conf.ObjectSize, err = ParseSize(fmt.Sprint(viperDefault("bench.object_size", "64KiB"))); err != nil {
+		return conf, fmt.Errorf("bench.object_size: %v", err)
+	}
+	if conf.MultipartSize, err = ParseSize(fmt.Sprint(viperDefault("bench.multipart_size", "10MiB"))); err != nil {
+		return conf, fmt.Errorf("bench.multipart_size: %v", err)
+	}
+	if conf.Mix, err = ParseMix(fmt.Sprint(viperDefault("bench.mix", "put=30,get=50,head=10,list=5,delete=5"))); err != nil {
+		return conf, fmt.Errorf("bench.mix: %v", err)
+	}
+
+	return conf, nil
+}
+
+// ErrorCode classifies err by its S3 error code. Errors that never reached
+// the server are reported under their SDK code, anything else as "ClientError".
+func ErrorCode(err error) string {
+
+	if err == nil {
+		return ""
+	}
+
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+
+	return "ClientError"
+}
+
+// OpStats accumulates the outcome of every call of one operation.
+type OpStats struct {
+	Op         string
+	Count      int64
+	Bytes      int64
+	Latencies  []time.Duration
+	ErrorCodes map[string]int64
+}
+
+func newOpStats(op string) *OpStats {
+
+	return &OpStats{Op: op, ErrorCodes: map[string]int64{}}
+}
+
+func (s *OpStats) record(d time.Duration, bytes int64, err error) {
+
+	s.Count++
+	s.Latencies = append(s.Latencies, d)
+	if err != nil {
+		s.ErrorCodes[ErrorCode(err)]++
+		return
+	}
+	s.Bytes += bytes
+}
+
+func (s *OpStats) merge(other *OpStats) {
+
+	s.Count += other.Count
+	s.Bytes += other.Bytes
+	s.Latencies = append(s.Latencies, other.Latencies...)
+	for code, n := range other.ErrorCodes {
+		s.ErrorCodes[code] += n
+	}
+}
+
+// Errors returns the number of failed calls.
+func (s *OpStats) Errors() int64 {
+
+	var n int64
+	for _, c := range s.ErrorCodes {
+		n += c
+	}
+
+	return n
+}
+
+// Percentile returns the latency below which p percent of calls completed,
+// using the nearest-rank method.
+func (s *OpStats) Percentile(p float64) time.Duration {
+
+	return percentile(s.Latencies, p)
+}
+
+func percentile(durations []time.Duration, p float64) time.Duration {
+
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
+
+// BenchResult holds the merged statistics of a run.
+type BenchResult struct {
+	Elapsed time.Duration
+	Ops     map[string]*OpStats
+}
+
+// Report writes a per-operation table of throughput, latency percentiles and
+// errors grouped by S3 error code.
+func (r *BenchResult) Report(w io.Writer) {
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "op\tcount\tops/s\tMiB/s\tp50\tp90\tp99\tmax\terrors")
+
+	secs := r.Elapsed.Seconds()
+	for _, op := range benchOps {
+		s, ok := r.Ops[op]
+		if !ok || s.Count == 0 {
+			continue
+		}
+
+		codes := []string{}
+		for code, n := range s.ErrorCodes {
+			codes = append(codes, fmt.Sprintf("%s=%d", code, n))
+		}
+		sort.Strings(codes)
+
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.2f\t%v\t%v\t%v\t%v\t%s\n", op, s.Count,
+			float64(s.Count)/secs, float64(s.Bytes)/secs/(1<<20),
+			s.Percentile(50), s.Percentile(90), s.Percentile(99), s.Percentile(100),
+			strings.Join(codes, " "))
+	}
+
+	tw.Flush()
+}
+
+func pickOp(r *rand.Rand, mix map[string]int) string {
+
+	total := 0
+	for _, op := range benchOps {
+		total += mix[op]
+	}
+
+	n := r.Intn(total)
+	for _, op := range benchOps {
+		if n < mix[op] {
+			return op
+		}
+		n -= mix[op]
+	}
+
+	return benchOps[0]
+}
+
+func benchKey(i int) string {
+
+	return fmt.Sprintf("bench/%06d", i)
+}
+
+func runOp(svc *s3.S3, conf BenchConfig, op string, key string) (int64, error) {
+
+	bucket := aws.String(conf.Bucket)
+
+	switch op {
+	case OpPut:
+		_, err := svc.PutObject(&s3.PutObjectInput{Bucket: bucket, Key: aws.String(key),
+			Body: NewPatternReader(conf.Seed, conf.ObjectSize)})
+		return conf.ObjectSize, err
+
+	case OpGet:
+		resp, err := svc.GetObject(&s3.GetObjectInput{Bucket: bucket, Key: aws.String(key)})
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		return io.Copy(ioutil.Discard, resp.Body)
+
+	case OpHead:
+		_, err := svc.HeadObject(&s3.HeadObjectInput{Bucket: bucket, Key: aws.String(key)})
+		return 0, err
+
+	case OpList:
+		_, err := svc.ListObjects(&s3.ListObjectsInput{Bucket: bucket, Prefix: aws.String("bench/"), MaxKeys: aws.Int64(100)})
+		return 0, err
+
+	case OpDelete:
+		_, err := svc.DeleteObject(&s3.DeleteObjectInput{Bucket: bucket, Key: aws.String(key)})
+		return 0, err
+
+	case OpMultipart:
+		_, err := UploadPattern(svc, conf.Bucket, key, conf.Seed, conf.MultipartSize, s3manager.MinUploadPartSize, 1)
+		return conf.MultipartSize, err
+	}
+
+	return 0, fmt.Errorf("unknown operation %q", op)
+}
+
+// RunBench drives conf.Workers workers issuing the configured operation mix
+// against conf.Bucket for conf.Duration and returns the merged statistics.
+// With Prefill set, every key is written once before timing starts so reads
+// do not just measure NoSuchKey.
+func RunBench(svc *s3.S3, conf BenchConfig) (*BenchResult, error) {
+
+	if conf.Prefill {
+		keys := make(chan int)
+		errs := make(chan error, conf.Workers)
+		var wg sync.WaitGroup
+
+		for w := 0; w < conf.Workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range keys {
+					if _, err := runOp(svc, conf, OpPut, benchKey(i)); err != nil {
+						errs <- fmt.Errorf("prefill %s: %v", benchKey(i), err)
+						return
+					}
+				}
+			}()
+		}
+
+		for i := 0; i < conf.Keys; i++ {
+			select {
+			case keys <- i:
+			case err := <-errs:
+				close(keys)
+				wg.Wait()
+				return nil, err
+			}
+		}
+		close(keys)
+		wg.Wait()
+
+		select {
+		case err := <-errs:
+			return nil, err
+		default:
+		}
+	}
+
+	perWorker := make([]map[string]*OpStats, conf.Workers)
+	var wg sync.WaitGroup
+	start := time.Now()
+	deadline := start.Add(conf.Duration)
+
+	for w := 0; w < conf.Workers; w++ {
+		stats := map[string]*OpStats{}
+		for _, op := range benchOps {
+			stats[op] = newOpStats(op)
+		}
+		perWorker[w] = stats
+
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(conf.Seed + int64(w)))
+			for time.Now().Before(deadline) {
+				op := pickOp(r, conf.Mix)
+				key := benchKey(r.Intn(conf.Keys))
+
+				t := time.Now()
+				n, err := runOp(svc, conf, op, key)
+				stats[op].record(time.Since(t), n, err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	result := &BenchResult{Elapsed: time.Since(start), Ops: map[string]*OpStats{}}
+	for _, op := range benchOps {
+		result.Ops[op] = newOpStats(op)
+		for _, stats := range perWorker {
+			result.Ops[op].merge(stats[op])
+		}
+	}
+
+	return result, nil
+}
+
+// EmptyBucket deletes every object in bucket, page by page.
+func EmptyBucket(svc *s3.S3, bucket string) error {
+
+	var deleteErr error
+
+	err := svc.ListObjectsPages(&s3.ListObjectsInput{Bucket: aws.String(bucket)},
+		func(page *s3.ListObjectsOutput, last bool) bool {
+			if len(page.Contents) == 0 {
+				return true
+			}
+
+			objs := make([]*s3.ObjectIdentifier, len(page.Contents))
+			for i, o := range page.Contents {
+				objs[i] = &s3.ObjectIdentifier{Key: o.Key}
+			}
+
+			_, deleteErr = svc.DeleteObjects(&s3.DeleteObjectsInput{
+				Bucket: aws.String(bucket),
+				Delete: &s3.Delete{Objects: objs, Quiet: aws.Bool(true)},
+			})
+
+			return deleteErr == nil
+		})
+
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	return err
+}
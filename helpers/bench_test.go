@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMix(t *testing.T) {
+
+	assert := assert.New(t)
+
+	mix, err := ParseMix("put=40, GET=50,list")
+	assert.Nil(err)
+	assert.Equal(map[string]int{"put": 40, "get": 50, "list": 1}, mix)
+
+	for _, in := range []string{"", "put=0", "copy=1", "put=x", "get=-1"} {
+		_, err := ParseMix(in)
+		assert.NotNil(err, in)
+	}
+}
+
+func TestPickOpFollowsMix(t *testing.T) {
+
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(1))
+	mix := map[string]int{OpGet: 3, OpPut: 1}
+	counts := map[string]int{}
+
+	for i := 0; i < 4000; i++ {
+		counts[pickOp(r, mix)]++
+	}
+
+	assert.Equal(2, len(counts))
+	assert.InDelta(3000, counts[OpGet], 150)
+	assert.InDelta(1000, counts[OpPut], 150)
+}
+
+func TestOpStatsPercentile(t *testing.T) {
+
+	assert := assert.New(t)
+	s := newOpStats(OpGet)
+
+	assert.Equal(time.Duration(0), s.Percentile(50))
+
+	for i := 100; i >= 1; i-- {
+		s.record(time.Duration(i)*time.Millisecond, 10, nil)
+	}
+
+	assert.Equal(50*time.Millisecond, s.Percentile(50))
+	assert.Equal(99*time.Millisecond, s.Percentile(99))
+	assert.Equal(100*time.Millisecond, s.Percentile(100))
+	assert.Equal(time.Millisecond, s.Percentile(0))
+	assert.Equal(int64(1000), s.Bytes)
+}
+
+func TestErrorCode(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("", ErrorCode(nil))
+	assert.Equal("NoSuchKey", ErrorCode(awserr.NewRequestFailure(awserr.New("NoSuchKey", "", nil), 404, "id")))
+	assert.Equal("RequestError", ErrorCode(awserr.New("RequestError", "send request failed", nil)))
+	assert.Equal("ClientError", ErrorCode(errors.New("boom")))
+}
+
+func TestBenchResultReport(t *testing.T) {
+
+	assert := assert.New(t)
+	get := newOpStats(OpGet)
+	get.record(time.Millisecond, 1<<20, nil)
+	get.record(time.Millisecond, 0, awserr.New("NoSuchKey", "", nil))
+
+	var out bytes.Buffer
+	result := &BenchResult{Elapsed: time.Second, Ops: map[string]*OpStats{OpGet: get, OpPut: newOpStats(OpPut)}}
+	result.Report(&out)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(2, len(lines))
+	assert.Contains(lines[1], "NoSuchKey=1")
+	assert.Equal(int64(1), get.Errors())
+}
@@ -0,0 +1,196 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"net/url"
+	"strings"
+)
+
+// ChecksumAlgorithm is one of the additional checksums S3 accepts in
+// x-amz-checksum-* headers and trailers.
+type ChecksumAlgorithm string
+
+const (
+	ChecksumCRC32  ChecksumAlgorithm = "CRC32"
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+	ChecksumSHA1   ChecksumAlgorithm = "SHA1"
+	ChecksumSHA256 ChecksumAlgorithm = "SHA256"
+)
+
+// ChecksumAlgorithms lists every supported algorithm.
+var ChecksumAlgorithms = []ChecksumAlgorithm{ChecksumCRC32, ChecksumCRC32C, ChecksumSHA1, ChecksumSHA256}
+
+func (a ChecksumAlgorithm) hash() hash.Hash {
+
+	switch a {
+	case ChecksumCRC32:
+		return crc32.NewIEEE()
+	case ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case ChecksumSHA1:
+		return sha1.New()
+	case ChecksumSHA256:
+		return sha256.New()
+	}
+
+	panic(fmt.Sprintf("unknown checksum algorithm %q", string(a)))
+}
+
+// Header returns the header carrying the checksum, e.g. x-amz-checksum-crc32.
+func (a ChecksumAlgorithm) Header() string {
+
+	return "x-amz-checksum-" + strings.ToLower(string(a))
+}
+
+// Checksum returns the base64 checksum of data, as S3 expects it.
+func (a ChecksumAlgorithm) Checksum(data []byte) string {
+
+	h := a.hash()
+	h.Write(data)
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// CompositeChecksum returns the checksum S3 reports for a multipart object:
+// the checksum of the concatenated binary part checksums, suffixed with the
+// number of parts.
+func (a ChecksumAlgorithm) CompositeChecksum(parts []string) (string, error) {
+
+	h := a.hash()
+	for _, part := range parts {
+		raw, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return "", err
+		}
+		h.Write(raw)
+	}
+
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(h.Sum(nil)), len(parts)), nil
+}
+
+// NewChecksumPut returns a raw PUT of body to bucket/key with an
+// x-amz-checksum-* header for checksum, which need not be correct.
+func NewChecksumPut(bucket string, key string, body string, a ChecksumAlgorithm, checksum string) *RawRequest {
+
+	return NewRawRequest("PUT", bucket, key, body).
+		Header("X-Amz-Sdk-Checksum-Algorithm", string(a)).
+		Header(a.Header(), checksum)
+}
+
+// NewTrailerPut returns a raw PUT of body to bucket/key encoded as
+// aws-chunked with unsigned chunks of chunkSize bytes and the checksum in a
+// trailer, the way streaming clients send it.
+func NewTrailerPut(bucket string, key string, body string, chunkSize int, a ChecksumAlgorithm, checksum string) *RawRequest {
+
+	var encoded bytes.Buffer
+	for rest := body; len(rest) > 0; {
+		n := chunkSize
+		if n > len(rest) {
+			n = len(rest)
+		}
+		fmt.Fprintf(&encoded, "%x\r\n%s\r\n", n, rest[:n])
+		rest = rest[n:]
+	}
+	fmt.Fprintf(&encoded, "0\r\n%s:%s\r\n\r\n", a.Header(), checksum)
+
+	r := NewRawRequest("PUT", bucket, key, "").
+		Header("Content-Encoding", "aws-chunked").
+		Header("X-Amz-Content-Sha256", "STREAMING-UNSIGNED-PAYLOAD-TRAILER").
+		Header("X-Amz-Decoded-Content-Length", fmt.Sprint(len(body))).
+		Header("X-Amz-Sdk-Checksum-Algorithm", string(a)).
+		Header("X-Amz-Trailer", a.Header())
+	r.Body = encoded.Bytes()
+
+	return r
+}
+
+// ChecksumPart is a part of a checksummed multipart upload as listed in
+// CompleteMultipartUpload.
+type ChecksumPart struct {
+	Number   int
+	ETag     string
+	Checksum string
+}
+
+// CreateChecksumUpload starts a multipart upload of bucket/key using
+// algorithm a and returns its upload ID.
+func CreateChecksumUpload(c *RawClient, bucket string, key string, a ChecksumAlgorithm) (string, *RawResponse, error) {
+
+	r := NewRawRequest("POST", bucket, key, "").Header("X-Amz-Checksum-Algorithm", string(a))
+	r.Query = "uploads"
+	resp, err := c.Do(r)
+	if err != nil || resp.Error != nil {
+		return "", resp, err
+	}
+
+	result := struct {
+		UploadID string `xml:"UploadId"`
+	}{}
+	if err := xml.Unmarshal(resp.Body, &result); err != nil {
+		return "", resp, err
+	}
+
+	return result.UploadID, resp, nil
+}
+
+// UploadChecksumPart uploads data as part number of uploadID with checksum
+// in the header of a.
+func UploadChecksumPart(c *RawClient, bucket string, key string, uploadID string, number int, data string, a ChecksumAlgorithm, checksum string) (*RawResponse, error) {
+
+	r := NewRawRequest("PUT", bucket, key, data).
+		Header("X-Amz-Sdk-Checksum-Algorithm", string(a)).
+		Header(a.Header(), checksum)
+	r.Query = fmt.Sprintf("partNumber=%d&uploadId=%s", number, url.QueryEscape(uploadID))
+
+	return c.Do(r)
+}
+
+// CompleteChecksumUpload completes uploadID with parts, listing the part
+// checksums under the element of a.
+func CompleteChecksumUpload(c *RawClient, bucket string, key string, uploadID string, a ChecksumAlgorithm, parts []ChecksumPart) (*RawResponse, error) {
+
+	var body bytes.Buffer
+	body.WriteString("<CompleteMultipartUpload>")
+	for _, p := range parts {
+		fmt.Fprintf(&body, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag>", p.Number, p.ETag)
+		if p.Checksum != "" {
+			fmt.Fprintf(&body, "<Checksum%s>%s</Checksum%s>", a, p.Checksum, a)
+		}
+		body.WriteString("</Part>")
+	}
+	body.WriteString("</CompleteMultipartUpload>")
+
+	r := NewRawRequest("POST", bucket, key, body.String())
+	r.Query = "uploadId=" + url.QueryEscape(uploadID)
+
+	return c.Do(r)
+}
+
+// CompletedChecksum returns the checksum element of a in the result of
+// CompleteMultipartUpload.
+func CompletedChecksum(resp *RawResponse, a ChecksumAlgorithm) string {
+
+	result := struct {
+		Fields []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	}{}
+	if xml.Unmarshal(resp.Body, &result) != nil {
+		return ""
+	}
+	for _, f := range result.Fields {
+		if f.XMLName.Local == "Checksum"+string(a) {
+			return f.Value
+		}
+	}
+
+	return ""
+}
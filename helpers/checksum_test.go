@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+
+	assert := assert.New(t)
+	data := []byte("hello world")
+
+	assert.Equal("DUoRhQ==", ChecksumCRC32.Checksum(data))
+	assert.Equal("yZRlqg==", ChecksumCRC32C.Checksum(data))
+	assert.Equal("Kq5sNclPz7QV2+lfQIuc6R7oRu0=", ChecksumSHA1.Checksum(data))
+	assert.Equal("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", ChecksumSHA256.Checksum(data))
+	assert.Equal("x-amz-checksum-crc32c", ChecksumCRC32C.Header())
+
+	composite, err := ChecksumSHA256.CompositeChecksum([]string{
+		ChecksumSHA256.Checksum([]byte("a")),
+		ChecksumSHA256.Checksum([]byte("b")),
+	})
+	assert.Nil(err)
+	assert.Equal("5aAf7hTg7VxIcU8iGA8lrYNltT+XefedxKPX6Tlj+Uo=-2", composite)
+
+	_, err = ChecksumCRC32.CompositeChecksum([]string{"not base64!"})
+	assert.NotNil(err)
+}
+
+func TestNewTrailerPut(t *testing.T) {
+
+	assert := assert.New(t)
+
+	r := NewTrailerPut("bucket", "key", "hello world", 4, ChecksumCRC32, "DUoRhQ==")
+	assert.Equal("4\r\nhell\r\n4\r\no wo\r\n3\r\nrld\r\n0\r\nx-amz-checksum-crc32:DUoRhQ==\r\n\r\n", string(r.Body))
+
+	head, err := testRawClient("s3.local:8000").Encode(r, time.Now())
+	assert.Nil(err)
+	lines := strings.Split(string(head), "\r\n")
+	assert.Contains(lines, "X-Amz-Content-Sha256: STREAMING-UNSIGNED-PAYLOAD-TRAILER")
+	assert.Contains(lines, "X-Amz-Decoded-Content-Length: 11")
+	assert.Contains(lines, "Content-Length: 62")
+	assert.Equal(1, strings.Count(string(head), "X-Amz-Content-Sha256"))
+}
+
+func TestChecksumUploadRequests(t *testing.T) {
+
+	assert := assert.New(t)
+	endpoint, sent := rawServer(t, func(r *bufio.Reader, w io.Writer) {
+		body := "<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>up 1</UploadId></InitiateMultipartUploadResult>"
+		io.WriteString(w, "HTTP/1.1 200 OK\r\nContent-Length: "+strconv.Itoa(len(body))+"\r\n\r\n"+body)
+	})
+
+	id, resp, err := CreateChecksumUpload(testRawClient(endpoint), "bucket", "key", ChecksumSHA1)
+	assert.Nil(err)
+	assert.Equal(200, resp.Status)
+	assert.Equal("up 1", id)
+	request := <-sent
+	assert.True(strings.HasPrefix(request, "POST /bucket/key?uploads HTTP/1.1\r\n"))
+	assert.Contains(request, "\r\nX-Amz-Checksum-Algorithm: SHA1\r\n")
+
+	completed := &RawResponse{Body: []byte("<CompleteMultipartUploadResult><ETag>\"x-2\"</ETag><ChecksumSHA1>abc-2</ChecksumSHA1></CompleteMultipartUploadResult>")}
+	assert.Equal("abc-2", CompletedChecksum(completed, ChecksumSHA1))
+	assert.Equal("", CompletedChecksum(completed, ChecksumCRC32))
+}
@@ -0,0 +1,303 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ConditionalOp is an operation that accepts precondition headers.
+type ConditionalOp string
+
+const (
+	CondGet  ConditionalOp = "GET"
+	CondHead ConditionalOp = "HEAD"
+	CondPut  ConditionalOp = "PUT"
+	// CondCopy copies key to key+CopySuffix with the conditions sent as
+	// x-amz-copy-source-if-* headers, so they apply to the source.
+	CondCopy ConditionalOp = "COPY"
+	// CondComplete uploads a single part to key and completes the upload
+	// with the conditions on the CompleteMultipartUpload request.
+	CondComplete ConditionalOp = "COMPLETE"
+)
+
+// CopySuffix is appended to the key to name the destination of CondCopy.
+const CopySuffix = ".copy"
+
+// Conditions holds the four RFC 7232 precondition headers. Empty strings and
+// nil times are not sent.
+type Conditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+// Headers renders the conditions as HTTP headers.
+func (c Conditions) Headers() map[string]string {
+
+	headers := map[string]string{}
+	if c.IfMatch != "" {
+		headers["If-Match"] = c.IfMatch
+	}
+	if c.IfNoneMatch != "" {
+		headers["If-None-Match"] = c.IfNoneMatch
+	}
+	if c.IfModifiedSince != nil {
+		headers["If-Modified-Since"] = c.IfModifiedSince.UTC().Format(http.TimeFormat)
+	}
+	if c.IfUnmodifiedSince != nil {
+		headers["If-Unmodified-Since"] = c.IfUnmodifiedSince.UTC().Format(http.TimeFormat)
+	}
+
+	return headers
+}
+
+// CopyOptions returns the conditions as x-amz-copy-source-if-* options.
+func (c Conditions) CopyOptions() []CopyOption {
+
+	opts := []CopyOption{}
+	if c.IfMatch != "" {
+		opts = append(opts, CopyIfMatch(c.IfMatch))
+	}
+	if c.IfNoneMatch != "" {
+		opts = append(opts, CopyIfNoneMatch(c.IfNoneMatch))
+	}
+	if c.IfModifiedSince != nil {
+		opts = append(opts, CopyIfModifiedSince(*c.IfModifiedSince))
+	}
+	if c.IfUnmodifiedSince != nil {
+		opts = append(opts, CopyIfUnmodifiedSince(*c.IfUnmodifiedSince))
+	}
+
+	return opts
+}
+
+// Expect returns the status RFC 7232 section 6 prescribes for op against an
+// object with the given etag and last-modified time: 200, 304 or 412.
+// exists is false when the target has no current representation.
+//
+// The evaluation order is If-Match, then If-Unmodified-Since only when
+// If-Match is absent, then If-None-Match, then If-Modified-Since only when
+// If-None-Match is absent. A failed If-None-Match or If-Modified-Since is
+// 304 for reads and 412 otherwise; If-Modified-Since only applies to reads
+// and to the copy source.
+func (c Conditions) Expect(op ConditionalOp, exists bool, etag string, lastModified time.Time) int {
+
+	read := op == CondGet || op == CondHead
+
+	if c.IfMatch != "" {
+		if !exists || !etagListMatches(c.IfMatch, etag) {
+			return http.StatusPreconditionFailed
+		}
+	} else if c.IfUnmodifiedSince != nil && exists {
+		if lastModified.Truncate(time.Second).After(*c.IfUnmodifiedSince) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if c.IfNoneMatch != "" {
+		if exists && etagListMatches(c.IfNoneMatch, etag) {
+			if read {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if c.IfModifiedSince != nil && (read || op == CondCopy) && exists {
+		if !lastModified.Truncate(time.Second).After(*c.IfModifiedSince) {
+			if read {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	return http.StatusOK
+}
+
+// etagListMatches reports whether etag matches "*" or any entry of the
+// comma-separated list, using strong comparison.
+func etagListMatches(list string, etag string) bool {
+
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if !strings.HasPrefix(candidate, "W/") && strings.Trim(candidate, `"`) == strings.Trim(etag, `"`) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConditionState is how a precondition header appears in a ConditionCase.
+type ConditionState int
+
+const (
+	ConditionAbsent ConditionState = iota
+	ConditionTrue
+	ConditionFalse
+)
+
+func (s ConditionState) String() string {
+
+	switch s {
+	case ConditionTrue:
+		return "true"
+	case ConditionFalse:
+		return "false"
+	}
+
+	return "absent"
+}
+
+// ConditionCase picks, for each header, whether it is absent or evaluates
+// to true or false against the target object.
+type ConditionCase struct {
+	IfMatch           ConditionState
+	IfNoneMatch       ConditionState
+	IfModifiedSince   ConditionState
+	IfUnmodifiedSince ConditionState
+}
+
+func (c ConditionCase) String() string {
+
+	return fmt.Sprintf("if-match=%v,if-none-match=%v,if-modified-since=%v,if-unmodified-since=%v",
+		c.IfMatch, c.IfNoneMatch, c.IfModifiedSince, c.IfUnmodifiedSince)
+}
+
+// ConditionCases returns all 81 combinations of the four headers.
+func ConditionCases() []ConditionCase {
+
+	states := []ConditionState{ConditionAbsent, ConditionTrue, ConditionFalse}
+	cases := make([]ConditionCase, 0, 81)
+	for _, m := range states {
+		for _, nm := range states {
+			for _, ms := range states {
+				for _, us := range states {
+					cases = append(cases, ConditionCase{m, nm, ms, us})
+				}
+			}
+		}
+	}
+
+	return cases
+}
+
+// Conditions builds the headers for the case against an object with the
+// given etag and last-modified time. Dates are never in the future, since
+// servers may ignore those.
+func (c ConditionCase) Conditions(etag string, lastModified time.Time) Conditions {
+
+	const bogus = `"ABCORZ"`
+	at := lastModified.UTC().Truncate(time.Second)
+	before := at.Add(-24 * time.Hour)
+
+	var cond Conditions
+	switch c.IfMatch {
+	case ConditionTrue:
+		cond.IfMatch = etag
+	case ConditionFalse:
+		cond.IfMatch = bogus
+	}
+	switch c.IfNoneMatch {
+	case ConditionTrue:
+		cond.IfNoneMatch = bogus
+	case ConditionFalse:
+		cond.IfNoneMatch = etag
+	}
+	switch c.IfModifiedSince {
+	case ConditionTrue:
+		cond.IfModifiedSince = &before
+	case ConditionFalse:
+		cond.IfModifiedSince = &at
+	}
+	switch c.IfUnmodifiedSince {
+	case ConditionTrue:
+		cond.IfUnmodifiedSince = &at
+	case ConditionFalse:
+		cond.IfUnmodifiedSince = &before
+	}
+
+	return cond
+}
+
+// ConditionalRequest runs op on bucket/key with cond and returns the HTTP
+// status of the response. PUT and COMPLETE write content to key. err is nil
+// whenever a status was received, including 304 and 412.
+func ConditionalRequest(svc *s3.S3, op ConditionalOp, bucket string, key string, content string, cond Conditions) (int, error) {
+
+	var err error
+	headers := AddHeaders(cond.Headers())
+
+	switch op {
+	case CondGet:
+		var resp *s3.GetObjectOutput
+		resp, err = svc.GetObjectWithContext(aws.BackgroundContext(), &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, headers)
+		if err == nil {
+			resp.Body.Close()
+		}
+	case CondHead:
+		_, err = svc.HeadObjectWithContext(aws.BackgroundContext(), &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		}, headers)
+	case CondPut:
+		_, err = svc.PutObjectWithContext(aws.BackgroundContext(), &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Body:   strings.NewReader(content),
+		}, headers)
+	case CondCopy:
+		_, err = CopyObjectWith(svc, bucket, key, bucket, key+CopySuffix, cond.CopyOptions()...)
+	case CondComplete:
+		err = completeWithConditions(svc, bucket, key, content, headers)
+	default:
+		return 0, fmt.Errorf("unknown conditional operation %q", op)
+	}
+
+	if err == nil {
+		return http.StatusOK, nil
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode(), nil
+	}
+
+	return 0, err
+}
+
+func completeWithConditions(svc *s3.S3, bucket string, key string, content string, headers request.Option) error {
+
+	upload, err := InitiateMultipartUpload(svc, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	part, err := Uploadpart(svc, bucket, key, *upload.UploadId, content, 1)
+	if err == nil {
+		_, err = svc.CompleteMultipartUploadWithContext(aws.BackgroundContext(), &s3.CompleteMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(key),
+			UploadId: upload.UploadId,
+			MultipartUpload: &s3.CompletedMultipartUpload{
+				Parts: []*s3.CompletedPart{{ETag: part.ETag, PartNumber: aws.Int64(1)}},
+			},
+		}, headers)
+	}
+	if err != nil {
+		AbortMultiPartUpload(svc, bucket, key, *upload.UploadId)
+	}
+
+	return err
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionCases(t *testing.T) {
+
+	assert := assert.New(t)
+	etag := `"abc"`
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := ConditionCases()
+	assert.Equal(81, len(cases))
+
+	seen := map[string]bool{}
+	for _, c := range cases {
+		seen[c.String()] = true
+
+		// Every header of the case evaluates as its state says.
+		cond := c.Conditions(etag, modified)
+		check := func(state ConditionState, single Conditions) {
+			status := single.Expect(CondGet, true, etag, modified)
+			switch state {
+			case ConditionTrue:
+				assert.Equal(http.StatusOK, status, c.String())
+			case ConditionFalse:
+				assert.NotEqual(http.StatusOK, status, c.String())
+			}
+		}
+		check(c.IfMatch, Conditions{IfMatch: cond.IfMatch})
+		check(c.IfNoneMatch, Conditions{IfNoneMatch: cond.IfNoneMatch})
+		check(c.IfModifiedSince, Conditions{IfModifiedSince: cond.IfModifiedSince})
+		check(c.IfUnmodifiedSince, Conditions{IfUnmodifiedSince: cond.IfUnmodifiedSince})
+	}
+	assert.Equal(81, len(seen))
+}
+
+func TestConditionsExpect(t *testing.T) {
+
+	assert := assert.New(t)
+	etag := `"abc"`
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := modified.Add(-time.Hour)
+
+	cases := []struct {
+		cond   Conditions
+		op     ConditionalOp
+		exists bool
+		want   int
+	}{
+		{Conditions{}, CondGet, true, http.StatusOK},
+		{Conditions{IfMatch: `"x", "abc"`}, CondGet, true, http.StatusOK},
+		{Conditions{IfMatch: `W/"abc"`}, CondGet, true, http.StatusPreconditionFailed},
+		{Conditions{IfMatch: "*"}, CondPut, false, http.StatusPreconditionFailed},
+		// If-Match wins over a failing If-Unmodified-Since.
+		{Conditions{IfMatch: etag, IfUnmodifiedSince: &before}, CondGet, true, http.StatusOK},
+		{Conditions{IfUnmodifiedSince: &before}, CondPut, true, http.StatusPreconditionFailed},
+		{Conditions{IfNoneMatch: etag}, CondHead, true, http.StatusNotModified},
+		{Conditions{IfNoneMatch: etag}, CondCopy, true, http.StatusPreconditionFailed},
+		{Conditions{IfNoneMatch: "*"}, CondPut, true, http.StatusPreconditionFailed},
+		{Conditions{IfNoneMatch: "*"}, CondComplete, false, http.StatusOK},
+		// If-None-Match wins over a failing If-Modified-Since.
+		{Conditions{IfNoneMatch: `"x"`, IfModifiedSince: &modified}, CondGet, true, http.StatusOK},
+		{Conditions{IfModifiedSince: &modified}, CondGet, true, http.StatusNotModified},
+		{Conditions{IfModifiedSince: &modified}, CondPut, true, http.StatusOK},
+		{Conditions{IfModifiedSince: &before}, CondGet, true, http.StatusOK},
+		// The copy source honours If-Modified-Since with 412 instead of 304.
+		{Conditions{IfModifiedSince: &modified}, CondCopy, true, http.StatusPreconditionFailed},
+		{Conditions{IfModifiedSince: &before}, CondCopy, true, http.StatusOK},
+		{Conditions{IfNoneMatch: `"x"`, IfModifiedSince: &modified}, CondCopy, true, http.StatusOK},
+		{Conditions{IfMatch: etag, IfModifiedSince: &modified}, CondCopy, true, http.StatusPreconditionFailed},
+		// A failed If-Match is reported before a failed If-None-Match.
+		{Conditions{IfMatch: `"x"`, IfNoneMatch: etag}, CondGet, true, http.StatusPreconditionFailed},
+	}
+
+	for i, c := range cases {
+		assert.Equal(c.want, c.cond.Expect(c.op, c.exists, etag, modified), "case %d", i)
+	}
+}
+
+func TestConditionsHeaders(t *testing.T) {
+
+	assert := assert.New(t)
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	headers := Conditions{IfMatch: `"a"`, IfModifiedSince: &at}.Headers()
+	assert.Equal(map[string]string{"If-Match": `"a"`, "If-Modified-Since": "Thu, 02 Jan 2020 03:04:05 GMT"}, headers)
+	assert.Equal(2, len(Conditions{IfNoneMatch: "*", IfUnmodifiedSince: &at}.CopyOptions()))
+}
@@ -0,0 +1,446 @@
+package helpers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ConfigEnv names the environment variable holding the path of the config
+// file. The -s3tests.config flag takes precedence over it.
+const ConfigEnv = "S3TESTS_CONFIG"
+
+// EnvPrefix prefixes the environment variables that override config keys:
+// S3TESTS_S3MAIN_ACCESS_KEY overrides s3main.access_key.
+const EnvPrefix = "S3TESTS"
+
+// ConfigNames are the file names looked for, in order, in ConfigDirs when
+// no path is given. s3tests.teuth.config.yaml is the file teuthology
+// renders for the suite.
+var ConfigNames = []string{"config.yaml", "s3tests.teuth.config.yaml"}
+
+// ConfigDirs are the directories searched for ConfigNames.
+var ConfigDirs = []string{".", ".."}
+
+// The flag is registered so that test binaries accept it; its value is read
+// from os.Args because the config is loaded before flags are parsed.
+var _ = flag.String("s3tests.config", "", "path to the suite config file (default: $"+ConfigEnv+", then "+strings.Join(ConfigNames, " or ")+" in . or ..)")
+
+var (
+	configOnce sync.Once
+	configErr  error
+)
+
+// loadedConfig loads the config the first time it is called and returns
+// what loading returned. The package variables built from the config call
+// it first, so the config is loaded before any of them whatever the order
+// they are initialized in.
+func loadedConfig() error {
+
+	configOnce.Do(func() { configErr = LoadConfig() })
+
+	return configErr
+}
+
+// ConfigErr returns the error of the config loaded at start-up, nil if it
+// was found and is valid.
+func ConfigErr() error {
+
+	return loadedConfig()
+}
+
+// ConfigError lists every problem found in a config.
+type ConfigError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+
+	path := e.Path
+	if path == "" {
+		path = "no config file"
+	}
+
+	return fmt.Sprintf("invalid config (%s):\n\t%s", path, strings.Join(e.Problems, "\n\t"))
+}
+
+// configFlagValue returns the value of -s3tests.config in args.
+func configFlagValue(args []string) string {
+
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if value := strings.TrimPrefix(name, "s3tests.config="); value != name {
+			return value
+		}
+		if name == "s3tests.config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	return ""
+}
+
+var errNoConfig = errors.New("no config file found")
+
+// FindConfig returns the path of the config file: the -s3tests.config
+// flag, then $S3TESTS_CONFIG, then the first of ConfigNames found in
+// ConfigDirs.
+func FindConfig() (string, error) {
+
+	for _, explicit := range []struct{ path, from string }{
+		{configFlagValue(os.Args[1:]), "-s3tests.config"},
+		{os.Getenv(ConfigEnv), "$" + ConfigEnv},
+	} {
+		if explicit.path == "" {
+			continue
+		}
+		if _, err := os.Stat(explicit.path); err != nil {
+			return "", fmt.Errorf("config file %s given by %s: %v", explicit.path, explicit.from, err)
+		}
+		return explicit.path, nil
+	}
+
+	for _, dir := range ConfigDirs {
+		for _, name := range ConfigNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: looked for %s in %s; copy config.yaml.sample to config.yaml, or point -s3tests.config or $%s at a config file",
+		errNoConfig, strings.Join(ConfigNames, " or "), strings.Join(ConfigDirs, " or "), ConfigEnv)
+}
+
+// LoadConfig finds and reads the config file, applies the S3TESTS_*
+// environment overrides and validates the result. When no file is found the
+// environment alone may provide every required key.
+func LoadConfig() error {
+
+	path, err := FindConfig()
+	if err != nil {
+		if !errors.Is(err, errNoConfig) {
+			return err
+		}
+		useEnv()
+		if problems := validateConfig(); len(problems) > 0 {
+			return &ConfigError{Problems: append([]string{err.Error()}, problems...)}
+		}
+		return nil
+	}
+
+	return LoadConfigFile(path)
+}
+
+// LoadConfigFile reads the config from path, applies the S3TESTS_*
+// environment overrides and validates the result.
+func LoadConfigFile(path string) error {
+
+	viper.SetConfigType("yaml")
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("reading config %s: %v", path, err)
+	}
+	useEnv()
+
+	if problems := validateConfig(); len(problems) > 0 {
+		return &ConfigError{Path: path, Problems: problems}
+	}
+
+	return nil
+}
+
+// useEnv turns on the environment overrides and fills in what a
+// teuthology-style config leaves out: the endpoint and is_secure of each
+// user come from the DEFAULT section when not given.
+func useEnv() {
+
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	var endpoint interface{}
+	if host := viper.GetString("default.host"); host != "" {
+		endpoint = host
+		if port := viper.GetString("default.port"); port != "" {
+			endpoint = net.JoinHostPort(host, port)
+		}
+	}
+	for _, user := range []string{"s3main", "s3alt"} {
+		viper.SetDefault(user+".endpoint", endpoint)
+		viper.SetDefault(user+".is_secure", viper.Get("default.is_secure"))
+	}
+}
+
+// configKey describes one config key: whether it must be set and how to
+// check its value.
+type configKey struct {
+	key      string
+	required bool
+	check    func(value string) error
+}
+
+var bucketPrefixRE = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,39}$`)
+
+func checkBool(value string) error {
+
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("%q is not a boolean, use true or false", value)
+	}
+
+	return nil
+}
+
+func checkInt(value string) error {
+
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("%q is not an integer", value)
+	}
+
+	return nil
+}
+
+func checkDuration(value string) error {
+
+	if _, err := time.ParseDuration(value); err != nil {
+		return fmt.Errorf("%q is not a duration such as 500ms or 30s", value)
+	}
+
+	return nil
+}
+
+func checkSize(value string) error {
+
+	if _, err := ParseSize(value); err != nil {
+		return fmt.Errorf("%q is not a size such as 1024, 64KiB or 5MiB+1", value)
+	}
+
+	return nil
+}
+
+func checkMix(value string) error {
+
+	_, err := ParseMix(value)
+
+	return err
+}
+
+func checkBucketPrefix(value string) error {
+
+	if !bucketPrefixRE.MatchString(value) {
+		return fmt.Errorf("%q must be 1-40 lowercase letters, digits or hyphens; it starts every bucket name the suite creates and deletes", value)
+	}
+
+	return nil
+}
+
+// checkEndpoint accepts host, host:port or an http(s) URL without a path.
+func checkEndpoint(value string) error {
+
+	raw := value
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		return fmt.Errorf("%q is not a valid endpoint: %v", value, err)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("%q: scheme must be http or https", value)
+	case u.Hostname() == "":
+		return fmt.Errorf("%q has no host, use host:port", value)
+	case strings.Trim(u.Path, "/") != "" || u.RawQuery != "":
+		return fmt.Errorf("%q must not have a path or query", value)
+	}
+	if port := u.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%q: port must be 1-65535", value)
+		}
+	}
+
+	return nil
+}
+
+func oneOf(values ...string) func(string) error {
+
+	return func(value string) error {
+		if !Contains(values, value) {
+			return fmt.Errorf("%q is not one of %s", value, strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+// configSchema lists every key the suite reads. Lists are not checked.
+var configSchema = []configKey{
+	{key: "default.host"},
+	{key: "default.port", check: checkInt},
+	{key: "default.is_secure", check: checkBool},
+
+	{key: "fixtures.bucket_prefix", required: true, check: checkBucketPrefix},
+
+	{key: "s3main.access_key", required: true},
+	{key: "s3main.access_secret", required: true},
+	{key: "s3main.session_token"},
+	{key: "s3main.endpoint", required: true, check: checkEndpoint},
+	{key: "s3main.region", required: true},
+	{key: "s3main.other_region"},
+	{key: "s3main.default_bucket_region"},
+	{key: "s3main.is_secure", check: checkBool},
+	{key: "s3main.bucket"},
+	{key: "s3main.host"},
+	{key: "s3main.port", check: checkInt},
+	{key: "s3main.display_name"},
+	{key: "s3main.email"},
+	{key: "s3main.sse"},
+	{key: "s3main.kmskeyid"},
+	{key: "s3main.log_level", check: checkInt},
+	{key: "s3main.addressing_style", check: oneOf("path", "virtual", "both")},
+	{key: "s3main.vhost_domain"},
+
+	{key: "s3alt.access_key"},
+	{key: "s3alt.access_secret"},
+	{key: "s3alt.session_token"},
+	{key: "s3alt.endpoint", check: checkEndpoint},
+	{key: "s3alt.region"},
+	{key: "s3alt.is_secure", check: checkBool},
+	{key: "s3alt.bucket"},
+	{key: "s3alt.display_name"},
+	{key: "s3alt.email"},
+	{key: "s3alt.sse"},
+	{key: "s3alt.kmskeyid"},
+
+	{key: "tls.ca_file"},
+	{key: "tls.cert_file"},
+	{key: "tls.key_file"},
+	{key: "tls.server_name"},
+	{key: "tls.insecure_skip_verify", check: checkBool},
+
+	{key: "sts.endpoint", check: checkEndpoint},
+	{key: "sts.role_arn"},
+	{key: "sts.session_name"},
+	{key: "sts.duration", check: checkDuration},
+	{key: "sts.access_key"},
+	{key: "sts.access_secret"},
+	{key: "sts.session_token"},
+	{key: "sts.expired_access_key"},
+	{key: "sts.expired_access_secret"},
+	{key: "sts.expired_session_token"},
+
+	{key: "large_objects.tier", check: oneOf("small", "medium", "large")},
+	{key: "large_objects.sizes"},
+	{key: "large_objects.part_sizes"},
+	{key: "large_objects.concurrency"},
+	{key: "large_objects.seed", check: checkInt},
+
+	{key: "bench.bucket"},
+	{key: "bench.workers", check: checkInt},
+	{key: "bench.duration", check: checkDuration},
+	{key: "bench.object_size", check: checkSize},
+	{key: "bench.multipart_size", check: checkSize},
+	{key: "bench.keys", check: checkInt},
+	{key: "bench.prefill", check: checkBool},
+	{key: "bench.seed", check: checkInt},
+	{key: "bench.mix", check: checkMix},
+
+	{key: "consistency.model", check: oneOf(StrongConsistency, EventualConsistency)},
+	{key: "consistency.window", check: checkDuration},
+	{key: "consistency.poll", check: checkDuration},
+	{key: "consistency.writers", check: checkInt},
+	{key: "consistency.keys", check: checkInt},
+
+	{key: "select.profile"},
+	{key: "select.resource"},
+	{key: "select.method"},
+	{key: "select.tag"},
+	{key: "select.exclude_tag"},
+	{key: "select.report"},
+
+	{key: "recorder.mode", check: oneOf(string(RecordOff), string(RecordFailures), string(RecordAlways), string(Replay))},
+	{key: "recorder.dir"},
+	{key: "recorder.max_body", check: checkSize},
+}
+
+// envName returns the environment variable overriding key.
+func envName(key string) string {
+
+	return EnvPrefix + "_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// validateConfig checks the loaded config against configSchema and returns
+// one message per problem, naming the key and how to fix it.
+func validateConfig() []string {
+
+	var problems []string
+	known := map[string]bool{}
+
+	for _, k := range configSchema {
+		known[k.key] = true
+
+		value := ""
+		if v := viper.Get(k.key); v != nil {
+			value = strings.TrimSpace(fmt.Sprint(v))
+		}
+		if value == "" {
+			if k.required {
+				problems = append(problems, fmt.Sprintf("%s is required: set it in the config file or $%s", k.key, envName(k.key)))
+			}
+			continue
+		}
+		if k.check != nil {
+			if err := k.check(value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", k.key, err))
+			}
+		}
+	}
+
+	if (viper.GetString("s3alt.access_key") == "") != (viper.GetString("s3alt.access_secret") == "") {
+		problems = append(problems, "s3alt.access_key and s3alt.access_secret must be set together, or both left empty to skip the cross-user tests")
+	}
+
+	for _, prefix := range []string{"sts.", "sts.expired_"} {
+		keys := []string{prefix + "access_key", prefix + "access_secret", prefix + "session_token"}
+		set := 0
+		for _, key := range keys {
+			if viper.GetString(key) != "" {
+				set++
+			}
+		}
+		if set != 0 && set != len(keys) {
+			problems = append(problems, fmt.Sprintf("%s must be set together", strings.Join(keys, ", ")))
+		}
+	}
+
+	if style := viper.GetString("s3main.addressing_style"); (style == string(VirtualHostedStyle) || style == "both") && !HasVirtualHosting() {
+		problems = append(problems, fmt.Sprintf("s3main.addressing_style %s needs a domain for bucket hostnames, but %q is an IP address: set s3main.vhost_domain", style, VirtualHostDomain()))
+	}
+
+	if _, err := TLSConfig(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	for _, key := range viper.AllKeys() {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("%s is not a known key; check its spelling against config.yaml.sample", key))
+		}
+	}
+
+	return problems
+}
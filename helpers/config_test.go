@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// withConfigFile loads content as the only config for the rest of t, then
+// goes back to the config loaded before. Values set by other tests are
+// dropped with the rest of the old config.
+func withConfigFile(t *testing.T, content string) (string, error) {
+
+	old := viper.ConfigFileUsed()
+	viper.Reset()
+	t.Cleanup(func() {
+		viper.Reset()
+		if err := LoadConfigFile(old); err != nil {
+			t.Errorf("restoring %s: %v", old, err)
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, LoadConfigFile(path)
+}
+
+func TestConfigFlagValue(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("", configFlagValue([]string{"-test.v", "-test.run=TestSuite"}))
+	assert.Equal("a.yaml", configFlagValue([]string{"-test.v", "-s3tests.config=a.yaml"}))
+	assert.Equal("b.yaml", configFlagValue([]string{"--s3tests.config", "b.yaml", "-test.v"}))
+	assert.Equal("", configFlagValue([]string{"s3tests.config=c.yaml"}))
+}
+
+func TestFindConfigFromEnv(t *testing.T) {
+
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "mine.yaml")
+	assert.Nil(ioutil.WriteFile(path, nil, 0644))
+
+	t.Setenv(ConfigEnv, path)
+	found, err := FindConfig()
+	assert.Nil(err)
+	assert.Equal(path, found)
+
+	t.Setenv(ConfigEnv, path+".missing")
+	_, err = FindConfig()
+	assert.Contains(err.Error(), "$"+ConfigEnv)
+}
+
+func TestLoadConfigTeuthology(t *testing.T) {
+
+	assert := assert.New(t)
+
+	_, err := withConfigFile(t, `
+DEFAULT :
+    host : s3.test
+    port : 8443
+    is_secure : true
+
+fixtures :
+    bucket_prefix : s3test-go-
+
+s3main :
+    access_key : AKID
+    access_secret : SECRET
+    region : us-east-1
+    is_secure :
+
+s3alt :
+    access_key :
+    access_secret :
+`)
+	assert.Nil(err)
+	assert.Equal("s3.test:8443", viper.GetString("s3main.endpoint"))
+	assert.True(viper.GetBool("s3main.is_secure"))
+	assert.False(HasAltUser())
+}
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+
+	assert := assert.New(t)
+
+	t.Setenv("S3TESTS_S3MAIN_ACCESS_KEY", "FROMENV")
+	t.Setenv("S3TESTS_S3MAIN_ENDPOINT", "https://gateway.test")
+	_, err := withConfigFile(t, `
+fixtures :
+    bucket_prefix : test
+s3main :
+    access_secret : SECRET
+    region : us-east-1
+`)
+	assert.Nil(err)
+	assert.Equal("FROMENV", viper.GetString("s3main.access_key"))
+	assert.Equal("https://gateway.test", viper.GetString("s3main.endpoint"))
+
+	t.Setenv("S3TESTS_S3MAIN_IS_SECURE", "maybe")
+	assert.Equal([]string{`s3main.is_secure: "maybe" is not a boolean, use true or false`}, validateConfig())
+}
+
+func TestLoadConfigProblems(t *testing.T) {
+
+	assert := assert.New(t)
+
+	path, err := withConfigFile(t, `
+fixtures :
+    bucket_prefix : Bad_Prefix
+s3main :
+    acess_key : AKID
+    access_secret : SECRET
+    region : us-east-1
+    endpoint : localhost:99999
+    is_secure : yes please
+    addressing_style : subdomain
+s3alt :
+    access_key : ALT
+bench :
+    duration : forever
+`)
+	configErr, ok := err.(*ConfigError)
+	if !assert.True(ok, "%v", err) {
+		return
+	}
+	assert.Equal(path, configErr.Path)
+
+	for _, want := range []string{
+		"fixtures.bucket_prefix: \"Bad_Prefix\"",
+		"s3main.access_key is required: set it in the config file or $S3TESTS_S3MAIN_ACCESS_KEY",
+		"s3main.endpoint: \"localhost:99999\": port must be 1-65535",
+		"s3main.is_secure: \"yes please\" is not a boolean",
+		"s3main.addressing_style: \"subdomain\" is not one of path, virtual, both",
+		"s3alt.access_key and s3alt.access_secret must be set together",
+		"s3main.acess_key is not a known key",
+		"bench.duration: \"forever\" is not a duration",
+	} {
+		found := false
+		for _, problem := range configErr.Problems {
+			found = found || strings.HasPrefix(problem, want)
+		}
+		assert.True(found, "missing %q in\n%v", want, err)
+	}
+	assert.Equal(8, len(configErr.Problems), "%v", err)
+}
+
+func TestCheckEndpoint(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for _, ok := range []string{"127.0.0.1:5200", "localhost", "http://s3.test", "https://s3.test:443/", "[::1]:8000"} {
+		assert.Nil(checkEndpoint(ok), ok)
+	}
+	for _, bad := range []string{"ftp://s3.test", "http://", "s3.test:0", "s3.test:port", "s3.test/bucket", "https://s3.test/?x=1"} {
+		assert.NotNil(checkEndpoint(bad), bad)
+	}
+}
@@ -0,0 +1,344 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/spf13/viper"
+)
+
+// Consistency models a gateway can be held to.
+const (
+	StrongConsistency   = "strong"
+	EventualConsistency = "eventual"
+)
+
+// Checks the consistency run knows how to make.
+const (
+	ReadAfterWrite     = "read-after-write"
+	ReadAfterOverwrite = "read-after-overwrite"
+	ReadAfterDelete    = "read-after-delete"
+	ListAfterWrite     = "list-after-write"
+	ListAfterDelete    = "list-after-delete"
+)
+
+// ConsistencyChecks lists every check in the order a key goes through them.
+var ConsistencyChecks = []string{ReadAfterWrite, ListAfterWrite, ReadAfterOverwrite, ReadAfterDelete, ListAfterDelete}
+
+// ConsistencyConfig describes one consistency run.
+type ConsistencyConfig struct {
+	Model   string
+	Window  time.Duration
+	Poll    time.Duration
+	Writers int
+	Keys    int
+}
+
+// GetConsistencyConfig reads the consistency section of the config, filling
+// in defaults. Window is how long a check keeps polling a stale result; under
+// the eventual model it is also the staleness allowed.
+func GetConsistencyConfig() (ConsistencyConfig, error) {
+
+	conf := ConsistencyConfig{
+		Model:   strings.ToLower(fmt.Sprint(viperDefault("consistency.model", StrongConsistency))),
+		Writers: viper.GetInt("consistency.writers"),
+		Keys:    viper.GetInt("consistency.keys"),
+	}
+
+	if conf.Model != StrongConsistency && conf.Model != EventualConsistency {
+		return conf, fmt.Errorf("consistency.model: unknown model %q", conf.Model)
+	}
+	if conf.Writers <= 0 {
+		conf.Writers = 4
+	}
+	if conf.Keys <= 0 {
+		conf.Keys = 20
+	}
+
+	var err error
+	if conf.Window, err = time.ParseDuration(fmt.Sprint(viperDefault("consistency.window", "5s"))); err != nil {
+		return conf, fmt.Errorf("consistency.window: %v", err)
+	}
+	if conf.Poll, err = time.ParseDuration(fmt.Sprint(viperDefault("consistency.poll", "50ms"))); err != nil {
+		return conf, fmt.Errorf("consistency.poll: %v", err)
+	}
+	if conf.Poll <= 0 || conf.Window < conf.Poll {
+		return conf, fmt.Errorf("consistency.poll: %v must be positive and no longer than window %v", conf.Poll, conf.Window)
+	}
+
+	return conf, nil
+}
+
+// WaitConsistent calls consistent until it reports true, sleeping poll
+// between calls, until window has passed since written, the moment the
+// write it checks returned. It returns how long after written the result
+// was still stale, zero when the first call was already consistent, and
+// whether it converged. An error ends the wait.
+func WaitConsistent(written time.Time, poll time.Duration, window time.Duration, consistent func() (bool, error)) (time.Duration, bool, error) {
+
+	for attempt := 0; ; attempt++ {
+		ok, err := consistent()
+		if err != nil {
+			return time.Since(written), false, err
+		}
+		if ok {
+			if attempt == 0 {
+				return 0, true, nil
+			}
+			return time.Since(written), true, nil
+		}
+		if time.Since(written) >= window {
+			return time.Since(written), false, nil
+		}
+		time.Sleep(poll)
+	}
+}
+
+// CheckStats accumulates the observations of one check. Staleness holds one
+// entry per observation, zero when the first read was already consistent.
+type CheckStats struct {
+	Check       string
+	Count       int64
+	Stale       int64
+	Unconverged int64
+	Staleness   []time.Duration
+	ErrorCodes  map[string]int64
+}
+
+// Percentile returns the staleness below which p percent of observations
+// fell, using the nearest-rank method.
+func (s *CheckStats) Percentile(p float64) time.Duration {
+
+	return percentile(s.Staleness, p)
+}
+
+// StalenessReport collects the observations of a run; it is safe for
+// concurrent use.
+type StalenessReport struct {
+	mu     sync.Mutex
+	Checks map[string]*CheckStats
+}
+
+// NewStalenessReport returns an empty report.
+func NewStalenessReport() *StalenessReport {
+
+	return &StalenessReport{Checks: map[string]*CheckStats{}}
+}
+
+// Record adds one observation of check.
+func (r *StalenessReport) Record(check string, stale time.Duration, converged bool, err error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.Checks[check]
+	if !ok {
+		s = &CheckStats{Check: check, ErrorCodes: map[string]int64{}}
+		r.Checks[check] = s
+	}
+
+	s.Count++
+	if err != nil {
+		s.ErrorCodes[ErrorCode(err)]++
+		return
+	}
+	s.Staleness = append(s.Staleness, stale)
+	if stale > 0 {
+		s.Stale++
+	}
+	if !converged {
+		s.Unconverged++
+	}
+}
+
+// Violations describes every way the observations break conf.Model: under
+// strong consistency any stale observation, under eventual consistency one
+// that stayed stale past the window. Failed requests are always violations.
+func (r *StalenessReport) Violations(conf ConsistencyConfig) []string {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var violations []string
+	for _, check := range ConsistencyChecks {
+		s, ok := r.Checks[check]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case s.Unconverged > 0:
+			violations = append(violations, fmt.Sprintf("%s: %d of %d observations still stale after %v",
+				check, s.Unconverged, s.Count, conf.Window))
+		case conf.Model == StrongConsistency && s.Stale > 0:
+			violations = append(violations, fmt.Sprintf("%s: %d of %d observations stale, up to %v",
+				check, s.Stale, s.Count, s.Percentile(100)))
+		}
+
+		for code, n := range s.ErrorCodes {
+			violations = append(violations, fmt.Sprintf("%s: %d requests failed with %s", check, n, code))
+		}
+	}
+	sort.Strings(violations)
+
+	return violations
+}
+
+// Report writes a per-check table of observations, stale and unconverged
+// counts, staleness percentiles and errors grouped by S3 error code.
+func (r *StalenessReport) Report(w io.Writer) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "check\tcount\tstale\tunconverged\tp50\tp99\tmax\terrors")
+
+	for _, check := range ConsistencyChecks {
+		s, ok := r.Checks[check]
+		if !ok {
+			continue
+		}
+
+		codes := []string{}
+		for code, n := range s.ErrorCodes {
+			codes = append(codes, fmt.Sprintf("%s=%d", code, n))
+		}
+		sort.Strings(codes)
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%v\t%v\t%v\t%s\n", check, s.Count, s.Stale, s.Unconverged,
+			s.Percentile(50), s.Percentile(99), s.Percentile(100), strings.Join(codes, " "))
+	}
+
+	tw.Flush()
+}
+
+func consistencyKey(writer int, i int) string {
+
+	return fmt.Sprintf("writer%02d/key%04d", writer, i)
+}
+
+// readsAs reports whether GET of bucket/key returns content, or NoSuchKey
+// when deleted is set.
+func readsAs(svc *s3.S3, bucket string, key string, content string, deleted bool) func() (bool, error) {
+
+	return func() (bool, error) {
+		data, err := GetObject(svc, bucket, key)
+		if err != nil {
+			if ErrorCode(err) == "NoSuchKey" {
+				return deleted, nil
+			}
+			return false, err
+		}
+		return !deleted && data == content, nil
+	}
+}
+
+// listsAs reports whether listing bucket shows key, or does not when
+// deleted is set.
+func listsAs(svc *s3.S3, bucket string, key string, deleted bool) func() (bool, error) {
+
+	return func() (bool, error) {
+		_, keys, _, err := ListObjectsWithPrefix(svc, bucket, key)
+		if err != nil {
+			return false, err
+		}
+		return Contains(keys, key) != deleted, nil
+	}
+}
+
+// RunConsistency runs conf.Writers concurrent writers against bucket, each
+// taking conf.Keys keys of its own through write, overwrite and delete, and
+// after every step polls the requested checks (all of them when none are
+// given) concurrently until the result is consistent or conf.Window runs
+// out. Staleness is measured from the moment the write returned. A failed
+// write stops the run.
+func RunConsistency(svc *s3.S3, bucket string, conf ConsistencyConfig, checks ...string) (*StalenessReport, error) {
+
+	if len(checks) == 0 {
+		checks = ConsistencyChecks
+	}
+	enabled := map[string]bool{}
+	for _, check := range checks {
+		enabled[check] = true
+	}
+
+	report := NewStalenessReport()
+	// observe polls the enabled checks of a step concurrently, so that one
+	// converging late does not hide the staleness of another.
+	observe := func(written time.Time, consistent map[string]func() (bool, error)) {
+		var wg sync.WaitGroup
+		for check, fn := range consistent {
+			if !enabled[check] {
+				continue
+			}
+			wg.Add(1)
+			go func(check string, fn func() (bool, error)) {
+				defer wg.Done()
+				stale, converged, err := WaitConsistent(written, conf.Poll, conf.Window, fn)
+				report.Record(check, stale, converged, err)
+			}(check, fn)
+		}
+		wg.Wait()
+	}
+
+	errs := make(chan error, conf.Writers)
+	var wg sync.WaitGroup
+
+	for w := 0; w < conf.Writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < conf.Keys; i++ {
+				key := consistencyKey(w, i)
+
+				first := fmt.Sprintf("%s v1", key)
+				if err := PutObjectToBucket(svc, bucket, key, first); err != nil {
+					errs <- fmt.Errorf("put %s: %v", key, err)
+					return
+				}
+				observe(time.Now(), map[string]func() (bool, error){
+					ReadAfterWrite: readsAs(svc, bucket, key, first, false),
+					ListAfterWrite: listsAs(svc, bucket, key, false),
+				})
+
+				if enabled[ReadAfterOverwrite] {
+					second := fmt.Sprintf("%s v2", key)
+					if err := PutObjectToBucket(svc, bucket, key, second); err != nil {
+						errs <- fmt.Errorf("overwrite %s: %v", key, err)
+						return
+					}
+					observe(time.Now(), map[string]func() (bool, error){
+						ReadAfterOverwrite: readsAs(svc, bucket, key, second, false),
+					})
+				}
+
+				if enabled[ReadAfterDelete] || enabled[ListAfterDelete] {
+					if err := DeleteObject(svc, bucket, key); err != nil {
+						errs <- fmt.Errorf("delete %s: %v", key, err)
+						return
+					}
+					observe(time.Now(), map[string]func() (bool, error){
+						ReadAfterDelete: readsAs(svc, bucket, key, "", true),
+						ListAfterDelete: listsAs(svc, bucket, key, true),
+					})
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return report, err
+	default:
+	}
+
+	return report, nil
+}
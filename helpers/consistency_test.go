@@ -0,0 +1,225 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+// laggingStore is an in-memory bucket whose writes become visible to reads
+// and listings only lag after they were made, like a stale metadata cache.
+type laggingStore struct {
+	mu       sync.Mutex
+	lag      time.Duration
+	versions map[string][]laggingVersion
+}
+
+type laggingVersion struct {
+	visible time.Time
+	content string
+	deleted bool
+}
+
+func (s *laggingStore) current(key string, now time.Time) (laggingVersion, bool) {
+
+	var found laggingVersion
+	ok := false
+	for _, v := range s.versions[key] {
+		if !v.visible.After(now) {
+			found, ok = v, true
+		}
+	}
+
+	return found, ok && !found.deleted
+}
+
+func (s *laggingStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case r.Method == "PUT":
+		body, _ := ioutil.ReadAll(r.Body)
+		s.versions[key] = append(s.versions[key], laggingVersion{visible: now.Add(s.lag), content: string(body)})
+	case r.Method == "DELETE":
+		s.versions[key] = append(s.versions[key], laggingVersion{visible: now.Add(s.lag), deleted: true})
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/bucket":
+		prefix := r.URL.Query().Get("prefix")
+		fmt.Fprint(w, "<ListBucketResult><IsTruncated>false</IsTruncated>")
+		for k := range s.versions {
+			if _, ok := s.current(k, now); ok && strings.HasPrefix(k, prefix) {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", k)
+			}
+		}
+		fmt.Fprint(w, "</ListBucketResult>")
+	default:
+		v, ok := s.current(key, now)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>gone</Message></Error>")
+			return
+		}
+		fmt.Fprint(w, v.content)
+	}
+}
+
+func laggingServer(lag time.Duration) *httptest.Server {
+
+	return httptest.NewServer(&laggingStore{lag: lag, versions: map[string][]laggingVersion{}})
+}
+
+func TestGetConsistencyConfig(t *testing.T) {
+
+	assert := assert.New(t)
+
+	withConfig(t, map[string]string{"consistency.model": "Eventual", "consistency.window": "2s", "consistency.writers": "3"})
+	conf, err := GetConsistencyConfig()
+	assert.Nil(err)
+	assert.Equal(ConsistencyConfig{Model: EventualConsistency, Window: 2 * time.Second, Poll: 50 * time.Millisecond, Writers: 3, Keys: 20}, conf)
+
+	for key, value := range map[string]string{
+		"consistency.model":  "causal",
+		"consistency.window": "soon",
+		"consistency.poll":   "10s",
+	} {
+		t.Run(key, func(t *testing.T) {
+			withConfig(t, map[string]string{key: value})
+			_, err := GetConsistencyConfig()
+			assert.Contains(fmt.Sprint(err), key)
+		})
+	}
+}
+
+func TestWaitConsistent(t *testing.T) {
+
+	assert := assert.New(t)
+
+	stale, converged, err := WaitConsistent(time.Now(), time.Millisecond, time.Second, func() (bool, error) { return true, nil })
+	assert.Equal(time.Duration(0), stale)
+	assert.True(converged)
+	assert.Nil(err)
+
+	calls := 0
+	stale, converged, err = WaitConsistent(time.Now(), time.Millisecond, time.Second, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	assert.True(stale > 0)
+	assert.True(converged)
+	assert.Nil(err)
+	assert.Equal(3, calls)
+
+	stale, converged, err = WaitConsistent(time.Now(), time.Millisecond, 20*time.Millisecond, func() (bool, error) { return false, nil })
+	assert.True(stale >= 20*time.Millisecond)
+	assert.False(converged)
+	assert.Nil(err)
+
+	_, converged, err = WaitConsistent(time.Now(), time.Millisecond, time.Second, func() (bool, error) { return false, errors.New("boom") })
+	assert.False(converged)
+	assert.NotNil(err)
+
+	// Staleness and the window count from the write, not the first poll.
+	written := time.Now().Add(-30 * time.Millisecond)
+	calls = 0
+	stale, converged, err = WaitConsistent(written, time.Millisecond, time.Second, func() (bool, error) {
+		calls++
+		return calls == 2, nil
+	})
+	assert.True(stale >= 30*time.Millisecond)
+	assert.True(converged)
+	assert.Nil(err)
+
+	stale, converged, err = WaitConsistent(written, time.Millisecond, 20*time.Millisecond, func() (bool, error) { return false, nil })
+	assert.True(stale >= 30*time.Millisecond)
+	assert.False(converged)
+	assert.Nil(err)
+}
+
+func TestStalenessReportViolations(t *testing.T) {
+
+	assert := assert.New(t)
+	strong := ConsistencyConfig{Model: StrongConsistency, Window: time.Second}
+	eventual := ConsistencyConfig{Model: EventualConsistency, Window: time.Second}
+
+	r := NewStalenessReport()
+	r.Record(ReadAfterWrite, 0, true, nil)
+	assert.Empty(r.Violations(strong))
+
+	r.Record(ReadAfterWrite, 300*time.Millisecond, true, nil)
+	assert.Equal([]string{"read-after-write: 1 of 2 observations stale, up to 300ms"}, r.Violations(strong))
+	assert.Empty(r.Violations(eventual))
+
+	r.Record(ListAfterDelete, time.Second, false, nil)
+	r.Record(ReadAfterDelete, 0, false, awserr.New("InternalError", "", nil))
+	assert.Equal([]string{
+		"list-after-delete: 1 of 1 observations still stale after 1s",
+		"read-after-delete: 1 requests failed with InternalError",
+	}, r.Violations(eventual))
+
+	var buf bytes.Buffer
+	r.Report(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(4, len(lines))
+	assert.True(strings.HasPrefix(lines[0], "check"))
+	assert.True(strings.HasPrefix(lines[1], "read-after-write"))
+	assert.Contains(lines[2], "InternalError=1")
+}
+
+func TestRunConsistency(t *testing.T) {
+
+	assert := assert.New(t)
+	conf := ConsistencyConfig{Model: StrongConsistency, Window: time.Second, Poll: 5 * time.Millisecond, Writers: 3, Keys: 2}
+
+	server := laggingServer(0)
+	defer server.Close()
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	report, err := RunConsistency(client, "bucket", conf)
+	assert.Nil(err)
+	assert.Empty(report.Violations(conf))
+	for _, check := range ConsistencyChecks {
+		if assert.Contains(report.Checks, check) {
+			assert.Equal(int64(conf.Writers*conf.Keys), report.Checks[check].Count, check)
+		}
+	}
+
+	lagging := laggingServer(50 * time.Millisecond)
+	defer lagging.Close()
+	client = recorderClient(strings.TrimPrefix(lagging.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	report, err = RunConsistency(client, "bucket", conf, ReadAfterWrite, ListAfterDelete)
+	assert.Nil(err)
+	assert.Equal(2, len(report.Checks))
+	assert.Equal(2, len(report.Violations(conf)))
+	assert.True(report.Checks[ReadAfterWrite].Percentile(50) >= 50*time.Millisecond)
+
+	// The listing lags as long as the read; polling both from the write
+	// flags it too instead of only checking it once the read converged.
+	report, err = RunConsistency(client, "bucket", conf, ReadAfterWrite, ListAfterWrite)
+	assert.Nil(err)
+	assert.Equal(2, len(report.Violations(conf)))
+	assert.True(report.Checks[ListAfterWrite].Percentile(50) >= 50*time.Millisecond)
+
+	conf.Model = EventualConsistency
+	assert.Empty(report.Violations(conf))
+
+	conf.Window = 10 * time.Millisecond
+	report, err = RunConsistency(client, "bucket", conf, ReadAfterOverwrite)
+	assert.Nil(err)
+	assert.Equal(int64(conf.Writers*conf.Keys), report.Checks[ReadAfterOverwrite].Unconverged)
+	assert.Equal(1, len(report.Violations(conf)))
+}
@@ -0,0 +1,209 @@
+package helpers
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// CopyOption adjusts a CopyObjectInput before it is sent.
+type CopyOption func(*s3.CopyObjectInput)
+
+// CopyMetadata sets the metadata directive ("COPY" or "REPLACE") and, for
+// REPLACE, the new user metadata.
+func CopyMetadata(directive string, metadata map[string]string) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.MetadataDirective = aws.String(directive)
+		if metadata != nil {
+			in.Metadata = aws.StringMap(metadata)
+		}
+	}
+}
+
+// CopyContentType sets the Content-Type sent with the copy.
+func CopyContentType(contentType string) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.ContentType = aws.String(contentType)
+	}
+}
+
+// CopyTagging sets the tagging directive ("COPY" or "REPLACE") and, for
+// REPLACE, the new tags.
+func CopyTagging(directive string, tags map[string]string) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.TaggingDirective = aws.String(directive)
+		if tags != nil {
+			in.Tagging = aws.String(EncodeTagging(tags))
+		}
+	}
+}
+
+// CopyIfMatch sets x-amz-copy-source-if-match.
+func CopyIfMatch(etag string) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.CopySourceIfMatch = aws.String(etag)
+	}
+}
+
+// CopyIfNoneMatch sets x-amz-copy-source-if-none-match.
+func CopyIfNoneMatch(etag string) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.CopySourceIfNoneMatch = aws.String(etag)
+	}
+}
+
+// CopyIfModifiedSince sets x-amz-copy-source-if-modified-since.
+func CopyIfModifiedSince(t time.Time) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.CopySourceIfModifiedSince = aws.Time(t)
+	}
+}
+
+// CopyIfUnmodifiedSince sets x-amz-copy-source-if-unmodified-since.
+func CopyIfUnmodifiedSince(t time.Time) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.CopySourceIfUnmodifiedSince = aws.Time(t)
+	}
+}
+
+// CopyObjectWith copies srcBucket/srcKey to bucket/key, URL-encoding the
+// copy source, and applies opts to the request.
+func CopyObjectWith(svc *s3.S3, srcBucket string, srcKey string, bucket string, key string, opts ...CopyOption) (*s3.CopyObjectOutput, error) {
+
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(EscapeCopySource(srcBucket, srcKey)),
+	}
+
+	for _, opt := range opts {
+		opt(input)
+	}
+
+	return svc.CopyObject(input)
+}
+
+// EncodeTagging renders tags as the URL query string x-amz-tagging expects,
+// sorted by key.
+func EncodeTagging(tags map[string]string) string {
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(tags[k]))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// PutObjectWithMetadata writes content with user metadata and a content type.
+func PutObjectWithMetadata(svc *s3.S3, bucket string, key string, content string, contentType string, metadata map[string]string) (*s3.PutObjectOutput, error) {
+
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     strings.NewReader(content),
+		Metadata: aws.StringMap(metadata),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	return svc.PutObject(input)
+}
+
+// PutObjectWithTagging writes content with the given object tags.
+func PutObjectWithTagging(svc *s3.S3, bucket string, key string, content string, tags map[string]string) error {
+
+	_, err := svc.PutObject(&s3.PutObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Body:    strings.NewReader(content),
+		Tagging: aws.String(EncodeTagging(tags)),
+	})
+
+	return err
+}
+
+// GetObjectTags returns the tags of bucket/key as a map.
+func GetObjectTags(svc *s3.S3, bucket string, key string) (map[string]string, error) {
+
+	resp, err := svc.GetObjectTagging(&s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, t := range resp.TagSet {
+		tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+	}
+
+	return tags, nil
+}
+
+// HeadObject returns the HEAD response for bucket/key.
+func HeadObject(svc *s3.S3, bucket string, key string) (*s3.HeadObjectOutput, error) {
+
+	return svc.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+}
+
+// SetObjectACL applies a canned ACL to bucket/key.
+func SetObjectACL(svc *s3.S3, bucket string, key string, acl string) error {
+
+	_, err := svc.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		ACL:    aws.String(acl),
+	})
+
+	return err
+}
+
+// MultipartUploadParts uploads each payload as a consecutive part of a new
+// multipart upload of bucket/key and completes it.
+func MultipartUploadParts(svc *s3.S3, bucket string, key string, payloads []string) (*s3.CompleteMultipartUploadOutput, error) {
+
+	result, err := InitiateMultipartUpload(svc, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*s3.CompletedPart, 0, len(payloads))
+	for i, payload := range payloads {
+		part, err := Uploadpart(svc, bucket, key, *result.UploadId, payload, int64(i+1))
+		if err != nil {
+			AbortMultiPartUpload(svc, bucket, key, *result.UploadId)
+			return nil, err
+		}
+		parts = append(parts, &s3.CompletedPart{ETag: part.ETag, PartNumber: aws.Int64(int64(i + 1))})
+	}
+
+	return svc.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        result.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+}
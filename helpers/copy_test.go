@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeTagging(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("", EncodeTagging(nil))
+	assert.Equal("a=1&b=x+y&c%26d=%3D", EncodeTagging(map[string]string{"b": "x y", "a": "1", "c&d": "="}))
+}
+
+func TestCopyOptions(t *testing.T) {
+
+	assert := assert.New(t)
+	input := &s3.CopyObjectInput{}
+
+	for _, opt := range []CopyOption{
+		CopyMetadata("REPLACE", map[string]string{"foo": "bar"}),
+		CopyTagging("COPY", nil),
+		CopyIfMatch(`"etag"`),
+	} {
+		opt(input)
+	}
+
+	assert.Equal("REPLACE", aws.StringValue(input.MetadataDirective))
+	assert.Equal("bar", aws.StringValue(input.Metadata["foo"]))
+	assert.Equal("COPY", aws.StringValue(input.TaggingDirective))
+	assert.Nil(input.Tagging)
+	assert.Equal(`"etag"`, aws.StringValue(input.CopySourceIfMatch))
+}
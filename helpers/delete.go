@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// MaxDeleteKeys is the most keys one DeleteObjects request may name.
+const MaxDeleteKeys = 1000
+
+// ObjectIDs returns identifiers for the current versions of keys.
+func ObjectIDs(keys ...string) []*s3.ObjectIdentifier {
+
+	ids := make([]*s3.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		ids[i] = &s3.ObjectIdentifier{Key: aws.String(key)}
+	}
+
+	return ids
+}
+
+// DeleteObjectIDs deletes ids from bucket with one DeleteObjects request.
+func DeleteObjectIDs(svc *s3.S3, bucket string, ids []*s3.ObjectIdentifier, quiet bool) (*s3.DeleteObjectsOutput, error) {
+
+	return svc.DeleteObjects(&s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &s3.Delete{Objects: ids, Quiet: aws.Bool(quiet)},
+	})
+}
+
+// DeleteKeys deletes keys from bucket with one DeleteObjects request.
+func DeleteKeys(svc *s3.S3, bucket string, keys []string, quiet bool) (*s3.DeleteObjectsOutput, error) {
+
+	return DeleteObjectIDs(svc, bucket, ObjectIDs(keys...), quiet)
+}
+
+// DeletedKeys returns the keys listed as deleted in out.
+func DeletedKeys(out *s3.DeleteObjectsOutput) []string {
+
+	keys := []string{}
+	for _, d := range out.Deleted {
+		keys = append(keys, aws.StringValue(d.Key))
+	}
+
+	return keys
+}
+
+// EnableVersioning turns versioning on for bucket.
+func EnableVersioning(svc *s3.S3, bucket string) error {
+
+	_, err := svc.PutBucketVersioning(&s3.PutBucketVersioningInput{
+		Bucket: aws.String(bucket),
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(s3.BucketVersioningStatusEnabled),
+		},
+	})
+
+	return err
+}
+
+// ContentMD5 returns the Content-MD5 header value for body.
+func ContentMD5(body string) string {
+
+	sum := md5.Sum([]byte(body))
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// DeleteXML renders the body of a DeleteObjects request for keys.
+func DeleteXML(quiet bool, keys ...string) string {
+
+	var b strings.Builder
+	b.WriteString("<Delete>")
+	if quiet {
+		b.WriteString("<Quiet>true</Quiet>")
+	}
+	for _, key := range keys {
+		b.WriteString("<Object><Key>")
+		xml.EscapeText(&b, []byte(key))
+		b.WriteString("</Key></Object>")
+	}
+	b.WriteString("</Delete>")
+
+	return b.String()
+}
+
+// NewDeleteRequest returns a raw POST ?delete of bucket with body, adding
+// its Content-MD5 unless md5 is false.
+func NewDeleteRequest(bucket string, body string, md5 bool) *RawRequest {
+
+	r := NewRawRequest("POST", bucket, "", body)
+	r.Query = "delete"
+	if md5 {
+		r.Header("Content-MD5", ContentMD5(body))
+	}
+
+	return r
+}
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteXML(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("<Delete><Object><Key>a</Key></Object><Object><Key>b&amp;&lt;c&gt;</Key></Object></Delete>", DeleteXML(false, "a", "b&<c>"))
+	assert.Equal("<Delete><Quiet>true</Quiet></Delete>", DeleteXML(true))
+
+	assert.Equal("1B2M2Y8AsgTpgAmY7PhCfg==", ContentMD5(""))
+
+	r := NewDeleteRequest("bucket", "<Delete/>", true)
+	assert.Equal("POST", r.Method)
+	assert.Equal("/bucket", r.Path)
+	assert.Equal("delete", r.Query)
+	assert.Equal([]RawHeader{{"Content-MD5", ContentMD5("<Delete/>")}}, r.Headers)
+	assert.Nil(NewDeleteRequest("bucket", "<Delete/>", false).Headers)
+}
+
+func TestDeleteObjectsBatches(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			body, _ := ioutil.ReadAll(r.Body)
+			batches = append(batches, strings.Count(string(body), "<Key>"))
+			w.Write([]byte("<DeleteResult></DeleteResult>"))
+			return
+		}
+		var b strings.Builder
+		b.WriteString("<ListVersionsResult><IsTruncated>false</IsTruncated>")
+		for i := 0; i < 1500; i++ {
+			fmt.Fprintf(&b, "<Version><Key>key%d</Key><VersionId>v1</VersionId></Version>", i)
+		}
+		b.WriteString("<DeleteMarker><Key>gone</Key><VersionId>m1</VersionId></DeleteMarker></ListVersionsResult>")
+		w.Write([]byte(b.String()))
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	assert.Nil(DeleteObjects(client, "bucket"))
+	assert.Equal([]int{MaxDeleteKeys, 501}, batches)
+}
+
+func TestDeleteObjectsReportsErrors(t *testing.T) {
+
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.Write([]byte("<DeleteResult><Error><Key>locked</Key><Code>AccessDenied</Code><Message>denied</Message></Error></DeleteResult>"))
+			return
+		}
+		w.Write([]byte("<ListVersionsResult><IsTruncated>false</IsTruncated><Version><Key>locked</Key><VersionId>v1</VersionId></Version><Version><Key>free</Key><VersionId>v1</VersionId></Version></ListVersionsResult>"))
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	err := DeleteObjects(client, "bucket")
+	if assert.NotNil(err) {
+		assert.Equal(`failed to delete 1 objects from "bucket": locked (AccessDenied)`, err.Error())
+	}
+}
+
+func TestDeleteBuckets(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := strings.Trim(r.URL.Path, "/")
+		switch {
+		case bucket == "gone":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<Error><Code>NoSuchBucket</Code></Error>"))
+		case bucket == "locked":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+		case r.Method == "DELETE":
+			deleted = append(deleted, bucket)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte("<ListVersionsResult><IsTruncated>false</IsTruncated></ListVersionsResult>"))
+		}
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	failed, errs := DeleteBuckets(client, "mine", "gone", "locked")
+	assert.Equal([]string{"mine"}, deleted)
+	assert.Equal([]string{"locked"}, failed)
+	if assert.Equal(1, len(errs)) {
+		assert.Contains(errs[0].Error(), "AccessDenied")
+	}
+}
@@ -0,0 +1,284 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// ErrorStatuses maps the standard S3 error codes to their canonical HTTP
+// status, as documented in the S3 API reference. HEAD responses carry no
+// body, so the SDK reports them under the generic status codes listed last.
+var ErrorStatuses = map[string]int{
+	"AccessDenied":                            http.StatusForbidden,
+	"AccountProblem":                          http.StatusForbidden,
+	"AllAccessDisabled":                       http.StatusForbidden,
+	"AmbiguousGrantByEmailAddress":            http.StatusBadRequest,
+	"AuthorizationHeaderMalformed":            http.StatusBadRequest,
+	"BadDigest":                               http.StatusBadRequest,
+	"BucketAlreadyExists":                     http.StatusConflict,
+	"BucketAlreadyOwnedByYou":                 http.StatusConflict,
+	"BucketNotEmpty":                          http.StatusConflict,
+	"CredentialsNotSupported":                 http.StatusBadRequest,
+	"CrossLocationLoggingProhibited":          http.StatusForbidden,
+	"EntityTooLarge":                          http.StatusBadRequest,
+	"EntityTooSmall":                          http.StatusBadRequest,
+	"ExpectationFailed":                       http.StatusExpectationFailed,
+	"ExpiredToken":                            http.StatusBadRequest,
+	"IllegalLocationConstraintException":      http.StatusBadRequest,
+	"IllegalVersioningConfigurationException": http.StatusBadRequest,
+	"IncompleteBody":                          http.StatusBadRequest,
+	"InlineDataTooLarge":                      http.StatusBadRequest,
+	"InternalError":                           http.StatusInternalServerError,
+	"InvalidAccessKeyId":                      http.StatusForbidden,
+	"InvalidArgument":                         http.StatusBadRequest,
+	"InvalidBucketName":                       http.StatusBadRequest,
+	"InvalidBucketState":                      http.StatusConflict,
+	"InvalidDigest":                           http.StatusBadRequest,
+	"InvalidEncryptionAlgorithmError":         http.StatusBadRequest,
+	"InvalidLocationConstraint":               http.StatusBadRequest,
+	"InvalidObjectState":                      http.StatusForbidden,
+	"InvalidPart":                             http.StatusBadRequest,
+	"InvalidPartNumber":                       http.StatusRequestedRangeNotSatisfiable,
+	"InvalidPartOrder":                        http.StatusBadRequest,
+	"InvalidPayer":                            http.StatusForbidden,
+	"InvalidPolicyDocument":                   http.StatusBadRequest,
+	"InvalidRange":                            http.StatusRequestedRangeNotSatisfiable,
+	"InvalidRequest":                          http.StatusBadRequest,
+	"InvalidSecurity":                         http.StatusForbidden,
+	"InvalidStorageClass":                     http.StatusBadRequest,
+	"InvalidTag":                              http.StatusBadRequest,
+	"InvalidTargetBucketForLogging":           http.StatusBadRequest,
+	"InvalidToken":                            http.StatusBadRequest,
+	"InvalidURI":                              http.StatusBadRequest,
+	"KeyTooLongError":                         http.StatusBadRequest,
+	"MalformedACLError":                       http.StatusBadRequest,
+	"MalformedPOSTRequest":                    http.StatusBadRequest,
+	"MalformedXML":                            http.StatusBadRequest,
+	"MaxMessageLengthExceeded":                http.StatusBadRequest,
+	"MetadataTooLarge":                        http.StatusBadRequest,
+	"MethodNotAllowed":                        http.StatusMethodNotAllowed,
+	"MissingContentLength":                    http.StatusLengthRequired,
+	"MissingRequestBodyError":                 http.StatusBadRequest,
+	"MissingSecurityHeader":                   http.StatusBadRequest,
+	"NoSuchBucket":                            http.StatusNotFound,
+	"NoSuchBucketPolicy":                      http.StatusNotFound,
+	"NoSuchCORSConfiguration":                 http.StatusNotFound,
+	"NoSuchKey":                               http.StatusNotFound,
+	"NoSuchLifecycleConfiguration":            http.StatusNotFound,
+	"NoSuchTagSet":                            http.StatusNotFound,
+	"NoSuchUpload":                            http.StatusNotFound,
+	"NoSuchVersion":                           http.StatusNotFound,
+	"NotImplemented":                          http.StatusNotImplemented,
+	"NotSignedUp":                             http.StatusForbidden,
+	"OperationAborted":                        http.StatusConflict,
+	"PermanentRedirect":                       http.StatusMovedPermanently,
+	"PreconditionFailed":                      http.StatusPreconditionFailed,
+	"Redirect":                                http.StatusTemporaryRedirect,
+	"RequestIsNotMultiPartContent":            http.StatusBadRequest,
+	"RequestTimeTooSkewed":                    http.StatusForbidden,
+	"RequestTimeout":                          http.StatusBadRequest,
+	"ServiceUnavailable":                      http.StatusServiceUnavailable,
+	"SignatureDoesNotMatch":                   http.StatusForbidden,
+	"SlowDown":                                http.StatusServiceUnavailable,
+	"TemporaryRedirect":                       http.StatusTemporaryRedirect,
+	"TooManyBuckets":                          http.StatusBadRequest,
+	"UnexpectedContent":                       http.StatusBadRequest,
+	"UnresolvableGrantByEmailAddress":         http.StatusBadRequest,
+	"UserKeyMustBeSpecified":                  http.StatusBadRequest,
+	"XAmzContentSHA256Mismatch":               http.StatusBadRequest,
+
+	"BadRequest":  http.StatusBadRequest,
+	"Forbidden":   http.StatusForbidden,
+	"NotFound":    http.StatusNotFound,
+	"NotModified": http.StatusNotModified,
+}
+
+// ErrorSpec describes the S3 error a request is expected to fail with.
+type ErrorSpec struct {
+	Code string
+	// Status is the expected HTTP status; 0 takes it from ErrorStatuses.
+	Status int
+	// Message, if set, is a regular expression the error message must match.
+	Message string
+	// Headers must be present on the response. For SDK errors only
+	// x-amz-request-id and x-amz-id-2 can be checked.
+	Headers []string
+	// Resource, if set, must equal the Resource of the error document. Only
+	// raw responses carry it.
+	Resource string
+}
+
+// ExpectError returns the spec for code with its canonical status and the
+// x-amz-request-id header every S3 error carries.
+func ExpectError(code string) ErrorSpec {
+
+	return ErrorSpec{Code: code, Headers: []string{"x-amz-request-id"}}
+}
+
+// WithStatus overrides the expected status.
+func (s ErrorSpec) WithStatus(status int) ErrorSpec {
+
+	s.Status = status
+
+	return s
+}
+
+// WithMessage requires the error message to match pattern.
+func (s ErrorSpec) WithMessage(pattern string) ErrorSpec {
+
+	s.Message = pattern
+
+	return s
+}
+
+// WithHeaders requires the given response headers as well.
+func (s ErrorSpec) WithHeaders(names ...string) ErrorSpec {
+
+	s.Headers = append(append([]string(nil), s.Headers...), names...)
+
+	return s
+}
+
+// WithResource requires the Resource element of the error document.
+func (s ErrorSpec) WithResource(resource string) ErrorSpec {
+
+	s.Resource = resource
+
+	return s
+}
+
+func (s ErrorSpec) String() string {
+
+	status := s.Status
+	if status == 0 {
+		status = ErrorStatuses[s.Code]
+	}
+
+	return fmt.Sprintf("%s (%d)", s.Code, status)
+}
+
+// observedError is what a failed request returned, from an SDK error or a
+// raw response.
+type observedError struct {
+	code     string
+	status   int
+	message  string
+	resource string
+	raw      bool
+	header   func(name string) (string, bool)
+}
+
+// CheckError compares err with spec and returns a description of every
+// difference, or "" when they match.
+func CheckError(err error, spec ErrorSpec) string {
+
+	if err == nil {
+		return fmt.Sprintf("expected S3 error %s, got no error", spec)
+	}
+
+	reqErr, ok := err.(awserr.RequestFailure)
+	if !ok {
+		return fmt.Sprintf("expected S3 error %s, got an error that never reached the server: %v", spec, err)
+	}
+
+	hostID := ""
+	if withHost, ok := err.(interface{ HostID() string }); ok {
+		hostID = withHost.HostID()
+	}
+
+	return spec.diff(observedError{
+		code:    reqErr.Code(),
+		status:  reqErr.StatusCode(),
+		message: reqErr.Message(),
+		header: func(name string) (string, bool) {
+			switch strings.ToLower(name) {
+			case "x-amz-request-id":
+				return reqErr.RequestID(), true
+			case "x-amz-id-2":
+				return hostID, true
+			}
+			return "", false
+		},
+	})
+}
+
+// CheckRawError is CheckError for a raw response.
+func CheckRawError(resp *RawResponse, spec ErrorSpec) string {
+
+	if resp == nil {
+		return fmt.Sprintf("expected S3 error %s, got no response", spec)
+	}
+
+	observed := observedError{
+		status: resp.Status,
+		raw:    true,
+		header: func(name string) (string, bool) {
+			return resp.Header.Get(name), true
+		},
+	}
+	if resp.Error != nil {
+		observed.code = resp.Error.Code
+		observed.message = resp.Error.Message
+		observed.resource = resp.Error.Resource
+	}
+
+	return spec.diff(observed)
+}
+
+func (s ErrorSpec) diff(got observedError) string {
+
+	var lines []string
+	mismatch := func(format string, args ...interface{}) {
+		lines = append(lines, "  "+fmt.Sprintf(format, args...))
+	}
+
+	want, known := ErrorStatuses[s.Code]
+	if s.Status != 0 {
+		if known && s.Status != want {
+			mismatch("spec:     %s is %d in the catalog, spec expects %d", s.Code, want, s.Status)
+		}
+		want = s.Status
+	}
+
+	if got.code != s.Code {
+		mismatch("code:     expected %q, got %q", s.Code, got.code)
+	}
+	if want != 0 && got.status != want {
+		mismatch("status:   expected %d, got %d", want, got.status)
+	}
+	if canonical, ok := ErrorStatuses[got.code]; ok && got.code != s.Code && got.status != canonical {
+		mismatch("catalog:  %s is %d in the catalog, gateway sent %d", got.code, canonical, got.status)
+	}
+	if s.Message != "" {
+		if re, err := regexp.Compile(s.Message); err != nil {
+			mismatch("message:  bad pattern %q: %v", s.Message, err)
+		} else if !re.MatchString(got.message) {
+			mismatch("message:  expected to match %q, got %q", s.Message, got.message)
+		}
+	}
+	if s.Resource != "" {
+		if !got.raw {
+			mismatch("resource: not available on SDK errors, use a raw request")
+		} else if got.resource != s.Resource {
+			mismatch("resource: expected %q, got %q", s.Resource, got.resource)
+		}
+	}
+	for _, name := range s.Headers {
+		value, ok := got.header(name)
+		if !ok {
+			mismatch("header:   %s not available on SDK errors, use a raw request", name)
+		} else if value == "" {
+			mismatch("header:   %s missing", name)
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("S3 error mismatch for %s:\n%s", s, strings.Join(lines, "\n"))
+}
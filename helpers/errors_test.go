@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func requestFailure(code string, message string, status int, requestID string) error {
+
+	return awserr.NewRequestFailure(awserr.New(code, message, nil), status, requestID)
+}
+
+func TestCheckErrorMatches(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("", CheckError(requestFailure("NoSuchKey", "gone", 404, "req-1"), ExpectError("NoSuchKey")))
+	assert.Equal("", CheckError(requestFailure("NoSuchKey", "The specified key does not exist.", 404, "req-1"),
+		ExpectError("NoSuchKey").WithMessage("key does not exist")))
+	assert.Equal("", CheckError(requestFailure("Custom", "", 418, "req-1"), ExpectError("Custom").WithStatus(418)))
+	// Unknown codes without a status only check the code.
+	assert.Equal("", CheckError(requestFailure("Custom", "", 499, "req-1"), ExpectError("Custom")))
+}
+
+func TestCheckErrorMismatches(t *testing.T) {
+
+	assert := assert.New(t)
+
+	diff := CheckError(nil, ExpectError("NoSuchKey"))
+	assert.Equal("expected S3 error NoSuchKey (404), got no error", diff)
+
+	diff = CheckError(errors.New("dial tcp: refused"), ExpectError("NoSuchKey"))
+	assert.True(strings.Contains(diff, "never reached the server"))
+
+	diff = CheckError(requestFailure("NoSuchBucket", "", 400, ""), ExpectError("NoSuchKey").WithMessage("^key"))
+	assert.True(strings.HasPrefix(diff, "S3 error mismatch for NoSuchKey (404):\n"))
+	assert.True(strings.Contains(diff, `code:     expected "NoSuchKey", got "NoSuchBucket"`))
+	assert.True(strings.Contains(diff, "status:   expected 404, got 400"))
+	assert.True(strings.Contains(diff, "catalog:  NoSuchBucket is 404 in the catalog, gateway sent 400"))
+	assert.True(strings.Contains(diff, `message:  expected to match "^key", got ""`))
+	assert.True(strings.Contains(diff, "header:   x-amz-request-id missing"))
+
+	diff = CheckError(requestFailure("NoSuchKey", "", 400, "r"), ExpectError("NoSuchKey").WithStatus(400))
+	assert.Equal("S3 error mismatch for NoSuchKey (400):\n  spec:     NoSuchKey is 404 in the catalog, spec expects 400", diff)
+
+	diff = CheckError(requestFailure("NoSuchKey", "", 404, "r"), ExpectError("NoSuchKey").WithHeaders("ETag").WithResource("/b/k"))
+	assert.True(strings.Contains(diff, "header:   ETag not available on SDK errors"))
+	assert.True(strings.Contains(diff, "resource: not available on SDK errors"))
+}
+
+func TestCheckRawError(t *testing.T) {
+
+	assert := assert.New(t)
+
+	resp := &RawResponse{
+		Status: http.StatusNotFound,
+		Header: http.Header{"X-Amz-Request-Id": []string{"r"}},
+		Error:  &S3Error{Code: "NoSuchKey", Resource: "/b/k"},
+	}
+	assert.Equal("", CheckRawError(resp, ExpectError("NoSuchKey").WithResource("/b/k")))
+
+	diff := CheckRawError(resp, ExpectError("NoSuchKey").WithHeaders("x-amz-id-2").WithResource("/b/x"))
+	assert.True(strings.Contains(diff, "header:   x-amz-id-2 missing"))
+	assert.True(strings.Contains(diff, `resource: expected "/b/x", got "/b/k"`))
+
+	resp = &RawResponse{Status: http.StatusBadRequest, Header: http.Header{}}
+	assert.True(strings.Contains(CheckRawError(resp, ExpectError("InvalidRequest")), `code:     expected "InvalidRequest", got ""`))
+}
@@ -0,0 +1,477 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/spf13/viper"
+)
+
+// FaultKind selects what a Fault does to a matching request.
+type FaultKind int
+
+const (
+	// FaultResetRequest forwards only the first Bytes of the request body
+	// upstream, then drops both connections.
+	FaultResetRequest FaultKind = iota + 1
+	// FaultTruncateResponse sends the upstream headers but only the first
+	// Bytes of the response body, then drops the client connection.
+	FaultTruncateResponse
+	// FaultDelayResponse forwards the request and holds the response back
+	// for Delay.
+	FaultDelayResponse
+	// FaultDuplicateRequest sends the request upstream twice and returns the
+	// second response.
+	FaultDuplicateRequest
+	// FaultContentLength sends the real body upstream with a Content-Length
+	// header that is off by Bytes.
+	FaultContentLength
+)
+
+// Fault describes how the proxy mangles requests that match Method and Query.
+type Fault struct {
+	Kind  FaultKind
+	Bytes int64
+	Delay time.Duration
+
+	// Method matches the request method; empty matches any.
+	Method string
+	// Query must appear in the raw query string, e.g. "partNumber=".
+	Query string
+	// Times limits how many requests the fault applies to; 0 is unlimited.
+	Times int
+}
+
+// ResetRequestAfter drops the connection after n bytes of request body.
+func ResetRequestAfter(n int64) Fault {
+
+	return Fault{Kind: FaultResetRequest, Bytes: n}
+}
+
+// TruncateResponseAfter drops the connection after n bytes of response body.
+func TruncateResponseAfter(n int64) Fault {
+
+	return Fault{Kind: FaultTruncateResponse, Bytes: n}
+}
+
+// DelayResponse holds every matching response back for d.
+func DelayResponse(d time.Duration) Fault {
+
+	return Fault{Kind: FaultDelayResponse, Delay: d}
+}
+
+// DuplicateRequest replays every matching request upstream.
+func DuplicateRequest() Fault {
+
+	return Fault{Kind: FaultDuplicateRequest}
+}
+
+// DeclareContentLength sends a Content-Length that is delta bytes off the
+// actual body length.
+func DeclareContentLength(delta int64) Fault {
+
+	return Fault{Kind: FaultContentLength, Bytes: delta}
+}
+
+// On restricts the fault to requests with method whose query contains query.
+func (f Fault) On(method string, query string) Fault {
+
+	f.Method, f.Query = method, query
+	return f
+}
+
+// Once restricts the fault to the first matching request.
+func (f Fault) Once() Fault {
+
+	f.Times = 1
+	return f
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+
+	return (f.Method == "" || f.Method == r.Method) && strings.Contains(r.URL.RawQuery, f.Query)
+}
+
+// ProxiedRequest records a request the proxy saw and what it did with it.
+type ProxiedRequest struct {
+	Method   string
+	Path     string
+	Query    string
+	Fault    FaultKind
+	Upstream int
+}
+
+// FaultProxy is an in-process reverse proxy that sits between an SDK client
+// and the endpoint and injects network faults into matching requests.
+type FaultProxy struct {
+	upstream  *url.URL
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+	tls       *tls.Config
+
+	mu       sync.Mutex
+	faults   []*activeFault
+	requests []ProxiedRequest
+}
+
+type activeFault struct {
+	Fault
+	left int
+}
+
+// NewFaultProxy starts a proxy on a loopback port in front of upstream, a URL
+// such as "http://127.0.0.1:5200", reaching an https upstream with the TLS
+// settings of the config.
+func NewFaultProxy(upstream string) (*FaultProxy, error) {
+
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+	conf, _ := TLSConfig()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &FaultProxy{
+		upstream:  u,
+		listener:  l,
+		transport: &http.Transport{DisableKeepAlives: true, TLSClientConfig: tlsClientConfig(conf, u.Hostname())},
+		tls:       conf,
+	}
+	p.server = &http.Server{Handler: p}
+
+	go p.server.Serve(l)
+
+	return p, nil
+}
+
+// NewFaultProxyForConfig starts a proxy in front of the s3main endpoint.
+func NewFaultProxyForConfig() (*FaultProxy, error) {
+
+	scheme := "http"
+	if viper.GetBool("s3main.is_secure") {
+		scheme = "https"
+	}
+
+	return NewFaultProxy(scheme + "://" + viper.GetString("s3main.endpoint"))
+}
+
+// Endpoint returns the host:port clients should connect to.
+func (p *FaultProxy) Endpoint() string {
+
+	return p.listener.Addr().String()
+}
+
+// Client returns an s3main client that talks to the endpoint through the
+// proxy, retrying failed requests at most maxRetries times.
+func (p *FaultProxy) Client(maxRetries int) *s3.S3 {
+
+	return s3.New(sess, newConfig(Creds).
+		WithEndpoint(p.Endpoint()).
+		WithDisableSSL(true).
+		WithMaxRetries(maxRetries))
+}
+
+// Inject adds faults; they are tried in order and the first match wins.
+func (p *FaultProxy) Inject(faults ...Fault) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, f := range faults {
+		left := f.Times
+		if left == 0 {
+			left = -1
+		}
+		p.faults = append(p.faults, &activeFault{Fault: f, left: left})
+	}
+}
+
+// Clear removes all faults and forgets recorded requests.
+func (p *FaultProxy) Clear() {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.faults = nil
+	p.requests = nil
+}
+
+// Requests returns the requests seen so far.
+func (p *FaultProxy) Requests() []ProxiedRequest {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]ProxiedRequest(nil), p.requests...)
+}
+
+// Close stops the proxy.
+func (p *FaultProxy) Close() error {
+
+	p.transport.CloseIdleConnections()
+	return p.server.Close()
+}
+
+func (p *FaultProxy) take(r *http.Request) *Fault {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, f := range p.faults {
+		if f.left != 0 && f.matches(r) {
+			if f.left > 0 {
+				f.left--
+			}
+			c := f.Fault
+			return &c
+		}
+	}
+
+	return nil
+}
+
+func (p *FaultProxy) record(r *http.Request, kind FaultKind, upstream int) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, ProxiedRequest{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Query:    r.URL.RawQuery,
+		Fault:    kind,
+		Upstream: upstream,
+	})
+}
+
+var hopHeaders = []string{"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+// outgoing clones r for the upstream, keeping the Host the client signed.
+func (p *FaultProxy) outgoing(r *http.Request, body io.Reader, length int64) *http.Request {
+
+	u := *r.URL
+	u.Scheme = p.upstream.Scheme
+	u.Host = p.upstream.Host
+
+	out := r.Clone(r.Context())
+	out.URL = &u
+	out.Host = r.Host
+	out.RequestURI = ""
+	out.ContentLength = length
+	out.Body = ioutil.NopCloser(body)
+	if body == nil {
+		out.Body = nil
+	}
+	for _, h := range hopHeaders {
+		out.Header.Del(h)
+	}
+
+	return out
+}
+
+type resetReader struct {
+	r io.Reader
+}
+
+func (rr *resetReader) Read(b []byte) (int, error) {
+
+	n, err := rr.r.Read(b)
+	if err == io.EOF {
+		return n, errors.New("fault proxy: injected reset")
+	}
+
+	return n, err
+}
+
+func copyResponse(w http.ResponseWriter, resp *http.Response, limit int64) {
+
+	for k, vv := range resp.Header {
+		for _, v := range vv {
+			w.Header().Add(k, v)
+		}
+	}
+	if resp.ContentLength >= 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(resp.ContentLength))
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	if limit < 0 {
+		io.Copy(w, resp.Body)
+		return
+	}
+
+	io.CopyN(w, resp.Body, limit)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	panic(http.ErrAbortHandler)
+}
+
+func (p *FaultProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	fault := p.take(r)
+	var kind FaultKind
+	if fault != nil {
+		kind = fault.Kind
+	}
+
+	switch kind {
+	case FaultResetRequest:
+		p.record(r, kind, 1)
+		out := p.outgoing(r, &resetReader{io.LimitReader(r.Body, fault.Bytes)}, r.ContentLength)
+		if resp, err := p.transport.RoundTrip(out); err == nil {
+			resp.Body.Close()
+		}
+		panic(http.ErrAbortHandler)
+
+	case FaultDuplicateRequest:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(http.ErrAbortHandler)
+		}
+		p.record(r, kind, 2)
+		if resp, err := p.transport.RoundTrip(p.outgoing(r, bytes.NewReader(body), int64(len(body)))); err == nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		p.forward(w, r, bytes.NewReader(body), int64(len(body)), -1)
+
+	case FaultContentLength:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(http.ErrAbortHandler)
+		}
+		p.record(r, kind, 1)
+		resp, err := p.rawRoundTrip(r, body, int64(len(body))+fault.Bytes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		copyResponse(w, resp, -1)
+
+	case FaultTruncateResponse:
+		p.record(r, kind, 1)
+		p.forward(w, r, r.Body, r.ContentLength, fault.Bytes)
+
+	case FaultDelayResponse:
+		p.record(r, kind, 1)
+		resp, err := p.transport.RoundTrip(p.outgoing(r, r.Body, r.ContentLength))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		time.Sleep(fault.Delay)
+		copyResponse(w, resp, -1)
+
+	default:
+		p.record(r, kind, 1)
+		p.forward(w, r, r.Body, r.ContentLength, -1)
+	}
+}
+
+func (p *FaultProxy) forward(w http.ResponseWriter, r *http.Request, body io.Reader, length int64, limit int64) {
+
+	if length == 0 {
+		body = nil
+	}
+
+	resp, err := p.transport.RoundTrip(p.outgoing(r, body, length))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	copyResponse(w, resp, limit)
+}
+
+// rawRoundTrip writes the request by hand so the declared Content-Length can
+// disagree with the body. When the declared length is longer than the body
+// the write side is closed so the server sees the body end early.
+func (p *FaultProxy) rawRoundTrip(r *http.Request, body []byte, declared int64) (*http.Response, error) {
+
+	var conn net.Conn
+	var err error
+
+	addr := p.upstream.Host
+	if p.upstream.Scheme == "https" {
+		if _, _, e := net.SplitHostPort(addr); e != nil {
+			addr += ":443"
+		}
+		conn, err = tls.Dial("tcp", addr, tlsClientConfig(p.tls, p.upstream.Hostname()))
+	} else {
+		if _, _, e := net.SplitHostPort(addr); e != nil {
+			addr += ":80"
+		}
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\nHost: %s\r\n", r.Method, r.URL.RequestURI(), r.Host)
+	header := r.Header.Clone()
+	header.Del("Content-Length")
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+	header.Write(&buf)
+	fmt.Fprintf(&buf, "Content-Length: %d\r\nConnection: close\r\n\r\n", declared)
+	buf.Write(body)
+
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if declared > int64(len(body)) {
+		if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+			cw.CloseWrite()
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	br := bufio.NewReader(conn)
+	for {
+		resp, err := http.ReadResponse(br, r)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusContinue {
+			continue
+		}
+		resp.Body = &connBody{ReadCloser: resp.Body, conn: conn}
+		return resp, nil
+	}
+}
+
+type connBody struct {
+	io.ReadCloser
+	conn net.Conn
+}
+
+func (b *connBody) Close() error {
+
+	b.ReadCloser.Close()
+	return b.conn.Close()
+}
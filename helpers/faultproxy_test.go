@@ -0,0 +1,187 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upstreamRecorder struct {
+	mu     sync.Mutex
+	bodies []string
+	errs   []error
+	hosts  []string
+}
+
+func (u *upstreamRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, err := ioutil.ReadAll(r.Body)
+
+	u.mu.Lock()
+	u.bodies = append(u.bodies, string(body))
+	u.errs = append(u.errs, err)
+	u.hosts = append(u.hosts, r.Host)
+	u.mu.Unlock()
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.Write([]byte("response-body"))
+}
+
+func (u *upstreamRecorder) calls() ([]string, []error) {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return append([]string(nil), u.bodies...), append([]error(nil), u.errs...)
+}
+
+func newTestProxy(t *testing.T) (*FaultProxy, *upstreamRecorder, func()) {
+
+	rec := &upstreamRecorder{}
+	upstream := httptest.NewServer(rec)
+
+	p, err := NewFaultProxy(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p, rec, func() {
+		p.Close()
+		upstream.Close()
+	}
+}
+
+func put(p *FaultProxy, path string, body string) (*http.Response, error) {
+
+	req, _ := http.NewRequest("PUT", "http://"+p.Endpoint()+path, strings.NewReader(body))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFaultProxyPassThrough(t *testing.T) {
+
+	assert := assert.New(t)
+	p, rec, done := newTestProxy(t)
+	defer done()
+
+	resp, err := put(p, "/bucket/key", "hello")
+	assert.Nil(err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(err)
+	assert.Equal("response-body", string(body))
+
+	bodies, _ := rec.calls()
+	assert.Equal([]string{"hello"}, bodies)
+	assert.Equal(p.Endpoint(), rec.hosts[0])
+	assert.Equal(1, len(p.Requests()))
+}
+
+func TestFaultProxyResetRequest(t *testing.T) {
+
+	assert := assert.New(t)
+	p, rec, done := newTestProxy(t)
+	defer done()
+
+	p.Inject(ResetRequestAfter(3).On("PUT", "").Once())
+
+	_, err := put(p, "/bucket/key", "hello world")
+	assert.NotNil(err)
+
+	time.Sleep(100 * time.Millisecond)
+	bodies, errs := rec.calls()
+	if assert.Equal(1, len(bodies)) {
+		assert.Equal("hel", bodies[0])
+		assert.NotNil(errs[0])
+	}
+
+	// Once: the next request passes through.
+	resp, err := put(p, "/bucket/key", "hello")
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func TestFaultProxyTruncateResponse(t *testing.T) {
+
+	assert := assert.New(t)
+	p, _, done := newTestProxy(t)
+	defer done()
+
+	p.Inject(TruncateResponseAfter(4))
+
+	resp, err := put(p, "/bucket/key", "hello")
+	assert.Nil(err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NotNil(err)
+	assert.Equal("resp", string(body))
+}
+
+func TestFaultProxyDuplicateRequest(t *testing.T) {
+
+	assert := assert.New(t)
+	p, rec, done := newTestProxy(t)
+	defer done()
+
+	p.Inject(DuplicateRequest().On("PUT", "partNumber="))
+
+	_, err := put(p, "/bucket/key?partNumber=1&uploadId=x", "part")
+	assert.Nil(err)
+	_, err = put(p, "/bucket/key", "plain")
+	assert.Nil(err)
+
+	bodies, _ := rec.calls()
+	assert.Equal([]string{"part", "part", "plain"}, bodies)
+	assert.Equal(FaultDuplicateRequest, p.Requests()[0].Fault)
+	assert.Equal(2, p.Requests()[0].Upstream)
+}
+
+func TestFaultProxyContentLength(t *testing.T) {
+
+	assert := assert.New(t)
+	p, rec, done := newTestProxy(t)
+	defer done()
+
+	p.Inject(DeclareContentLength(5))
+
+	resp, err := put(p, "/bucket/key", "hello")
+	assert.Nil(err)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	bodies, errs := rec.calls()
+	if assert.Equal(1, len(bodies)) {
+		assert.Equal("hello", bodies[0])
+		assert.NotNil(errs[0])
+	}
+
+	p.Clear()
+	p.Inject(DeclareContentLength(-2))
+
+	resp, err = put(p, "/bucket/key", "hello")
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	bodies, _ = rec.calls()
+	assert.Equal("hel", bodies[1])
+}
+
+func TestFaultProxyDelayResponse(t *testing.T) {
+
+	assert := assert.New(t)
+	p, _, done := newTestProxy(t)
+	defer done()
+
+	p.Inject(DelayResponse(300 * time.Millisecond))
+
+	start := time.Now()
+	resp, err := put(p, "/bucket/key", "hello")
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.True(time.Since(start) >= 300*time.Millisecond)
+}
@@ -0,0 +1,346 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"golang.org/x/net/http/httpguts"
+)
+
+// MaxKeyLength is the longest object key, in bytes, that S3 accepts.
+const MaxKeyLength = 1024
+
+const (
+	// SpecialCharset holds the ASCII characters that most often trip up key
+	// encoding: percent signs, plus, spaces, slashes and URL delimiters.
+	SpecialCharset = "%+ /?#&=:;,@$!*'()[]~"
+
+	// UnicodeCharset mixes accented latin, CJK, RTL and astral-plane runes.
+	UnicodeCharset = "äöüßéñçøåЖжΩλ中文日本語한국어עבריתعربي🙂🚀𝄞"
+
+	// ControlCharset holds non-printing characters that are still valid in keys.
+	ControlCharset = "\x01\x07\x08\x09\x0a\x0b\x0c\x0d\x1b\x7f"
+)
+
+var keyCharsets = []string{charset, SpecialCharset, UnicodeCharset, ControlCharset}
+
+// EdgeCaseKeys returns the fixed corpus of keys every round-trip run covers.
+func EdgeCaseKeys() []string {
+
+	return []string{
+		"key1",
+		"b/a/c",
+		"with space",
+		" leading-and-trailing ",
+		"percent%20encoded",
+		"percent%",
+		"plus+sign",
+		"a+b c%2Bd",
+		"double//slash",
+		"//leading-slashes",
+		"trailing/",
+		"trailing//",
+		"dot/./segment",
+		"dotdot/../segment",
+		"question?mark",
+		"hash#fragment",
+		"amp&eq=semi;",
+		"tilde~star*",
+		"quote'double\"",
+		"back\\slash",
+		"<angle>brackets",
+		"café",
+		"ключ",
+		"中文/日本語/한국어",
+		"emoji🙂🚀",
+		"𝄞-astral",
+		"\x01control",
+		"tab\tkey",
+		"new\nline",
+		"carriage\rreturn",
+		"del\x7f",
+		strings.Repeat("a", MaxKeyLength),
+		strings.Repeat("é", MaxKeyLength/2),
+		strings.Repeat("a/", MaxKeyLength/2),
+	}
+}
+
+// EdgeCaseMetadata returns user metadata with unusual but legal header values.
+func EdgeCaseMetadata() map[string]string {
+
+	return map[string]string{
+		"plain":       "value",
+		"empty":       "",
+		"spaces":      "a  b   c",
+		"specials":    "some-value=!@#$%^&* (+)",
+		"quotes":      `"quoted" 'single'`,
+		"tab":         "tab\tinside",
+		"comma-list":  "a, b, c",
+		"semicolon":   "k=v; k2=v2",
+		"percent":     "100%25",
+		"unicode":     "café ключ 中文",
+		"long":        strings.Repeat("x", 1024),
+		"mixed-case":  "MiXeD",
+		"with_under":  "underscore",
+		"digits-0123": "0123456789",
+	}
+}
+
+// RandomKey builds a key of at most length runes out of runs drawn from the
+// plain, special, unicode and control charsets, capped at MaxKeyLength bytes.
+func RandomKey(length int) string {
+
+	var b strings.Builder
+
+	for utf8.RuneCountInString(b.String()) < length {
+		run := 1 + seededRand.Intn(8)
+		if left := length - utf8.RuneCountInString(b.String()); run > left {
+			run = left
+		}
+		set := keyCharsets[seededRand.Intn(len(keyCharsets))]
+		b.WriteString(StringWithCharset(run, set))
+	}
+
+	key := b.String()
+	for len(key) > MaxKeyLength {
+		_, size := utf8.DecodeLastRuneInString(key)
+		key = key[:len(key)-size]
+	}
+
+	if key == "" {
+		return String(1)
+	}
+
+	return key
+}
+
+// RandomMetadata returns n user metadata entries with lowercase names and
+// printable values of up to 64 characters.
+func RandomMetadata(n int) map[string]string {
+
+	values := charset + SpecialCharset + "\t\"<>{}|^`"
+	metadata := make(map[string]string, n)
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("%s-%d", String(1+seededRand.Intn(10)), i)
+		metadata[name] = StringWithCharset(seededRand.Intn(64), values)
+	}
+
+	return metadata
+}
+
+// IsValidObjectKey reports whether key is something S3 should accept at all:
+// non-empty, valid UTF-8 and no longer than MaxKeyLength bytes.
+func IsValidObjectKey(key string) bool {
+
+	return key != "" && len(key) <= MaxKeyLength && utf8.ValidString(key)
+}
+
+// IsValidMetadata reports whether name and value can be sent as an
+// x-amz-meta-* header without the HTTP client rejecting them.
+func IsValidMetadata(name string, value string) bool {
+
+	return name != "" && httpguts.ValidHeaderFieldName("X-Amz-Meta-"+name) &&
+		httpguts.ValidHeaderFieldValue(value) && utf8.ValidString(value)
+}
+
+// EscapeCopySource builds an x-amz-copy-source value for bucket/key,
+// percent-encoding every key segment including '+'.
+func EscapeCopySource(bucket string, key string) string {
+
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = strings.Replace(url.PathEscape(s), "+", "%2B", -1)
+	}
+
+	return bucket + "/" + strings.Join(segments, "/")
+}
+
+// NormalizeMetadata lowercases metadata names, decodes RFC 2047 values and
+// trims the surrounding whitespace HTTP does not preserve.
+func NormalizeMetadata(metadata map[string]*string) map[string]string {
+
+	dec := new(mime.WordDecoder)
+	norm := make(map[string]string, len(metadata))
+
+	for k, v := range metadata {
+		value := aws.StringValue(v)
+		if decoded, err := dec.DecodeHeader(value); err == nil {
+			value = decoded
+		}
+		norm[strings.ToLower(k)] = strings.TrimSpace(value)
+	}
+
+	return norm
+}
+
+func normalizeSent(metadata map[string]string) map[string]string {
+
+	norm := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		norm[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+
+	return norm
+}
+
+func diffMetadata(op string, want map[string]string, got map[string]*string) error {
+
+	norm := NormalizeMetadata(got)
+	if len(norm) != len(want) {
+		return fmt.Errorf("%s: metadata has %d entries, want %d: %v", op, len(norm), len(want), norm)
+	}
+
+	for k, v := range want {
+		if norm[k] != v {
+			return fmt.Errorf("%s: metadata %q = %q, want %q", op, k, norm[k], v)
+		}
+	}
+
+	return nil
+}
+
+// NewKeyConn returns a client like NewStyledConn that sends keys exactly as
+// given. The SDK otherwise cleans the request path, turning "a//b", "a/./b"
+// and "a/../b" into other keys before they reach the gateway.
+func NewKeyConn(creds *credentials.Credentials, style AddressingStyle) *s3.S3 {
+
+	config := newStyledConfig(creds, style).WithLogLevel(logLevel())
+	config.DisableRestProtocolURICleaning = aws.Bool(true)
+
+	client := s3.New(sess, config)
+	Cassettes.Install(&client.Handlers)
+
+	return client
+}
+
+// RoundTripObject writes content under key with the given user metadata and
+// checks that GET, HEAD, LIST, COPY (into copyBucket) and DELETE all agree on
+// the key, body and metadata. It returns the first disagreement it finds.
+func RoundTripObject(svc *s3.S3, bucket string, copyBucket string, key string, content string, metadata map[string]string) error {
+
+	want := normalizeSent(metadata)
+
+	_, err := svc.PutObject(&s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     strings.NewReader(content),
+		Metadata: aws.StringMap(metadata),
+	})
+	if err != nil {
+		return fmt.Errorf("PUT %q: %v", key, err)
+	}
+
+	obj, err := svc.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return fmt.Errorf("GET %q: %v", key, err)
+	}
+	body, err := ioutil.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		return fmt.Errorf("GET %q: %v", key, err)
+	}
+	if string(body) != content {
+		return fmt.Errorf("GET %q: body %q, want %q", key, body, content)
+	}
+	if err := diffMetadata("GET "+strconv.Quote(key), want, obj.Metadata); err != nil {
+		return err
+	}
+
+	head, err := svc.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return fmt.Errorf("HEAD %q: %v", key, err)
+	}
+	if aws.Int64Value(head.ContentLength) != int64(len(content)) {
+		return fmt.Errorf("HEAD %q: content length %d, want %d", key, aws.Int64Value(head.ContentLength), len(content))
+	}
+	if err := diffMetadata("HEAD "+strconv.Quote(key), want, head.Metadata); err != nil {
+		return err
+	}
+
+	list, err := svc.ListObjects(&s3.ListObjectsInput{
+		Bucket:       aws.String(bucket),
+		Prefix:       aws.String(key),
+		EncodingType: aws.String(s3.EncodingTypeUrl),
+	})
+	if err != nil {
+		return fmt.Errorf("LIST %q: %v", key, err)
+	}
+	found := false
+	for _, o := range list.Contents {
+		listed, err := url.QueryUnescape(aws.StringValue(o.Key))
+		if err != nil {
+			return fmt.Errorf("LIST %q: undecodable key %q: %v", key, aws.StringValue(o.Key), err)
+		}
+		if listed == key {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("LIST %q: key missing from listing", key)
+	}
+
+	_, err = svc.CopyObject(&s3.CopyObjectInput{
+		Bucket:     aws.String(copyBucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(EscapeCopySource(bucket, key)),
+	})
+	if err != nil {
+		return fmt.Errorf("COPY %q: %v", key, err)
+	}
+	copied, err := svc.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(copyBucket), Key: aws.String(key)})
+	if err != nil {
+		return fmt.Errorf("HEAD copy of %q: %v", key, err)
+	}
+	if aws.StringValue(copied.ETag) != aws.StringValue(head.ETag) {
+		return fmt.Errorf("COPY %q: etag %s, want %s", key, aws.StringValue(copied.ETag), aws.StringValue(head.ETag))
+	}
+	if err := diffMetadata("COPY "+strconv.Quote(key), want, copied.Metadata); err != nil {
+		return err
+	}
+
+	for _, b := range []string{bucket, copyBucket} {
+		if err := DeleteObject(svc, b, key); err != nil {
+			return fmt.Errorf("DELETE %q from %s: %v", key, b, err)
+		}
+		_, err = svc.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(b), Key: aws.String(key)})
+		if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != "NotFound" {
+			return fmt.Errorf("HEAD %q after DELETE from %s: %v, want NotFound", key, b, err)
+		}
+	}
+
+	return nil
+}
+
+// SaveFuzzSeed writes values into dir in the "go test fuzz v1" corpus format,
+// so that an input found by a generated run becomes a regression seed of the
+// matching native fuzz target. It returns the path of the seed file.
+func SaveFuzzSeed(dir string, values ...string) (string, error) {
+
+	var b strings.Builder
+	b.WriteString("go test fuzz v1\n")
+	for _, v := range values {
+		fmt.Fprintf(&b, "string(%s)\n", strconv.Quote(v))
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	path := filepath.Join(dir, fmt.Sprintf("%x", sum)[:16])
+
+	return path, ioutil.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdgeCaseKeysValid(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for _, key := range EdgeCaseKeys() {
+		assert.True(IsValidObjectKey(key), "%q", key)
+	}
+
+	assert.False(IsValidObjectKey(""))
+	assert.False(IsValidObjectKey(strings.Repeat("a", MaxKeyLength+1)))
+	assert.False(IsValidObjectKey("\xff"))
+}
+
+func TestRandomKeyReproducible(t *testing.T) {
+
+	assert := assert.New(t)
+	saved := GetSeed()
+	defer Seed(saved)
+
+	Seed(42)
+	first := []string{RandomKey(20), RandomKey(2000)}
+	Seed(42)
+	second := []string{RandomKey(20), RandomKey(2000)}
+
+	assert.Equal(first, second)
+	assert.Equal(20, utf8.RuneCountInString(first[0]))
+	for _, key := range first {
+		assert.True(IsValidObjectKey(key), "%q", key)
+	}
+}
+
+func TestRandomMetadataValid(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for k, v := range RandomMetadata(20) {
+		assert.True(IsValidMetadata(k, v), "%q: %q", k, v)
+	}
+
+	assert.False(IsValidMetadata("bad name", "v"))
+	assert.False(IsValidMetadata("name", "new\nline"))
+}
+
+func TestEscapeCopySource(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("bucket/a%20b/c%2Bd/%25", EscapeCopySource("bucket", "a b/c+d/%"))
+	assert.Equal("bucket/caf%C3%A9/", EscapeCopySource("bucket", "café/"))
+}
+
+func TestNormalizeMetadata(t *testing.T) {
+
+	assert := assert.New(t)
+
+	got := NormalizeMetadata(map[string]*string{
+		"Mymeta":  aws.String(" padded "),
+		"Encoded": aws.String("=?UTF-8?B?Y2Fmw6k=?="),
+	})
+
+	assert.Equal(map[string]string{"mymeta": "padded", "encoded": "café"}, got)
+}
+
+func TestSaveFuzzSeed(t *testing.T) {
+
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "fuzz")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	path, err := SaveFuzzSeed(dir, "a\nb", "c")
+	assert.Nil(err)
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(err)
+	assert.Equal("go test fuzz v1\nstring(\"a\\nb\")\nstring(\"c\")\n", string(data))
+}
+
+func TestNewKeyConnKeepsKey(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+	}))
+	defer server.Close()
+
+	creds := credentials.NewStaticCredentials("AKID", "SECRET", "")
+	for _, client := range []*s3.S3{NewStyledConn(creds, PathStyle), NewKeyConn(creds, PathStyle)} {
+		client.Endpoint = server.URL
+		_, err := client.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String("bucket"),
+			Key:    aws.String("a//b"),
+			Body:   strings.NewReader("x"),
+		})
+		assert.Nil(err)
+	}
+
+	assert.Equal([]string{"/bucket/a/b", "/bucket/a//b"}, paths)
+}
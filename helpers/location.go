@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/spf13/viper"
+)
+
+// Region returns the region of the s3main endpoint.
+func Region() string {
+
+	return viper.GetString("s3main.region")
+}
+
+// DefaultBucketRegion returns the region of a bucket created without a
+// location constraint, s3main.default_bucket_region in the config. It
+// defaults to us-east-1, as on AWS, whatever region the client signs for.
+func DefaultBucketRegion() string {
+
+	if region := viper.GetString("s3main.default_bucket_region"); region != "" {
+		return region
+	}
+
+	return "us-east-1"
+}
+
+// OtherRegion returns a valid region the endpoint does not serve,
+// s3main.other_region in the config, for mismatch tests.
+func OtherRegion() string {
+
+	if region := viper.GetString("s3main.other_region"); region != "" {
+		return region
+	}
+	if Region() == "us-west-2" {
+		return "eu-west-1"
+	}
+
+	return "us-west-2"
+}
+
+// NewRegionConn returns a client signing with creds for region instead of
+// the configured one.
+func NewRegionConn(creds *credentials.Credentials, region string) *s3.S3 {
+
+	client := s3.New(sess, newStyledConfig(creds, AddressingStyles()[0]).WithRegion(region))
+	Cassettes.Install(&client.Handlers)
+
+	return client
+}
+
+// CreateBucketInRegion creates bucket with constraint as its location
+// constraint; an empty constraint sends no CreateBucketConfiguration.
+func CreateBucketInRegion(svc *s3.S3, bucket string, constraint string) error {
+
+	input := &s3.CreateBucketInput{Bucket: aws.String(bucket)}
+	if constraint != "" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(constraint),
+		}
+	}
+	_, err := svc.CreateBucket(input)
+
+	return err
+}
+
+// GetBucketLocation returns the location constraint of bucket as stored,
+// "" for us-east-1.
+func GetBucketLocation(svc *s3.S3, bucket string) (string, error) {
+
+	out, err := svc.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.LocationConstraint), nil
+}
+
+// HeadBucketRegion sends HEAD bucket and returns its x-amz-bucket-region
+// header.
+func HeadBucketRegion(svc *s3.S3, bucket string) (string, error) {
+
+	req, _ := svc.HeadBucketRequest(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err := req.Send(); err != nil {
+		return "", err
+	}
+
+	return req.HTTPResponse.Header.Get("X-Amz-Bucket-Region"), nil
+}
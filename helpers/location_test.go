@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOtherRegion(t *testing.T) {
+
+	assert := assert.New(t)
+
+	withConfig(t, map[string]string{"s3main.region": "us-east-1", "s3main.other_region": ""})
+	assert.Equal("us-west-2", OtherRegion())
+
+	withConfig(t, map[string]string{"s3main.region": "us-west-2"})
+	assert.Equal("eu-west-1", OtherRegion())
+
+	withConfig(t, map[string]string{"s3main.other_region": "zg2"})
+	assert.Equal("zg2", OtherRegion())
+}
+
+func TestBucketLocationHelpers(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var created string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT":
+			body, _ := ioutil.ReadAll(r.Body)
+			created = string(body)
+		case r.Method == "HEAD":
+			w.Header().Set("X-Amz-Bucket-Region", "eu-west-1")
+		default:
+			w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">eu-west-1</LocationConstraint>`))
+		}
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	assert.Nil(CreateBucketInRegion(client, "bucket", ""))
+	assert.Equal("", created)
+	assert.Nil(CreateBucketInRegion(client, "bucket", "eu-west-1"))
+	assert.Contains(created, "<LocationConstraint>eu-west-1</LocationConstraint>")
+
+	location, err := GetBucketLocation(client, "bucket")
+	assert.Nil(err)
+	assert.Equal("eu-west-1", location)
+
+	region, err := HeadBucketRegion(client, "bucket")
+	assert.Nil(err)
+	assert.Equal("eu-west-1", region)
+}
@@ -0,0 +1,171 @@
+package helpers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// MaxMetadataSize is the limit S3 puts on user metadata: the UTF-8 bytes
+// of every name and value added up, without the x-amz-meta- prefix.
+const MaxMetadataSize = 2048
+
+// MetadataSize returns the size of metadata as S3 counts it against
+// MaxMetadataSize.
+func MetadataSize(metadata map[string]string) int {
+
+	size := 0
+	for name, value := range metadata {
+		size += len(name) + len(value)
+	}
+
+	return size
+}
+
+// ObjectHeaders are the standard headers S3 stores with an object. Empty
+// fields are not sent. Expires is an HTTP date.
+type ObjectHeaders struct {
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	Expires            string
+}
+
+func (h ObjectHeaders) expires() *time.Time {
+
+	if h.Expires == "" {
+		return nil
+	}
+	t, err := http.ParseTime(h.Expires)
+	if err != nil {
+		return nil
+	}
+
+	return aws.Time(t)
+}
+
+// PutObjectWithHeaders writes content with the standard headers h and
+// user metadata.
+func PutObjectWithHeaders(svc *s3.S3, bucket string, key string, content string, h ObjectHeaders, metadata map[string]string) error {
+
+	_, err := svc.PutObject(&s3.PutObjectInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		Body:               strings.NewReader(content),
+		ContentType:        optionalString(h.ContentType),
+		ContentEncoding:    optionalString(h.ContentEncoding),
+		ContentDisposition: optionalString(h.ContentDisposition),
+		ContentLanguage:    optionalString(h.ContentLanguage),
+		CacheControl:       optionalString(h.CacheControl),
+		Expires:            h.expires(),
+		Metadata:           aws.StringMap(metadata),
+	})
+
+	return err
+}
+
+// MultipartUploadWithHeaders is MultipartUploadParts for an upload created
+// with the standard headers h and user metadata.
+func MultipartUploadWithHeaders(svc *s3.S3, bucket string, key string, payloads []string, h ObjectHeaders, metadata map[string]string) error {
+
+	upload, err := svc.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		ContentType:        optionalString(h.ContentType),
+		ContentEncoding:    optionalString(h.ContentEncoding),
+		ContentDisposition: optionalString(h.ContentDisposition),
+		ContentLanguage:    optionalString(h.ContentLanguage),
+		CacheControl:       optionalString(h.CacheControl),
+		Expires:            h.expires(),
+		Metadata:           aws.StringMap(metadata),
+	})
+	if err != nil {
+		return err
+	}
+
+	var parts []*s3.CompletedPart
+	for i, payload := range payloads {
+		number := int64(i + 1)
+		part, err := Uploadpart(svc, bucket, key, aws.StringValue(upload.UploadId), payload, number)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, &s3.CompletedPart{ETag: part.ETag, PartNumber: aws.Int64(number)})
+	}
+
+	_, err = svc.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+
+	return err
+}
+
+// CopyHeaders sets the standard headers of a copy; they only take effect
+// with the REPLACE metadata directive.
+func CopyHeaders(h ObjectHeaders) CopyOption {
+
+	return func(in *s3.CopyObjectInput) {
+		in.ContentType = optionalString(h.ContentType)
+		in.ContentEncoding = optionalString(h.ContentEncoding)
+		in.ContentDisposition = optionalString(h.ContentDisposition)
+		in.ContentLanguage = optionalString(h.ContentLanguage)
+		in.CacheControl = optionalString(h.CacheControl)
+		in.Expires = h.expires()
+	}
+}
+
+// HeadObjectHeaders returns the standard headers and normalized user
+// metadata of bucket/key as HEAD reports them.
+func HeadObjectHeaders(svc *s3.S3, bucket string, key string) (ObjectHeaders, map[string]string, error) {
+
+	out, err := HeadObject(svc, bucket, key)
+	if err != nil {
+		return ObjectHeaders{}, nil, err
+	}
+
+	return ObjectHeaders{
+		ContentType:        aws.StringValue(out.ContentType),
+		ContentEncoding:    aws.StringValue(out.ContentEncoding),
+		ContentDisposition: aws.StringValue(out.ContentDisposition),
+		ContentLanguage:    aws.StringValue(out.ContentLanguage),
+		CacheControl:       aws.StringValue(out.CacheControl),
+		Expires:            aws.StringValue(out.Expires),
+	}, NormalizeMetadata(out.Metadata), nil
+}
+
+// GetObjectHeaders is HeadObjectHeaders for GET. Non-empty fields of
+// overrides are sent as response-* query parameters.
+func GetObjectHeaders(svc *s3.S3, bucket string, key string, overrides ObjectHeaders) (ObjectHeaders, map[string]string, error) {
+
+	out, err := svc.GetObject(&s3.GetObjectInput{
+		Bucket:                     aws.String(bucket),
+		Key:                        aws.String(key),
+		ResponseContentType:        optionalString(overrides.ContentType),
+		ResponseContentEncoding:    optionalString(overrides.ContentEncoding),
+		ResponseContentDisposition: optionalString(overrides.ContentDisposition),
+		ResponseContentLanguage:    optionalString(overrides.ContentLanguage),
+		ResponseCacheControl:       optionalString(overrides.CacheControl),
+		ResponseExpires:            overrides.expires(),
+	})
+	if err != nil {
+		return ObjectHeaders{}, nil, err
+	}
+	out.Body.Close()
+
+	return ObjectHeaders{
+		ContentType:        aws.StringValue(out.ContentType),
+		ContentEncoding:    aws.StringValue(out.ContentEncoding),
+		ContentDisposition: aws.StringValue(out.ContentDisposition),
+		ContentLanguage:    aws.StringValue(out.ContentLanguage),
+		CacheControl:       aws.StringValue(out.CacheControl),
+		Expires:            aws.StringValue(out.Expires),
+	}, NormalizeMetadata(out.Metadata), nil
+}
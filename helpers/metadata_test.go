@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataSize(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal(0, MetadataSize(nil))
+	assert.Equal(len("name")+len("value")+len("ü")+len("日本"), MetadataSize(map[string]string{"name": "value", "ü": "日本"}))
+}
+
+func TestObjectHeadersRequests(t *testing.T) {
+
+	assert := assert.New(t)
+
+	headers := ObjectHeaders{
+		ContentType:     "text/plain",
+		ContentLanguage: "de",
+		CacheControl:    "no-cache",
+		Expires:         "Thu, 15 Dec 2033 16:00:00 GMT",
+	}
+
+	var sent *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r
+		w.Header().Set("Content-Type", r.URL.Query().Get("response-content-type"))
+		w.Header().Set("Cache-Control", "max-age=1")
+		w.Header().Set("X-Amz-Meta-Name", "=?UTF-8?B?w7w=?=")
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	assert.Nil(PutObjectWithHeaders(client, "bucket", "key", "bar", headers, map[string]string{"name": "value"}))
+	assert.Equal("text/plain", sent.Header.Get("Content-Type"))
+	assert.Equal("de", sent.Header.Get("Content-Language"))
+	assert.Equal("no-cache", sent.Header.Get("Cache-Control"))
+	assert.Equal(headers.Expires, sent.Header.Get("Expires"))
+	assert.Equal("value", sent.Header.Get("X-Amz-Meta-Name"))
+	_, ok := sent.Header["Content-Disposition"]
+	assert.False(ok)
+
+	got, meta, err := GetObjectHeaders(client, "bucket", "key", ObjectHeaders{ContentType: "image/png"})
+	assert.Nil(err)
+	assert.Equal("image/png", sent.URL.Query().Get("response-content-type"))
+	assert.Equal(ObjectHeaders{ContentType: "image/png", CacheControl: "max-age=1"}, got)
+	assert.Equal(map[string]string{"name": "ü"}, meta)
+}
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	// MinBucketNameLength and MaxBucketNameLength bound bucket names.
+	MinBucketNameLength = 3
+	MaxBucketNameLength = 63
+)
+
+// CheckBucketName returns the S3 naming rule name breaks, or nil if it is
+// a valid bucket name.
+func CheckBucketName(name string) error {
+
+	switch {
+	case len(name) < MinBucketNameLength:
+		return fmt.Errorf("shorter than %d characters", MinBucketNameLength)
+	case len(name) > MaxBucketNameLength:
+		return fmt.Errorf("longer than %d characters", MaxBucketNameLength)
+	}
+
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-') {
+			return fmt.Errorf("invalid character %q", c)
+		}
+	}
+
+	switch {
+	case !isAlnum(name[0]):
+		return fmt.Errorf("does not start with a letter or digit")
+	case !isAlnum(name[len(name)-1]):
+		return fmt.Errorf("does not end with a letter or digit")
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("consecutive dots")
+	case strings.Contains(name, ".-") || strings.Contains(name, "-."):
+		return fmt.Errorf("dot next to a hyphen")
+	case net.ParseIP(name) != nil:
+		return fmt.Errorf("formatted as an IP address")
+	case strings.HasPrefix(name, "xn--"):
+		return fmt.Errorf("reserved prefix xn--")
+	case strings.HasSuffix(name, "-s3alias"):
+		return fmt.Errorf("reserved suffix -s3alias")
+	case strings.HasSuffix(name, "--ol-s3"):
+		return fmt.Errorf("reserved suffix --ol-s3")
+	}
+
+	return nil
+}
+
+func isAlnum(c byte) bool {
+
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// BucketNameCase is a bucket name and the naming rule it exercises.
+type BucketNameCase struct {
+	Rule  string
+	Name  string
+	Valid bool
+	// Shared marks valid names short enough that another owner of a shared
+	// endpoint may hold them already; BucketAlreadyExists also passes.
+	Shared bool
+}
+
+// BucketNameCases returns names around each naming rule, built from base
+// so that they are unique to the run. base should be a valid name such as
+// one from GetBucketName.
+func BucketNameCases(base string) []BucketNameCase {
+
+	pad := func(length int) string {
+		return base + "-" + strings.Repeat("a", length-len(base)-1)
+	}
+
+	return []BucketNameCase{
+		{Rule: "minimum length", Name: String(MinBucketNameLength), Valid: true, Shared: true},
+		{Rule: "maximum length", Name: pad(MaxBucketNameLength), Valid: true},
+		{Rule: "dots and hyphens", Name: base + ".a-b.c", Valid: true},
+		{Rule: "digits only", Name: fmt.Sprintf("%012d", seededRand.Int63n(1e12)), Valid: true},
+		{Rule: "too short", Name: String(2)},
+		{Rule: "too long", Name: pad(MaxBucketNameLength + 1)},
+		{Rule: "uppercase", Name: base + "Upper"},
+		{Rule: "underscore", Name: base + "_x"},
+		{Rule: "leading hyphen", Name: "-" + base},
+		{Rule: "trailing hyphen", Name: base + "-"},
+		{Rule: "leading dot", Name: "." + base},
+		{Rule: "trailing dot", Name: base + "."},
+		{Rule: "consecutive dots", Name: base + "..x"},
+		{Rule: "dot next to hyphen", Name: base + ".-x"},
+		{Rule: "IP address", Name: fmt.Sprintf("192.168.%d.%d", seededRand.Intn(256), seededRand.Intn(256))},
+		{Rule: "xn-- prefix", Name: "xn--" + base},
+		{Rule: "-s3alias suffix", Name: base + "-s3alias"},
+		{Rule: "--ol-s3 suffix", Name: base + "--ol-s3"},
+	}
+}
@@ -0,0 +1,52 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBucketName(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for _, name := range []string{"abc", "my.bucket-1", "123", "a1b2c3"} {
+		assert.Nil(CheckBucketName(name), name)
+	}
+
+	for name, rule := range map[string]string{
+		"ab":          "shorter",
+		"a_b":         "invalid character",
+		"Abc":         "invalid character",
+		"-abc":        "start",
+		"abc.":        "end",
+		"a..b":        "consecutive dots",
+		"a-.b":        "hyphen",
+		"10.0.0.1":    "IP address",
+		"xn--abc":     "xn--",
+		"abc-s3alias": "-s3alias",
+		"abc--ol-s3":  "--ol-s3",
+		"a.b c":       "invalid character",
+		"bucéket1":    "invalid character",
+	} {
+		err := CheckBucketName(name)
+		if assert.NotNil(err, name) {
+			assert.Contains(err.Error(), rule, name)
+		}
+	}
+}
+
+func TestBucketNameCases(t *testing.T) {
+
+	assert := assert.New(t)
+	base := GetBucketName()
+
+	for _, c := range BucketNameCases(base) {
+		err := CheckBucketName(c.Name)
+		assert.Equal(c.Valid, err == nil, "%s: %q %v", c.Rule, c.Name, err)
+		assert.Equal(c.Rule == "minimum length", c.Shared, c.Rule)
+		if c.Rule == "digits only" {
+			assert.Regexp("^[0-9]+$", c.Name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// SecureTransportPolicy returns a bucket policy denying actions on bucket
+// and its objects to everyone when aws:SecureTransport is secure.
+func SecureTransportPolicy(bucket string, secure bool, actions ...string) string {
+
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{{
+			"Effect":    "Deny",
+			"Principal": "*",
+			"Action":    actions,
+			"Resource": []string{
+				fmt.Sprintf("arn:aws:s3:::%s", bucket),
+				fmt.Sprintf("arn:aws:s3:::%s/*", bucket),
+			},
+			"Condition": map[string]interface{}{
+				"Bool": map[string]string{"aws:SecureTransport": fmt.Sprint(secure)},
+			},
+		}},
+	}
+	data, _ := json.Marshal(policy)
+
+	return string(data)
+}
+
+// PutBucketPolicy sets the policy document of bucket.
+func PutBucketPolicy(svc *s3.S3, bucket string, policy string) error {
+
+	_, err := svc.PutBucketPolicy(&s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(policy),
+	})
+
+	return err
+}
+
+// DeleteBucketPolicy removes the policy of bucket.
+func DeleteBucketPolicy(svc *s3.S3, bucket string) error {
+
+	_, err := svc.DeleteBucketPolicy(&s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+
+	return err
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureTransportPolicy(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var policy struct {
+		Statement []struct {
+			Effect    string
+			Principal string
+			Action    []string
+			Resource  []string
+			Condition map[string]map[string]string
+		}
+	}
+	assert.Nil(json.Unmarshal([]byte(SecureTransportPolicy("bucket", false, "s3:GetObject")), &policy))
+
+	if assert.Equal(1, len(policy.Statement)) {
+		s := policy.Statement[0]
+		assert.Equal("Deny", s.Effect)
+		assert.Equal("*", s.Principal)
+		assert.Equal([]string{"s3:GetObject"}, s.Action)
+		assert.Equal([]string{"arn:aws:s3:::bucket", "arn:aws:s3:::bucket/*"}, s.Resource)
+		assert.Equal(map[string]map[string]string{"Bool": {"aws:SecureTransport": "false"}}, s.Condition)
+	}
+}
@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// RaceBody returns a body of size bytes made of writer's tag repeated, so a
+// read that mixes the bodies of two writers matches neither of them.
+func RaceBody(writer int, size int) string {
+
+	tag := fmt.Sprintf("writer%04d;", writer)
+	body := strings.Repeat(tag, size/len(tag)+1)
+
+	return body[:size]
+}
+
+// RaceWinner returns the index of the body in bodies that data equals, or
+// -1 when it equals none of them.
+func RaceWinner(data string, bodies []string) int {
+
+	for i, body := range bodies {
+		if data == body {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// RunConcurrently starts n goroutines, releases them at the same moment and
+// calls f with each goroutine's index. It returns the errors by index once
+// all of them have finished.
+func RunConcurrently(n int, f func(i int) error) []error {
+
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = f(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	return errs
+}
+
+// Succeeded returns the indexes of the nil errors in errs.
+func Succeeded(errs []error) []int {
+
+	ok := []int{}
+	for i, err := range errs {
+		if err == nil {
+			ok = append(ok, i)
+		}
+	}
+
+	return ok
+}
+
+// PrepareMultipart creates an upload of bucket/key and uploads payloads as
+// its parts, returning the upload ID and the parts to complete it with.
+func PrepareMultipart(svc *s3.S3, bucket string, key string, payloads []string) (string, []*s3.CompletedPart, error) {
+
+	upload, err := InitiateMultipartUpload(svc, bucket, key)
+	if err != nil {
+		return "", nil, err
+	}
+	uploadID := aws.StringValue(upload.UploadId)
+
+	var parts []*s3.CompletedPart
+	for i, payload := range payloads {
+		number := int64(i + 1)
+		part, err := Uploadpart(svc, bucket, key, uploadID, payload, number)
+		if err != nil {
+			return uploadID, nil, err
+		}
+		parts = append(parts, &s3.CompletedPart{ETag: part.ETag, PartNumber: aws.Int64(number)})
+	}
+
+	return uploadID, parts, nil
+}
+
+// CompleteParts completes the upload uploadID of bucket/key with parts.
+func CompleteParts(svc *s3.S3, bucket string, key string, uploadID string, parts []*s3.CompletedPart) (*s3.CompleteMultipartUploadOutput, error) {
+
+	return svc.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+}
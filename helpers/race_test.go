@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaceBody(t *testing.T) {
+
+	assert := assert.New(t)
+
+	bodies := []string{RaceBody(0, 1000), RaceBody(1, 1000), RaceBody(2, 7)}
+	assert.Equal(1000, len(bodies[0]))
+	assert.Equal("writer0", bodies[2])
+	assert.NotEqual(bodies[0], bodies[1])
+
+	assert.Equal(1, RaceWinner(bodies[1], bodies))
+	assert.Equal(-1, RaceWinner(bodies[0][:500]+bodies[1][500:], bodies))
+	assert.Equal(-1, RaceWinner("", bodies))
+}
+
+func TestRunConcurrently(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var calls int32
+	errs := RunConcurrently(10, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i%2 == 1 {
+			return errors.New("odd")
+		}
+		return nil
+	})
+
+	assert.Equal(int32(10), calls)
+	assert.Equal(10, len(errs))
+	assert.Equal([]int{0, 2, 4, 6, 8}, Succeeded(errs))
+	assert.Equal([]int{}, Succeeded(errs[1:2]))
+}
+
+func TestPrepareMultipart(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var uploaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			fmt.Fprint(w, "<InitiateMultipartUploadResult><UploadId>upload1</UploadId></InitiateMultipartUploadResult>")
+		case r.Method == "PUT":
+			q := r.URL.Query()
+			uploaded = append(uploaded, q.Get("uploadId")+"/"+q.Get("partNumber"))
+			w.Header().Set("ETag", `"etag`+q.Get("partNumber")+`"`)
+		}
+	}))
+	defer server.Close()
+
+	client := recorderClient(strings.TrimPrefix(server.URL, "http://"), &Recorder{Mode: RecordOff})
+
+	uploadID, parts, err := PrepareMultipart(client, "bucket", "key", []string{"a", "b"})
+	assert.Nil(err)
+	assert.Equal("upload1", uploadID)
+	assert.Equal([]string{"upload1/1", "upload1/2"}, uploaded)
+	if assert.Equal(2, len(parts)) {
+		assert.Equal(`"etag2"`, aws.StringValue(parts[1].ETag))
+		assert.Equal(int64(2), aws.Int64Value(parts[1].PartNumber))
+	}
+}
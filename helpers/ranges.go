@@ -0,0 +1,172 @@
+package helpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// RangeResponse is what a ranged or part-number read returned on the wire.
+type RangeResponse struct {
+	Status        int
+	Code          string
+	ContentRange  string
+	ContentLength int64
+	ContentType   string
+	PartsCount    int64
+	Body          string
+}
+
+// RangeRead selects what GetRange and HeadRange ask for. Empty fields are
+// not sent.
+type RangeRead struct {
+	Range      string
+	PartNumber int64
+	Conditions Conditions
+}
+
+// GetRange issues a GET for bucket/key and returns the status and headers of
+// the response. err is nil whenever a status was received, including 304,
+// 412 and 416, whose error code is in Code.
+func GetRange(svc *s3.S3, bucket string, key string, read RangeRead) (*RangeResponse, error) {
+
+	input := &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+	if read.Range != "" {
+		input.Range = aws.String(read.Range)
+	}
+	if read.PartNumber > 0 {
+		input.PartNumber = aws.Int64(read.PartNumber)
+	}
+
+	req, out := svc.GetObjectRequest(input)
+	req.ApplyOptions(AddHeaders(read.Conditions.Headers()))
+	err := req.Send()
+
+	resp := &RangeResponse{}
+	if err == nil {
+		body, err := ioutil.ReadAll(out.Body)
+		out.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = string(body)
+		resp.ContentRange = aws.StringValue(out.ContentRange)
+		resp.ContentLength = aws.Int64Value(out.ContentLength)
+		resp.ContentType = aws.StringValue(out.ContentType)
+		resp.PartsCount = aws.Int64Value(out.PartsCount)
+	}
+
+	return rangeResult(req, resp, err)
+}
+
+// HeadRange is GetRange for HEAD; Body is always empty.
+func HeadRange(svc *s3.S3, bucket string, key string, read RangeRead) (*RangeResponse, error) {
+
+	input := &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+	if read.Range != "" {
+		input.Range = aws.String(read.Range)
+	}
+	if read.PartNumber > 0 {
+		input.PartNumber = aws.Int64(read.PartNumber)
+	}
+
+	req, out := svc.HeadObjectRequest(input)
+	req.ApplyOptions(AddHeaders(read.Conditions.Headers()))
+	err := req.Send()
+
+	resp := &RangeResponse{}
+	if err == nil {
+		resp.ContentLength = aws.Int64Value(out.ContentLength)
+		resp.ContentType = aws.StringValue(out.ContentType)
+		resp.PartsCount = aws.Int64Value(out.PartsCount)
+		resp.ContentRange = req.HTTPResponse.Header.Get("Content-Range")
+	}
+
+	return rangeResult(req, resp, err)
+}
+
+func rangeResult(req *request.Request, resp *RangeResponse, err error) (*RangeResponse, error) {
+
+	if err != nil {
+		reqErr, ok := err.(awserr.RequestFailure)
+		if !ok {
+			return nil, err
+		}
+		resp.Code = reqErr.Code()
+		resp.Status = reqErr.StatusCode()
+	} else {
+		resp.Status = req.HTTPResponse.StatusCode
+	}
+
+	if req.HTTPResponse != nil && resp.ContentRange == "" {
+		resp.ContentRange = req.HTTPResponse.Header.Get("Content-Range")
+	}
+
+	return resp, nil
+}
+
+// ParseByteRange resolves a single "bytes=" range against an object of size
+// bytes following RFC 7233: it returns the first and last byte offsets and
+// whether the range is satisfiable. Multi-range and malformed values are
+// errors.
+func ParseByteRange(value string, size int64) (int64, int64, bool, error) {
+
+	spec := strings.TrimPrefix(value, "bytes=")
+	if spec == value {
+		return 0, 0, false, fmt.Errorf("range %q: not a bytes range", value)
+	}
+	if strings.Contains(spec, ",") {
+		return 0, 0, false, fmt.Errorf("range %q: multiple ranges", value)
+	}
+
+	dash := strings.Index(spec, "-")
+	if dash < 0 {
+		return 0, 0, false, fmt.Errorf("range %q: missing '-'", value)
+	}
+	first, last := spec[:dash], spec[dash+1:]
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, fmt.Errorf("range %q: bad suffix length", value)
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, nil
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, fmt.Errorf("range %q: bad first byte", value)
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, false, fmt.Errorf("range %q: bad last byte", value)
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, nil
+	}
+
+	return start, end, true, nil
+}
+
+// ContentRange formats the Content-Range of a 206 response.
+func ContentRange(start int64, end int64, size int64) string {
+
+	return fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+}
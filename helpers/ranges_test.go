@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteRange(t *testing.T) {
+
+	assert := assert.New(t)
+
+	cases := []struct {
+		value       string
+		size        int64
+		start, end  int64
+		satisfiable bool
+	}{
+		{"bytes=4-7", 11, 4, 7, true},
+		{"bytes=4-", 11, 4, 10, true},
+		{"bytes=4-100", 11, 4, 10, true},
+		{"bytes=10-", 11, 10, 10, true},
+		{"bytes=-8", 11, 3, 10, true},
+		{"bytes=-100", 11, 0, 10, true},
+		{"bytes=11-", 11, 0, 0, false},
+		{"bytes=40-50", 11, 0, 0, false},
+		{"bytes=-0", 11, 0, 0, false},
+		{"bytes=0-", 0, 0, 0, false},
+		{"bytes=-1", 0, 0, 0, false},
+	}
+
+	for _, c := range cases {
+		start, end, ok, err := ParseByteRange(c.value, c.size)
+		assert.Nil(err, c.value)
+		assert.Equal(c.satisfiable, ok, c.value)
+		if ok {
+			assert.Equal(c.start, start, c.value)
+			assert.Equal(c.end, end, c.value)
+		}
+	}
+
+	for _, bad := range []string{"4-7", "bytes=0-1,4-5", "bytes=7-4", "bytes=a-", "bytes=-x", "bytes=4"} {
+		_, _, _, err := ParseByteRange(bad, 11)
+		assert.NotNil(err, bad)
+	}
+
+	assert.Equal("bytes 4-7/11", ContentRange(4, 7, 11))
+}
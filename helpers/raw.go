@@ -0,0 +1,397 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/spf13/viper"
+	"golang.org/x/net/http/httpguts"
+)
+
+// RawHeader is one header line, sent exactly as given.
+type RawHeader struct {
+	Name  string
+	Value string
+}
+
+// RawRequest is an HTTP request written to the connection byte for byte.
+// Host, Content-Length and the SigV4 headers are added unless disabled;
+// every header in Headers is sent as is, in order, duplicates included.
+type RawRequest struct {
+	Method string
+	// Path is the escaped request target without the query, e.g. "/bucket/key".
+	Path  string
+	Query string
+	// Proto defaults to "HTTP/1.1".
+	Proto   string
+	Headers []RawHeader
+	Body    []byte
+
+	// Host replaces the endpoint in the Host header and the signature; the
+	// connection still goes to the endpoint.
+	Host string
+	// NoHost leaves out the Host header. The request is still signed for
+	// the configured endpoint.
+	NoHost bool
+	// NoContentLength leaves out the Content-Length header that is otherwise
+	// added when Headers do not carry one.
+	NoContentLength bool
+	// CloseWrite half-closes the connection after the body.
+	CloseWrite bool
+}
+
+// NewRawRequest builds a path-style request for bucket/key; an empty key
+// addresses the bucket and an empty bucket the service.
+func NewRawRequest(method string, bucket string, key string, body string) *RawRequest {
+
+	path := "/"
+	if bucket != "" {
+		path += bucket
+		if key != "" {
+			path = "/" + EscapeCopySource(bucket, key)
+		}
+	}
+
+	return &RawRequest{Method: method, Path: path, Body: []byte(body)}
+}
+
+// NewURLRawRequest builds a request for u, such as a presigned URL, with
+// the host of u in the Host header. It is still sent to the client's
+// endpoint.
+func NewURLRawRequest(method string, u *url.URL, body string) *RawRequest {
+
+	return &RawRequest{Method: method, Path: u.EscapedPath(), Query: u.RawQuery, Host: u.Host, Body: []byte(body)}
+}
+
+// NewVirtualRawRequest builds a virtual-hosted request for bucket/key,
+// with the bucket in the Host header.
+func NewVirtualRawRequest(method string, bucket string, key string, body string) *RawRequest {
+
+	path := "/"
+	if key != "" {
+		path = EscapeCopySource("", key)
+	}
+
+	return &RawRequest{Method: method, Path: path, Host: VirtualHost(bucket), Body: []byte(body)}
+}
+
+// Header appends a header line and returns r.
+func (r *RawRequest) Header(name string, value string) *RawRequest {
+
+	r.Headers = append(r.Headers, RawHeader{name, value})
+
+	return r
+}
+
+func (r *RawRequest) hasHeader(name string) bool {
+
+	for _, h := range r.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *RawRequest) expectsContinue() bool {
+
+	for _, h := range r.Headers {
+		if strings.EqualFold(h.Name, "Expect") && strings.EqualFold(strings.TrimSpace(h.Value), "100-continue") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// S3Error is the XML error document S3 returns with failed requests.
+type S3Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+	// Region is the region hint of AuthorizationHeaderMalformed and
+	// redirect errors.
+	Region string `xml:"Region"`
+}
+
+func (e *S3Error) Error() string {
+
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// RawResponse is the final response to a RawRequest.
+type RawResponse struct {
+	Status int
+	Proto  string
+	Header http.Header
+	Body   []byte
+	// Continue is set when a 100 Continue arrived before the body was sent.
+	Continue bool
+	// Error is the parsed S3 error document of a failed request, if any.
+	Error *S3Error
+}
+
+// Code returns the S3 error code of the response, or "" if there is none.
+func (r *RawResponse) Code() string {
+
+	if r.Error == nil {
+		return ""
+	}
+
+	return r.Error.Code
+}
+
+// RawClient sends RawRequests to an endpoint, signing them with SigV4.
+type RawClient struct {
+	Endpoint string
+	Secure   bool
+	// TLS configures connections when Secure is set; nil uses the defaults.
+	TLS    *tls.Config
+	Region string
+	// Creds signs requests; nil sends them anonymously.
+	Creds *credentials.Credentials
+	// ContinueTimeout is how long to wait for 100 Continue before sending
+	// the body anyway.
+	ContinueTimeout time.Duration
+	Timeout         time.Duration
+}
+
+// NewRawClient returns a client for the s3main endpoint signing with creds,
+// using the TLS settings of the config.
+func NewRawClient(creds *credentials.Credentials) *RawClient {
+
+	conf, _ := TLSConfig()
+
+	return &RawClient{
+		Endpoint:        viper.GetString("s3main.endpoint"),
+		Secure:          viper.GetBool("s3main.is_secure"),
+		TLS:             conf,
+		Region:          viper.GetString("s3main.region"),
+		Creds:           creds,
+		ContinueTimeout: time.Second,
+		Timeout:         time.Minute,
+	}
+}
+
+// Encode returns the request head as it goes on the wire, signed at now.
+func (c *RawClient) Encode(r *RawRequest, now time.Time) ([]byte, error) {
+
+	proto := r.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	target := r.Path
+	if r.Query != "" {
+		target += "?" + r.Query
+	}
+
+	host := c.Endpoint
+	if r.Host != "" {
+		host = r.Host
+	}
+
+	headers := []RawHeader{}
+	if !r.NoHost {
+		headers = append(headers, RawHeader{"Host", host})
+	}
+	if c.Creds != nil {
+		signed, err := c.sign(r, host, target, now)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, signed...)
+	}
+	headers = append(headers, r.Headers...)
+	if !r.NoContentLength && !r.hasHeader("Content-Length") {
+		headers = append(headers, RawHeader{"Content-Length", fmt.Sprint(len(r.Body))})
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s %s\r\n", r.Method, target, proto)
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.Name, h.Value)
+	}
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// sign computes the SigV4 headers for r. Only Host and the well-formed
+// x-amz-*, Content-Type and Content-MD5 headers of r are signed, so that
+// malformed values still reach the server.
+func (c *RawClient) sign(r *RawRequest, host string, target string, now time.Time) ([]RawHeader, error) {
+
+	scheme := "http"
+	if c.Secure {
+		scheme = "https"
+	}
+	u, err := url.Parse(scheme + "://" + host + target)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{Method: r.Method, URL: u, Host: host, Header: http.Header{}}
+	for _, h := range r.Headers {
+		name := strings.ToLower(h.Name)
+		if (strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "content-md5") &&
+			httpguts.ValidHeaderFieldValue(h.Value) {
+			req.Header.Add(h.Name, h.Value)
+		}
+	}
+
+	signer := v4.NewSigner(c.Creds, func(s *v4.Signer) {
+		s.DisableURIPathEscaping = true
+	})
+	if _, err := signer.Sign(req, bytes.NewReader(r.Body), "s3", c.Region, now); err != nil {
+		return nil, err
+	}
+
+	// A payload hash in r, such as STREAMING-UNSIGNED-PAYLOAD-TRAILER, was
+	// signed as given and is sent from r.Headers.
+	signed := []RawHeader{{"X-Amz-Date", req.Header.Get("X-Amz-Date")}}
+	if !r.hasHeader("X-Amz-Content-Sha256") {
+		signed = append(signed, RawHeader{"X-Amz-Content-Sha256", req.Header.Get("X-Amz-Content-Sha256")})
+	}
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" && !r.hasHeader("X-Amz-Security-Token") {
+		signed = append(signed, RawHeader{"X-Amz-Security-Token", token})
+	}
+
+	return append(signed, RawHeader{"Authorization", req.Header.Get("Authorization")}), nil
+}
+
+// Do sends r on a new connection and reads the final response. With
+// Expect: 100-continue the body is held back until the server answers or
+// ContinueTimeout passes; a final status before that means the body is
+// never sent.
+func (c *RawClient) Do(r *RawRequest) (*RawResponse, error) {
+
+	head, err := c.Encode(r, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	addr := c.Endpoint
+	if c.Secure {
+		if _, _, e := net.SplitHostPort(addr); e != nil {
+			addr += ":443"
+		}
+		host := hostOnly(c.Endpoint)
+		if r.Host != "" {
+			host = hostOnly(r.Host)
+		}
+		conn, err = tls.Dial("tcp", addr, tlsClientConfig(c.TLS, host))
+	} else {
+		if _, _, e := net.SplitHostPort(addr); e != nil {
+			addr += ":80"
+		}
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+
+	if _, err := conn.Write(head); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	method := &http.Request{Method: r.Method}
+	result := &RawResponse{}
+
+	if r.expectsContinue() {
+		conn.SetReadDeadline(time.Now().Add(c.ContinueTimeout))
+		_, peekErr := br.Peek(1)
+		if c.Timeout > 0 {
+			conn.SetDeadline(time.Now().Add(c.Timeout))
+		} else {
+			conn.SetDeadline(time.Time{})
+		}
+
+		if peekErr == nil {
+			resp, err := http.ReadResponse(br, method)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode != http.StatusContinue {
+				return result, readRawResponse(resp, result)
+			}
+			result.Continue = true
+		} else if netErr, ok := peekErr.(net.Error); !ok || !netErr.Timeout() {
+			return nil, peekErr
+		}
+	}
+
+	if _, err := conn.Write(r.Body); err != nil {
+		return nil, err
+	}
+	if r.CloseWrite {
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			tcp.CloseWrite()
+		}
+	}
+
+	for {
+		resp, err := http.ReadResponse(br, method)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusContinue {
+			result.Continue = true
+			continue
+		}
+		return result, readRawResponse(resp, result)
+	}
+}
+
+// hostOnly strips the port, if any, from a host header value.
+func hostOnly(hostport string) string {
+
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+
+	return hostport
+}
+
+func readRawResponse(resp *http.Response, result *RawResponse) error {
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	result.Status = resp.StatusCode
+	result.Proto = resp.Proto
+	result.Header = resp.Header
+	result.Body = body
+
+	if resp.StatusCode >= 300 && len(body) > 0 {
+		s3Err := &S3Error{}
+		if xml.Unmarshal(body, s3Err) == nil {
+			result.Error = s3Err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRawClient(endpoint string) *RawClient {
+
+	return &RawClient{
+		Endpoint:        endpoint,
+		Region:          "us-east-1",
+		Creds:           credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		ContinueTimeout: 200 * time.Millisecond,
+		Timeout:         5 * time.Second,
+	}
+}
+
+// rawServer accepts one connection, hands what the client sent so far to
+// respond and returns everything the client sent before closing.
+func rawServer(t *testing.T, respond func(r *bufio.Reader, w io.Writer)) (string, <-chan string) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan string, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			sent <- ""
+			return
+		}
+		defer conn.Close()
+
+		var buf strings.Builder
+		r := bufio.NewReader(io.TeeReader(conn, &buf))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+		}
+		respond(r, conn)
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		io.Copy(ioutil.Discard, r)
+		sent <- buf.String()
+	}()
+
+	return ln.Addr().String(), sent
+}
+
+func TestRawEncode(t *testing.T) {
+
+	assert := assert.New(t)
+	c := testRawClient("s3.local:8000")
+
+	r := NewRawRequest("PUT", "bucket", "a b/c+d", "bar").
+		Header("Content-Length", "10").
+		Header("Content-Length", "3").
+		Header("X-Amz-Meta-Foo", "x")
+	head, err := c.Encode(r, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Nil(err)
+
+	lines := strings.Split(string(head), "\r\n")
+	assert.Equal("PUT /bucket/a%20b/c%2Bd HTTP/1.1", lines[0])
+	assert.Equal("Host: s3.local:8000", lines[1])
+	assert.Equal("X-Amz-Date: 20200102T030405Z", lines[2])
+	assert.True(strings.HasPrefix(lines[4], "Authorization: AWS4-HMAC-SHA256 Credential=AKID/20200102/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-meta-foo,"))
+	assert.Equal([]string{"Content-Length: 10", "Content-Length: 3", "X-Amz-Meta-Foo: x", "", ""}, lines[5:])
+
+	r = &RawRequest{Method: "GET", Path: "/", Proto: "HTTP/1.0", NoHost: true, NoContentLength: true}
+	c.Creds = nil
+	head, err = c.Encode(r, time.Now())
+	assert.Nil(err)
+	assert.Equal("GET / HTTP/1.0\r\n\r\n", string(head))
+}
+
+func TestRawDoParsesError(t *testing.T) {
+
+	assert := assert.New(t)
+	endpoint, sent := rawServer(t, func(r *bufio.Reader, w io.Writer) {
+		body := "<Error><Code>MissingContentLength</Code><Message>no</Message><RequestId>1</RequestId></Error>"
+		fmt.Fprintf(w, "HTTP/1.1 400 Bad Request\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+	})
+
+	resp, err := testRawClient(endpoint).Do(NewRawRequest("PUT", "bucket", "", "").Header("Content-Length", " "))
+	assert.Nil(err)
+	assert.Equal(400, resp.Status)
+	assert.Equal("MissingContentLength", resp.Code())
+	assert.Equal("no", resp.Error.Message)
+	assert.True(strings.Contains(<-sent, "\r\nContent-Length:  \r\n"))
+}
+
+func TestRawDoExpectContinue(t *testing.T) {
+
+	assert := assert.New(t)
+	endpoint, sent := rawServer(t, func(r *bufio.Reader, w io.Writer) {
+		io.WriteString(w, "HTTP/1.1 100 Continue\r\n\r\n")
+		body := make([]byte, 3)
+		io.ReadFull(r, body)
+		io.WriteString(w, "HTTP/1.1 200 OK\r\nContent-Length: 3\r\n\r\n"+string(body))
+	})
+
+	resp, err := testRawClient(endpoint).Do(NewRawRequest("PUT", "bucket", "key", "bar").Header("Expect", "100-continue"))
+	assert.Nil(err)
+	assert.True(resp.Continue)
+	assert.Equal(200, resp.Status)
+	assert.Equal("bar", string(resp.Body))
+	assert.True(strings.HasSuffix(<-sent, "\r\n\r\nbar"))
+}
+
+func TestRawDoExpectRejected(t *testing.T) {
+
+	assert := assert.New(t)
+	endpoint, sent := rawServer(t, func(r *bufio.Reader, w io.Writer) {
+		io.WriteString(w, "HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n")
+	})
+
+	resp, err := testRawClient(endpoint).Do(NewRawRequest("PUT", "bucket", "key", "bar").Header("Expect", "100-continue"))
+	assert.Nil(err)
+	assert.False(resp.Continue)
+	assert.Equal(403, resp.Status)
+	assert.Nil(resp.Error)
+	assert.True(strings.HasSuffix(<-sent, "\r\n\r\n"))
+}
+
+func TestHostOnly(t *testing.T) {
+
+	assert := assert.New(t)
+
+	assert.Equal("s3.test", hostOnly("s3.test"))
+	assert.Equal("s3.test", hostOnly("s3.test:8443"))
+	assert.Equal("::1", hostOnly("[::1]:443"))
+}
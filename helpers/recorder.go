@@ -0,0 +1,420 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/corehandlers"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/spf13/viper"
+)
+
+// RecorderMode selects what a Recorder does with the traffic of a test.
+type RecorderMode string
+
+const (
+	// RecordOff leaves the clients alone.
+	RecordOff RecorderMode = "off"
+	// RecordFailures keeps every exchange and writes the cassette only if
+	// the test failed.
+	RecordFailures RecorderMode = "failures"
+	// RecordAlways writes a cassette for every test.
+	RecordAlways RecorderMode = "always"
+	// Replay answers requests from the test's cassette without touching the
+	// network.
+	Replay RecorderMode = "replay"
+)
+
+const redacted = "REDACTED"
+
+// CassetteBody is a captured body, base64 encoded when it is not UTF-8.
+type CassetteBody struct {
+	Data      string `json:"data,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func newCassetteBody(b []byte, truncated bool) CassetteBody {
+
+	if utf8.Valid(b) {
+		return CassetteBody{Data: string(b), Truncated: truncated}
+	}
+
+	return CassetteBody{Data: base64.StdEncoding.EncodeToString(b), Encoding: "base64", Truncated: truncated}
+}
+
+// Bytes returns the decoded body.
+func (b CassetteBody) Bytes() []byte {
+
+	if b.Encoding == "base64" {
+		data, _ := base64.StdEncoding.DecodeString(b.Data)
+		return data
+	}
+
+	return []byte(b.Data)
+}
+
+// Interaction is one request and the response or transport error it got.
+type Interaction struct {
+	Method         string       `json:"method"`
+	URL            string       `json:"url"`
+	RequestHeader  http.Header  `json:"request_header"`
+	RequestBody    CassetteBody `json:"request_body"`
+	Status         int          `json:"status,omitempty"`
+	ResponseHeader http.Header  `json:"response_header,omitempty"`
+	ResponseBody   CassetteBody `json:"response_body"`
+	Error          string       `json:"error,omitempty"`
+
+	body *captureBody
+}
+
+// Cassette is the recorded SDK traffic of one test. Seed reseeds the name
+// generators on replay so that the test asks for the same buckets and keys;
+// RunSeed is the run seed the fixture bucket names were derived from.
+type Cassette struct {
+	Test         string         `json:"test"`
+	Seed         int64          `json:"seed"`
+	RunSeed      int64          `json:"run_seed,omitempty"`
+	Interactions []*Interaction `json:"interactions"`
+
+	// queues holds the interactions not yet replayed, by interactionKey, in
+	// recorded order. Requests of concurrent goroutines interleave
+	// differently on every run, so replay only keeps the order per key.
+	queues map[string][]*Interaction
+}
+
+// interactionKey identifies the requests a recorded interaction may answer:
+// same method, path and query, with secrets redacted as in the cassette.
+func interactionKey(method string, u *url.URL) string {
+
+	return method + " " + u.EscapedPath() + "?" + u.Query().Encode()
+}
+
+// Recorder captures the requests SDK clients send, per test, into cassette
+// files under Dir, or replays them from there. Secrets are redacted before
+// anything is kept.
+type Recorder struct {
+	Mode RecorderMode
+	Dir  string
+	// MaxBody caps how many bytes of each request and response body are kept.
+	MaxBody int64
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorderForConfig builds a recorder from the recorder section of the
+// config: mode (off, failures, always or replay), dir and max_body.
+func NewRecorderForConfig() *Recorder {
+
+	loadedConfig()
+	r := &Recorder{
+		Mode:    RecorderMode(fmt.Sprint(viperDefault("recorder.mode", string(RecordOff)))),
+		Dir:     fmt.Sprint(viperDefault("recorder.dir", filepath.Join("testdata", "cassettes"))),
+		MaxBody: 1 << 20,
+	}
+	if max := viper.GetString("recorder.max_body"); max != "" {
+		if n, err := ParseSize(max); err == nil {
+			r.MaxBody = n
+		}
+	}
+
+	return r
+}
+
+// Cassettes records the traffic of the s3main and s3alt clients.
+var Cassettes = NewRecorderForConfig()
+
+func init() {
+
+	Cassettes.Install(&svc.Handlers)
+	Cassettes.Install(&altSvc.Handlers)
+}
+
+// Install routes the send step of handlers through the recorder.
+func (r *Recorder) Install(handlers *request.Handlers) {
+
+	handlers.Send.Swap(corehandlers.SendHandler.Name, request.NamedHandler{
+		Name: corehandlers.SendHandler.Name,
+		Fn:   r.send,
+	})
+}
+
+// CassettePath returns the file the cassette of test is stored in.
+func (r *Recorder) CassettePath(test string) string {
+
+	name := regexp.MustCompile(`[^A-Za-z0-9_.-]+`).ReplaceAllString(test, "_")
+
+	return filepath.Join(r.Dir, name+".json")
+}
+
+// Start begins recording test, or loads its cassette in replay mode.
+func (r *Recorder) Start(test string) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette = nil
+	switch r.Mode {
+	case RecordFailures, RecordAlways:
+		seed := seededRand.Int63()
+		Seed(seed)
+		r.cassette = &Cassette{Test: test, Seed: seed, RunSeed: RunSeed()}
+	case Replay:
+		data, err := ioutil.ReadFile(r.CassettePath(test))
+		if err != nil {
+			return err
+		}
+		cassette := &Cassette{}
+		if err := json.Unmarshal(data, cassette); err != nil {
+			return fmt.Errorf("cassette %s: %v", r.CassettePath(test), err)
+		}
+		if cassette.RunSeed != 0 && cassette.RunSeed != RunSeed() {
+			return fmt.Errorf("cassette %s was recorded with fixtures.seed %d: set it to replay", r.CassettePath(test), cassette.RunSeed)
+		}
+		Seed(cassette.Seed)
+		r.cassette = cassette
+	}
+
+	return nil
+}
+
+// Stop ends the current test and writes its cassette if the mode asks for
+// it. It returns the path written, or "" if nothing was.
+func (r *Recorder) Stop(failed bool) (string, error) {
+
+	r.mu.Lock()
+	cassette := r.cassette
+	r.cassette = nil
+	r.mu.Unlock()
+
+	if cassette == nil || r.Mode == Replay || (r.Mode == RecordFailures && !failed) {
+		return "", nil
+	}
+
+	for _, in := range cassette.Interactions {
+		if in.body != nil {
+			in.ResponseBody = in.body.cassetteBody()
+		}
+	}
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return "", err
+	}
+	path := r.CassettePath(cassette.Test)
+
+	return path, ioutil.WriteFile(path, data, 0644)
+}
+
+func (r *Recorder) current() *Cassette {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette
+}
+
+func (r *Recorder) send(req *request.Request) {
+
+	cassette := r.current()
+	if cassette == nil {
+		corehandlers.SendHandler.Fn(req)
+		return
+	}
+
+	if r.Mode == Replay {
+		r.replay(cassette, req)
+		return
+	}
+
+	in := &Interaction{
+		Method:        req.HTTPRequest.Method,
+		URL:           redactURL(req.HTTPRequest.URL),
+		RequestHeader: redactHeader(req.HTTPRequest.Header),
+		RequestBody:   r.peekBody(req.Body),
+	}
+
+	corehandlers.SendHandler.Fn(req)
+
+	if req.HTTPResponse != nil {
+		in.Status = req.HTTPResponse.StatusCode
+		in.ResponseHeader = redactHeader(req.HTTPResponse.Header)
+		in.body = &captureBody{ReadCloser: req.HTTPResponse.Body, limit: r.MaxBody}
+		req.HTTPResponse.Body = in.body
+	}
+	if req.Error != nil {
+		in.Error = req.Error.Error()
+	}
+
+	r.mu.Lock()
+	cassette.Interactions = append(cassette.Interactions, in)
+	r.mu.Unlock()
+}
+
+func (r *Recorder) replay(cassette *Cassette, req *request.Request) {
+
+	method, path := req.HTTPRequest.Method, req.HTTPRequest.URL.Path
+	live, _ := url.Parse(redactURL(req.HTTPRequest.URL))
+	key := interactionKey(method, live)
+
+	r.mu.Lock()
+	if cassette.queues == nil {
+		cassette.queues = map[string][]*Interaction{}
+		for _, in := range cassette.Interactions {
+			if recorded, err := url.Parse(in.URL); err == nil {
+				k := interactionKey(in.Method, recorded)
+				cassette.queues[k] = append(cassette.queues[k], in)
+			}
+		}
+	}
+	var in *Interaction
+	if queue := cassette.queues[key]; len(queue) > 0 {
+		in = queue[0]
+		cassette.queues[key] = queue[1:]
+	}
+	r.mu.Unlock()
+
+	if in == nil {
+		req.Error = awserr.New(request.ErrCodeRequestError,
+			fmt.Sprintf("replay: no recorded interaction left for %s %s", method, path), nil)
+		return
+	}
+
+	if in.Status == 0 {
+		req.Error = awserr.New(request.ErrCodeRequestError, "replay: "+in.Error, nil)
+		return
+	}
+
+	body := in.ResponseBody.Bytes()
+	req.HTTPResponse = &http.Response{
+		StatusCode:    in.Status,
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		Header:        in.ResponseHeader,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if req.HTTPResponse.Header == nil {
+		req.HTTPResponse.Header = http.Header{}
+	}
+}
+
+// peekBody reads up to MaxBody bytes of body and rewinds it.
+func (r *Recorder) peekBody(body io.ReadSeeker) CassetteBody {
+
+	if body == nil || !aws.IsReaderSeekable(body) {
+		return CassetteBody{}
+	}
+
+	start, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return CassetteBody{}
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(body, r.MaxBody+1))
+	body.Seek(start, io.SeekStart)
+
+	truncated := int64(len(data)) > r.MaxBody
+	if truncated {
+		data = data[:r.MaxBody]
+	}
+
+	return newCassetteBody(data, truncated)
+}
+
+// captureBody keeps the first limit bytes the SDK reads from a response.
+type captureBody struct {
+	io.ReadCloser
+	limit     int64
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (c *captureBody) Read(p []byte) (int, error) {
+
+	n, err := c.ReadCloser.Read(p)
+
+	c.mu.Lock()
+	if room := c.limit - int64(c.buf.Len()); room > 0 {
+		if int64(n) > room {
+			c.buf.Write(p[:room])
+			c.truncated = true
+		} else {
+			c.buf.Write(p[:n])
+		}
+	} else if n > 0 {
+		c.truncated = true
+	}
+	c.mu.Unlock()
+
+	return n, err
+}
+
+func (c *captureBody) cassetteBody() CassetteBody {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return newCassetteBody(c.buf.Bytes(), c.truncated)
+}
+
+var signatureRE = regexp.MustCompile(`Signature=[0-9a-fA-F]+`)
+
+// redactHeader copies h with signatures and session tokens removed.
+func redactHeader(h http.Header) http.Header {
+
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		values = append([]string(nil), values...)
+		switch strings.ToLower(name) {
+		case "authorization":
+			for i, v := range values {
+				values[i] = signatureRE.ReplaceAllString(v, "Signature="+redacted)
+			}
+		case "x-amz-security-token":
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+		out[name] = values
+	}
+
+	return out
+}
+
+// redactURL renders u with presigned signatures and tokens removed.
+func redactURL(u *url.URL) string {
+
+	copied := *u
+	query := copied.Query()
+	changed := false
+	for name := range query {
+		switch strings.ToLower(name) {
+		case "x-amz-signature", "x-amz-security-token", "signature":
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		copied.RawQuery = query.Encode()
+	}
+
+	return copied.String()
+}
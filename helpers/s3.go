@@ -2,18 +2,19 @@ package helpers
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 
 	"bytes"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,46 +22,73 @@ import (
 	"golang.org/x/net/context"
 )
 
-func LoadConfig() error {
+var err error
 
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("../")
-	viper.SetConfigName("config")
+var Creds = credsFor("s3main")
 
-	err := viper.ReadInConfig()
-	if err != nil {
-		fmt.Println(err)
-		fmt.Println("Config file not found...")
-	}
+// credsFor returns the static credentials of a user section of the config.
+func credsFor(section string) *credentials.Credentials {
 
-	return err
+	loadedConfig()
+
+	return credentials.NewStaticCredentials(viper.GetString(section+".access_key"), viper.GetString(section+".access_secret"), viper.GetString(section+".session_token"))
 }
 
-var err = LoadConfig()
+func newConfig(creds *credentials.Credentials) *aws.Config {
 
-const (
-	localCertFile = "/home/mu3e/Software/huangnauh-master/build/cert.pem"
-)
+	loadedConfig()
 
-var Creds = credentials.NewStaticCredentials(viper.GetString("s3main.access_key"), viper.GetString("s3main.access_secret"), "")
+	return aws.NewConfig().WithRegion(viper.GetString("s3main.region")).
+		WithEndpoint(viper.GetString("s3main.endpoint")).
+		WithDisableSSL(!viper.GetBool("s3main.is_secure")).
+		WithS3ForcePathStyle(true).
+		WithHTTPClient(&http.Client{Transport: newTransport(nil)}).
+		WithCredentials(creds)
+}
+
+// logLevel is the SDK log level of the shared clients, s3main.log_level in
+// the config. It defaults to off; use the recorder to see the exchanges of
+// a failing test.
+func logLevel() aws.LogLevelType {
+
+	return aws.LogLevelType(viper.GetInt("s3main.log_level"))
+}
 
-var cfg = aws.NewConfig().WithRegion(viper.GetString("s3main.region")).
-	WithEndpoint(viper.GetString("s3main.endpoint")).
-	WithDisableSSL(!viper.GetBool("s3main.is_secure")).
-	WithLogLevel(3).
-	WithS3ForcePathStyle(true).
-	WithCredentials(Creds)
+var cfg = newStyledConfig(Creds, AddressingStyles()[0]).WithLogLevel(logLevel())
 
 var sess = session.Must(session.NewSession())
 var svc = s3.New(sess, cfg)
-var uploader = s3manager.NewUploader(sess)
-var downloader = s3manager.NewDownloader(sess)
+
+// AltCreds are the credentials of the s3alt user, a second account on the
+// same endpoint used for cross-owner tests.
+var AltCreds = credsFor("s3alt")
+
+var altSvc = s3.New(sess, newStyledConfig(AltCreds, AddressingStyles()[0]).WithLogLevel(logLevel()))
 
 func GetConn() *s3.S3 {
 
 	return svc
 }
 
+// GetAltConn returns a client authenticated as the s3alt user.
+func GetAltConn() *s3.S3 {
+
+	return altSvc
+}
+
+// HasAltUser reports whether an s3alt user is configured.
+func HasAltUser() bool {
+
+	return viper.GetString("s3alt.access_key") != ""
+}
+
+// NewConn builds a quiet client for the s3main section of the config as it
+// is loaded now, for callers that load their own config file.
+func NewConn() *s3.S3 {
+
+	return s3.New(sess, newStyledConfig(credsFor("s3main"), AddressingStyles()[0]))
+}
+
 func WithIfNoneMatch(conditions ...string) request.Option {
 	return func(r *request.Request) {
 		for _, v := range conditions {
@@ -98,6 +126,21 @@ func CreateBucket(svc *s3.S3, bucket string) error {
 	return err
 }
 
+// CreateFixtureBucket creates bucket holding exactly objects, emptying it
+// first when an earlier run left it behind.
+func CreateFixtureBucket(svc *s3.S3, bucket string, objects map[string]string) error {
+
+	err := CreateBucket(svc, bucket)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "BucketAlreadyOwnedByYou" {
+		err = DeleteObjects(svc, bucket)
+	}
+	if err != nil {
+		return err
+	}
+
+	return CreateObjects(svc, bucket, objects)
+}
+
 func PutObjectToBucket(svc *s3.S3, bucket string, key string, content string) error {
 
 	_, err := svc.PutObject(&s3.PutObjectInput{
@@ -119,10 +162,12 @@ func CreateObjects(svc *s3.S3, bucket string, objects map[string]string) error {
 			Key:    &key,
 		})
 
-		err = err
+		if err != nil {
+			return err
+		}
 	}
 
-	return err
+	return nil
 }
 
 func DeleteBucket(svc *s3.S3, bucket string) error {
@@ -285,29 +330,63 @@ func GetObjectWithRange(svc *s3.S3, bucket string, key string, range_value strin
 func DeleteObject(svc *s3.S3, bucket string, key string) error {
 
 	_, err := svc.DeleteObject(&s3.DeleteObjectInput{
-		Bucket: aws.String("Bucketname"),
-		Key:    aws.String("ObjectKey"),
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
 	})
 
 	return err
 }
 
+// DeleteObjects empties bucket: every version and delete marker, or every
+// object where the gateway cannot list versions, in batches of
+// MaxDeleteKeys.
 func DeleteObjects(svc *s3.S3, bucket string) error {
 
-	resp, err := svc.ListObjects(&s3.ListObjectsInput{Bucket: aws.String(bucket)})
-
-	num_objs := len(resp.Contents)
-	var items s3.Delete
-	var objs = make([]*s3.ObjectIdentifier, num_objs)
-
-	for i, o := range resp.Contents {
-		objs[i] = &s3.ObjectIdentifier{Key: aws.String(*o.Key)}
+	var ids []*s3.ObjectIdentifier
+	err := svc.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: aws.String(bucket)},
+		func(page *s3.ListObjectVersionsOutput, last bool) bool {
+			for _, v := range page.Versions {
+				ids = append(ids, &s3.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+			}
+			for _, m := range page.DeleteMarkers {
+				ids = append(ids, &s3.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+			}
+			return true
+		})
+	if err != nil {
+		ids = nil
+		err = svc.ListObjectsPages(&s3.ListObjectsInput{Bucket: aws.String(bucket)},
+			func(page *s3.ListObjectsOutput, last bool) bool {
+				for _, o := range page.Contents {
+					ids = append(ids, &s3.ObjectIdentifier{Key: o.Key})
+				}
+				return true
+			})
+		if err != nil {
+			return err
+		}
 	}
 
-	items.SetObjects(objs)
-	_, err = svc.DeleteObjects(&s3.DeleteObjectsInput{Bucket: &bucket, Delete: &items})
+	var failed []string
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > MaxDeleteKeys {
+			n = MaxDeleteKeys
+		}
+		out, err := DeleteObjectIDs(svc, bucket, ids[:n], true)
+		if err != nil {
+			return err
+		}
+		for _, e := range out.Errors {
+			failed = append(failed, fmt.Sprintf("%s (%s)", aws.StringValue(e.Key), aws.StringValue(e.Code)))
+		}
+		ids = ids[n:]
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete %d objects from %q: %s", len(failed), bucket, strings.Join(failed, ", "))
+	}
 
-	return err
+	return nil
 }
 
 func GetKeys(svc *s3.S3, bucket string) (*s3.ListObjectsOutput, []string, error) {
@@ -393,6 +472,32 @@ func GeneratePresignedUrlGetObject(svc *s3.S3, bucket string, key string) (strin
 	return urlStr, err
 }
 
+// DeleteBuckets empties and deletes each of buckets, skipping those that
+// do not exist. It returns the buckets it failed to delete, such as those
+// svc has no access to, with the errors.
+func DeleteBuckets(svc *s3.S3, buckets ...string) ([]string, []error) {
+
+	var failed []string
+	var errs []error
+	for _, bucket := range buckets {
+		err := DeleteObjects(svc, bucket)
+		if err == nil {
+			err = DeleteBucket(svc, bucket)
+		}
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchBucket {
+			continue
+		}
+		if err != nil {
+			failed = append(failed, bucket)
+			errs = append(errs, fmt.Errorf("bucket %q: %v", bucket, err))
+		}
+	}
+
+	return failed, errs
+}
+
+// DeletePrefixedBuckets deletes every bucket with the fixtures prefix and
+// its objects.
 func DeletePrefixedBuckets(svc *s3.S3) {
 
 	buckets, err := svc.ListBuckets(&s3.ListBucketsInput{})
@@ -540,21 +645,6 @@ func WriteSSEKMSkeyId(svc *s3.S3, bucket string, key string, content string, sse
 	return err
 }
 
-func GetSetMetadata(metadata map[string]*string) map[string]*string {
-
-	bucket := GetBucketName()
-	objects := map[string]string{"key1": "echo"}
-	key := objects["key1"]
-
-	_ = CreateBucket(svc, bucket)
-	_ = CreateObjects(svc, bucket, objects)
-
-	resp, _ := svc.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
-	resp.SetMetadata(metadata)
-
-	return resp.Metadata
-}
-
 func GetObjectWithIfMatch(svc *s3.S3, bucket string, key string, condition string) (string, error) {
 
 	results, err := svc.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key), IfMatch: aws.String(condition)})
@@ -665,12 +755,7 @@ func GetObj(svc *s3.S3, bucket string, key string) (*s3.GetObjectOutput, error)
 
 func PutObjectWithIfMatch(svc *s3.S3, bucket string, key string, content string, tag string) error {
 
-	data, err := GetObject(svc, bucket, key)
-
-	if data != "" {
-
-		fmt.Sprintf("some data, %v", data)
-	}
+	_, err := GetObject(svc, bucket, key)
 
 	if err == nil {
 
@@ -789,6 +874,17 @@ func Listparts(svc *s3.S3, bucket string, key string, uploadid string) (*s3.List
 	return result, err
 }
 
+func ListMultipartUploads(svc *s3.S3, bucket string) (*s3.ListMultipartUploadsOutput, error) {
+
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket),
+	}
+
+	result, err := svc.ListMultipartUploads(input)
+
+	return result, err
+}
+
 func Uploadpart(svc *s3.S3, bucket string, key string, uploadid string, content string, partNum int64) (*s3.UploadPartOutput, error) {
 
 	input := &s3.UploadPartInput{
@@ -907,7 +1003,7 @@ func SetupRequest(serviceName, region, body string) (*http.Request, io.ReadSeeke
 	req, _ := http.NewRequest("POST", endpoint, reader)
 	req.Header.Add("X-Amz-Target", "prefix.Operation")
 	req.Header.Add("Content-Type", "application/x-amz-json-1.0")
-	req.Header.Add("Content-Length", string(len(body)))
+	req.Header.Add("Content-Length", strconv.Itoa(len(body)))
 	req.Header.Add("X-Amz-Meta-Other-Header", "some-value=!@#$%^&* (+)")
 	req.Header.Add("X-Amz-Meta-Other-Header_With_Underscore", "some-value=!@#$%^&* (+)")
 	req.Header.Add("X-amz-Meta-Other-Header_With_Underscore", "some-value=!@#$%^&* (+)")
@@ -922,7 +1018,7 @@ func SetupRawRequest(proto, method, url, body string) (*http.Request, io.ReadSee
 	req, _ := http.NewRequest(method, endpoint, reader)
 	req.Header.Add("X-Amz-Target", "prefix.Operation")
 	req.Header.Add("Content-Type", "application/x-amz-json-1.0")
-	req.Header.Add("Content-Length", string(len(body)))
+	req.Header.Add("Content-Length", strconv.Itoa(len(body)))
 	req.Header.Add("X-Amz-Meta-Other-Header", "some-value=!@#$%^&* (+)")
 	req.Header.Add("X-Amz-Meta-Other-Header_With_Underscore", "some-value=!@#$%^&* (+)")
 	req.Header.Add("X-amz-Meta-Other-Header_With_Underscore", "some-value=!@#$%^&* (+)")
@@ -0,0 +1,368 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/spf13/viper"
+)
+
+const patternBlockSize = 64 * 1024
+
+// SizeTiers maps a tier name to the object sizes it streams. Each tier is a
+// superset of the one before it, so "large" also covers "small".
+var SizeTiers = map[string][]string{
+	"small":  {"0", "1", "5MiB", "5MiB+1", "17MiB"},
+	"medium": {"0", "1", "5MiB", "5MiB+1", "17MiB", "128MiB", "513MiB"},
+	"large":  {"0", "1", "5MiB", "5MiB+1", "17MiB", "128MiB", "513MiB", "2GiB", "5GiB+1"},
+}
+
+// StreamConfig describes the large object matrix the streaming tests run.
+type StreamConfig struct {
+	Sizes       []int64
+	PartSizes   []int64
+	Concurrency []int
+	Seed        int64
+}
+
+// ParseSize parses a byte count such as "1024", "5MiB" or "5MiB+1".
+func ParseSize(s string) (int64, error) {
+
+	var total int64
+
+	for _, term := range strings.Split(s, "+") {
+		term = strings.TrimSpace(term)
+		mult := int64(1)
+
+		for _, unit := range []struct {
+			suffix string
+			mult   int64
+		}{{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40}, {"B", 1}} {
+			if strings.HasSuffix(term, unit.suffix) {
+				term, mult = strings.TrimSuffix(term, unit.suffix), unit.mult
+				break
+			}
+		}
+
+		n, err := strconv.ParseInt(strings.TrimSpace(term), 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid size %q", s)
+		}
+		total += n * mult
+	}
+
+	return total, nil
+}
+
+func parseSizes(values []string) ([]int64, error) {
+
+	sizes := make([]int64, 0, len(values))
+	for _, v := range values {
+		n, err := ParseSize(v)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, n)
+	}
+
+	return sizes, nil
+}
+
+// GetStreamConfig reads the large_objects section of the config. Sizes come
+// from large_objects.sizes if set, otherwise from large_objects.tier
+// (default "small").
+func GetStreamConfig() (StreamConfig, error) {
+
+	conf := StreamConfig{Seed: viper.GetInt64("large_objects.seed")}
+
+	sizes := viper.GetStringSlice("large_objects.sizes")
+	if len(sizes) == 0 {
+		tier := viper.GetString("large_objects.tier")
+		if tier == "" {
+			tier = "small"
+		}
+		var ok bool
+		if sizes, ok = SizeTiers[tier]; !ok {
+			return conf, fmt.Errorf("unknown large_objects.tier %q", tier)
+		}
+	}
+
+	partSizes := viper.GetStringSlice("large_objects.part_sizes")
+	if len(partSizes) == 0 {
+		partSizes = []string{"5MiB", "8MiB"}
+	}
+
+	conf.Concurrency = viper.GetIntSlice("large_objects.concurrency")
+	if len(conf.Concurrency) == 0 {
+		conf.Concurrency = []int{1, 4}
+	}
+
+	var err error
+	if conf.Sizes, err = parseSizes(sizes); err != nil {
+		return conf, err
+	}
+	if conf.PartSizes, err = parseSizes(partSizes); err != nil {
+		return conf, err
+	}
+
+	for _, p := range conf.PartSizes {
+		if p < s3manager.MinUploadPartSize {
+			return conf, fmt.Errorf("part size %d is below the %d byte minimum", p, s3manager.MinUploadPartSize)
+		}
+	}
+
+	return conf, nil
+}
+
+func fillPatternBlock(seed int64, block int64, buf []byte) {
+
+	// splitmix64 keyed on (seed, block), so any block can be produced on its own.
+	state := uint64(seed) ^ uint64(block)*0x9e3779b97f4a7c15
+	var word [8]byte
+
+	for i := 0; i < len(buf); i += 8 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z ^= z >> 31
+		binary.LittleEndian.PutUint64(word[:], z)
+		copy(buf[i:], word[:])
+	}
+}
+
+// PatternReader streams size pseudo-random bytes derived from seed. Any
+// offset can be produced without generating what precedes it, so it
+// implements io.ReaderAt and io.Seeker without holding the object in memory.
+type PatternReader struct {
+	seed   int64
+	size   int64
+	offset int64
+	block  int64
+	buf    []byte
+}
+
+// NewPatternReader returns a reader over the size bytes generated from seed.
+func NewPatternReader(seed int64, size int64) *PatternReader {
+
+	return &PatternReader{seed: seed, size: size, block: -1}
+}
+
+// Size returns the total length of the pattern.
+func (r *PatternReader) Size() int64 {
+
+	return r.size
+}
+
+func (r *PatternReader) Read(p []byte) (int, error) {
+
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.buf == nil {
+		r.buf = make([]byte, patternBlockSize)
+	}
+
+	n := 0
+	for n < len(p) && r.offset < r.size {
+		block := r.offset / patternBlockSize
+		if block != r.block {
+			fillPatternBlock(r.seed, block, r.buf)
+			r.block = block
+		}
+		start := r.offset % patternBlockSize
+		end := int64(patternBlockSize)
+		if left := r.size - block*patternBlockSize; left < end {
+			end = left
+		}
+		c := copy(p[n:], r.buf[start:end])
+		n += c
+		r.offset += int64(c)
+	}
+
+	return n, nil
+}
+
+// ReadAt is safe for concurrent use; it does not touch the Read offset.
+func (r *PatternReader) ReadAt(p []byte, off int64) (int, error) {
+
+	if off < 0 {
+		return 0, errors.New("pattern: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	buf := make([]byte, patternBlockSize)
+	n := 0
+	for n < len(p) && off < r.size {
+		block := off / patternBlockSize
+		fillPatternBlock(r.seed, block, buf)
+		start := off % patternBlockSize
+		end := int64(patternBlockSize)
+		if left := r.size - block*patternBlockSize; left < end {
+			end = left
+		}
+		c := copy(p[n:], buf[start:end])
+		n += c
+		off += int64(c)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (r *PatternReader) Seek(offset int64, whence int) (int64, error) {
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("pattern: invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("pattern: negative position")
+	}
+	r.offset = offset
+
+	return offset, nil
+}
+
+// PatternHash returns the hex SHA-256 of the size bytes generated from seed.
+func PatternHash(seed int64, size int64) string {
+
+	h := sha256.New()
+	io.Copy(h, NewPatternReader(seed, size))
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// VerifyingWriterAt checks every write against the pattern at the same offset
+// instead of storing it, and records how many bytes were verified.
+type VerifyingWriterAt struct {
+	pattern *PatternReader
+
+	mu       sync.Mutex
+	written  int64
+	mismatch error
+}
+
+// NewVerifyingWriterAt returns a writer that expects the size bytes generated
+// from seed.
+func NewVerifyingWriterAt(seed int64, size int64) *VerifyingWriterAt {
+
+	return &VerifyingWriterAt{pattern: NewPatternReader(seed, size)}
+}
+
+func (w *VerifyingWriterAt) WriteAt(p []byte, off int64) (int, error) {
+
+	want := make([]byte, len(p))
+	n, _ := w.pattern.ReadAt(want, off)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n != len(p) {
+		w.mismatch = fmt.Errorf("write of %d bytes at offset %d runs past the expected %d bytes", len(p), off, w.pattern.size)
+		return 0, w.mismatch
+	}
+
+	for i := range p {
+		if p[i] != want[i] {
+			w.mismatch = fmt.Errorf("content differs at offset %d", off+int64(i))
+			return 0, w.mismatch
+		}
+	}
+	w.written += int64(len(p))
+
+	return len(p), nil
+}
+
+// Verify returns an error unless every expected byte was written and matched.
+func (w *VerifyingWriterAt) Verify() error {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.mismatch != nil {
+		return w.mismatch
+	}
+	if w.written != w.pattern.size {
+		return fmt.Errorf("verified %d bytes, want %d", w.written, w.pattern.size)
+	}
+
+	return nil
+}
+
+// UploadPattern streams the size bytes generated from seed to bucket/key with
+// the managed uploader, using the given part size and concurrency.
+func UploadPattern(svc *s3.S3, bucket string, key string, seed int64, size int64, partSize int64, concurrency int) (*s3manager.UploadOutput, error) {
+
+	uploader := s3manager.NewUploaderWithClient(svc, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = concurrency
+	})
+
+	return uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   NewPatternReader(seed, size),
+	})
+}
+
+// DownloadPattern fetches bucket/key with parallel ranged GETs and checks it
+// against the size bytes generated from seed.
+func DownloadPattern(svc *s3.S3, bucket string, key string, seed int64, size int64, partSize int64, concurrency int) error {
+
+	downloader := s3manager.NewDownloaderWithClient(svc, func(d *s3manager.Downloader) {
+		d.PartSize = partSize
+		d.Concurrency = concurrency
+	})
+
+	w := NewVerifyingWriterAt(seed, size)
+	n, err := downloader.Download(w, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("downloaded %d bytes, want %d", n, size)
+	}
+
+	return w.Verify()
+}
+
+// GetObjectHash streams bucket/key through SHA-256 with a single GET and
+// returns the hex digest and the number of bytes read.
+func GetObjectHash(svc *s3.S3, bucket string, key string) (string, int64, error) {
+
+	results, err := svc.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return "", 0, err
+	}
+	defer results.Body.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, results.Body)
+
+	return fmt.Sprintf("%x", h.Sum(nil)), n, err
+}
@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for in, want := range map[string]int64{
+		"0":      0,
+		"1024":   1024,
+		"12B":    12,
+		"4KiB":   4096,
+		"5MiB":   5 << 20,
+		"5MiB+1": 5<<20 + 1,
+		"2GiB":   2 << 30,
+	} {
+		got, err := ParseSize(in)
+		assert.Nil(err, in)
+		assert.Equal(want, got, in)
+	}
+
+	for _, in := range []string{"", "MiB", "-1", "5MB", "1+"} {
+		_, err := ParseSize(in)
+		assert.NotNil(err, in)
+	}
+}
+
+func TestSizeTiersParse(t *testing.T) {
+
+	assert := assert.New(t)
+
+	for tier, sizes := range SizeTiers {
+		_, err := parseSizes(sizes)
+		assert.Nil(err, tier)
+	}
+}
+
+func TestPatternReaderDeterministic(t *testing.T) {
+
+	assert := assert.New(t)
+	size := int64(3*patternBlockSize + 17)
+
+	a, err := ioutil.ReadAll(NewPatternReader(7, size))
+	assert.Nil(err)
+	b, err := ioutil.ReadAll(NewPatternReader(7, size))
+	assert.Nil(err)
+	c, err := ioutil.ReadAll(NewPatternReader(8, size))
+	assert.Nil(err)
+
+	assert.Equal(int(size), len(a))
+	assert.Equal(a, b)
+	assert.NotEqual(a, c)
+	assert.Equal(fmt.Sprintf("%x", sha256.Sum256(a)), PatternHash(7, size))
+}
+
+func TestPatternReaderReadAtSeek(t *testing.T) {
+
+	assert := assert.New(t)
+	size := int64(2*patternBlockSize + 5)
+	r := NewPatternReader(3, size)
+
+	all, err := ioutil.ReadAll(NewPatternReader(3, size))
+	assert.Nil(err)
+
+	p := make([]byte, 100)
+	n, err := r.ReadAt(p, patternBlockSize-50)
+	assert.Nil(err)
+	assert.Equal(100, n)
+	assert.Equal(all[patternBlockSize-50:patternBlockSize+50], p)
+
+	n, err = r.ReadAt(p, size-10)
+	assert.Equal(io.EOF, err)
+	assert.Equal(10, n)
+
+	pos, err := r.Seek(-20, io.SeekEnd)
+	assert.Nil(err)
+	assert.Equal(size-20, pos)
+	tail, err := ioutil.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal(all[size-20:], tail)
+}
+
+func TestVerifyingWriterAt(t *testing.T) {
+
+	assert := assert.New(t)
+	size := int64(patternBlockSize + 10)
+	all, _ := ioutil.ReadAll(NewPatternReader(1, size))
+
+	w := NewVerifyingWriterAt(1, size)
+	_, err := w.WriteAt(all[100:], 100)
+	assert.Nil(err)
+	assert.NotNil(w.Verify())
+	_, err = w.WriteAt(all[:100], 0)
+	assert.Nil(err)
+	assert.Nil(w.Verify())
+
+	bad := append([]byte(nil), all[:10]...)
+	bad[3] ^= 0xff
+	w = NewVerifyingWriterAt(1, size)
+	_, err = w.WriteAt(bad, 0)
+	assert.NotNil(err)
+	assert.NotNil(w.Verify())
+}
@@ -0,0 +1,215 @@
+package helpers
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/spf13/viper"
+)
+
+// TemporaryCredentials are session credentials as STS hands them out.
+type TemporaryCredentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Expiration   time.Time
+}
+
+// Credentials returns c for signing requests.
+func (c TemporaryCredentials) Credentials() *credentials.Credentials {
+
+	return credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, c.SessionToken)
+}
+
+// ErrNoTemporaryCredentials means the sts section gives no way to obtain
+// session credentials.
+var ErrNoTemporaryCredentials = errors.New("no temporary credentials: set sts.endpoint, or sts.access_key, sts.access_secret and sts.session_token for the local stand-in")
+
+// AssumeRole obtains session credentials for roleARN from the STS endpoint,
+// signing the call with creds.
+func AssumeRole(creds *credentials.Credentials, endpoint string, roleARN string, sessionName string, duration time.Duration) (TemporaryCredentials, error) {
+
+	client := sts.New(sess, aws.NewConfig().
+		WithRegion(viper.GetString("s3main.region")).
+		WithEndpoint(endpoint).
+		WithDisableSSL(!viper.GetBool("s3main.is_secure")).
+		WithHTTPClient(&http.Client{Transport: newTransport(nil)}).
+		WithCredentials(creds))
+
+	out, err := client.AssumeRole(&sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleARN),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int64(int64(duration / time.Second)),
+	})
+	if err != nil {
+		return TemporaryCredentials{}, err
+	}
+
+	return TemporaryCredentials{
+		AccessKey:    aws.StringValue(out.Credentials.AccessKeyId),
+		SecretKey:    aws.StringValue(out.Credentials.SecretAccessKey),
+		SessionToken: aws.StringValue(out.Credentials.SessionToken),
+		Expiration:   aws.TimeValue(out.Credentials.Expiration),
+	}, nil
+}
+
+// GetTemporaryCredentials obtains session credentials for the s3main user
+// through AssumeRole: from sts.endpoint when the gateway has one, otherwise
+// from a local stand-in handing out sts.access_key, sts.access_secret and
+// sts.session_token.
+func GetTemporaryCredentials() (TemporaryCredentials, error) {
+
+	duration, err := time.ParseDuration(fmt.Sprint(viperDefault("sts.duration", "15m")))
+	if err != nil {
+		return TemporaryCredentials{}, fmt.Errorf("sts.duration: %v", err)
+	}
+	roleARN := fmt.Sprint(viperDefault("sts.role_arn", "arn:aws:iam::123456789012:role/s3tests"))
+	sessionName := fmt.Sprint(viperDefault("sts.session_name", "s3tests"))
+
+	if endpoint := viper.GetString("sts.endpoint"); endpoint != "" {
+		return AssumeRole(Creds, endpoint, roleARN, sessionName, duration)
+	}
+
+	issued := TemporaryCredentials{
+		AccessKey:    viper.GetString("sts.access_key"),
+		SecretKey:    viper.GetString("sts.access_secret"),
+		SessionToken: viper.GetString("sts.session_token"),
+		Expiration:   time.Now().Add(duration).UTC().Truncate(time.Second),
+	}
+	if issued.AccessKey == "" || issued.SecretKey == "" || issued.SessionToken == "" {
+		return TemporaryCredentials{}, ErrNoTemporaryCredentials
+	}
+
+	standIn, err := StartSTSStandIn(issued)
+	if err != nil {
+		return TemporaryCredentials{}, err
+	}
+	defer standIn.Close()
+
+	return AssumeRole(Creds, standIn.Endpoint(), roleARN, sessionName, duration)
+}
+
+// ExpiredCredentials returns the session credentials of sts.expired_*,
+// which the gateway must reject as expired, and whether they are set.
+func ExpiredCredentials() (TemporaryCredentials, bool) {
+
+	c := TemporaryCredentials{
+		AccessKey:    viper.GetString("sts.expired_access_key"),
+		SecretKey:    viper.GetString("sts.expired_access_secret"),
+		SessionToken: viper.GetString("sts.expired_session_token"),
+	}
+
+	return c, c.AccessKey != "" && c.SecretKey != "" && c.SessionToken != ""
+}
+
+// STSStandIn is a local AssumeRole endpoint handing out fixed credentials,
+// for gateways that accept session credentials but have no STS of their
+// own.
+type STSStandIn struct {
+	issued   TemporaryCredentials
+	listener net.Listener
+	server   *http.Server
+
+	mu       sync.Mutex
+	sessions []string
+}
+
+// StartSTSStandIn starts a stand-in on a loopback port answering every
+// AssumeRole with issued.
+func StartSTSStandIn(issued TemporaryCredentials) (*STSStandIn, error) {
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &STSStandIn{issued: issued, listener: l}
+	s.server = &http.Server{Handler: s}
+	go s.server.Serve(l)
+
+	return s, nil
+}
+
+// Endpoint returns the URL to send STS calls to.
+func (s *STSStandIn) Endpoint() string {
+
+	return "http://" + s.listener.Addr().String()
+}
+
+// Sessions returns the RoleSessionName of every AssumeRole answered.
+func (s *STSStandIn) Sessions() []string {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.sessions...)
+}
+
+// Close stops the stand-in.
+func (s *STSStandIn) Close() error {
+
+	return s.server.Close()
+}
+
+type stsCredentialsXML struct {
+	AccessKeyID     string `xml:"AccessKeyId"`
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      string
+}
+
+type assumeRoleResponseXML struct {
+	XMLName     xml.Name          `xml:"https://sts.amazonaws.com/doc/2011-06-15/ AssumeRoleResponse"`
+	Credentials stsCredentialsXML `xml:"AssumeRoleResult>Credentials"`
+	RoleID      string            `xml:"AssumeRoleResult>AssumedRoleUser>AssumedRoleId"`
+	ARN         string            `xml:"AssumeRoleResult>AssumedRoleUser>Arn"`
+	RequestID   string            `xml:"ResponseMetadata>RequestId"`
+}
+
+type stsErrorXML struct {
+	XMLName xml.Name `xml:"ErrorResponse"`
+	Type    string   `xml:"Error>Type"`
+	Code    string   `xml:"Error>Code"`
+	Message string   `xml:"Error>Message"`
+}
+
+func (s *STSStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "AssumeRole" {
+		w.WriteHeader(http.StatusBadRequest)
+		xml.NewEncoder(w).Encode(stsErrorXML{Type: "Sender", Code: "InvalidAction", Message: "the stand-in only answers AssumeRole"})
+		return
+	}
+	if !strings.Contains(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		w.WriteHeader(http.StatusForbidden)
+		xml.NewEncoder(w).Encode(stsErrorXML{Type: "Sender", Code: "MissingAuthenticationToken", Message: "request is not signed"})
+		return
+	}
+
+	session := r.Form.Get("RoleSessionName")
+	s.mu.Lock()
+	s.sessions = append(s.sessions, session)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/xml")
+	xml.NewEncoder(w).Encode(assumeRoleResponseXML{
+		Credentials: stsCredentialsXML{
+			AccessKeyID:     s.issued.AccessKey,
+			SecretAccessKey: s.issued.SecretKey,
+			SessionToken:    s.issued.SessionToken,
+			Expiration:      s.issued.Expiration.UTC().Format(time.RFC3339),
+		},
+		RoleID:    "AROASTANDIN:" + session,
+		ARN:       r.Form.Get("RoleArn") + "/" + session,
+		RequestID: fmt.Sprint(time.Now().UnixNano()),
+	})
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSTSStandInAssumeRole(t *testing.T) {
+
+	assert := assert.New(t)
+	issued := TemporaryCredentials{AccessKey: "ASIATEMP", SecretKey: "TEMPSECRET", SessionToken: "TOKEN", Expiration: time.Now().Add(time.Hour).UTC().Truncate(time.Second)}
+
+	standIn, err := StartSTSStandIn(issued)
+	if !assert.Nil(err) {
+		return
+	}
+	defer standIn.Close()
+
+	got, err := AssumeRole(Creds, standIn.Endpoint(), "arn:aws:iam::123456789012:role/r", "session-1", 15*time.Minute)
+	assert.Nil(err)
+	assert.Equal(issued.AccessKey, got.AccessKey)
+	assert.Equal(issued.SecretKey, got.SecretKey)
+	assert.Equal(issued.SessionToken, got.SessionToken)
+	assert.True(issued.Expiration.Equal(got.Expiration), "%v != %v", issued.Expiration, got.Expiration)
+	assert.Equal([]string{"session-1"}, standIn.Sessions())
+
+	resp, err := http.Get(standIn.Endpoint() + "/?Action=GetCallerIdentity")
+	assert.Nil(err)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(standIn.Endpoint() + "/?Action=AssumeRole")
+	assert.Nil(err)
+	assert.Equal(http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestGetTemporaryCredentials(t *testing.T) {
+
+	assert := assert.New(t)
+
+	withConfig(t, map[string]string{"sts.endpoint": "", "sts.access_key": "", "sts.access_secret": "", "sts.session_token": ""})
+	_, err := GetTemporaryCredentials()
+	assert.Equal(ErrNoTemporaryCredentials, err)
+
+	withConfig(t, map[string]string{"sts.access_key": "ASIATEMP", "sts.access_secret": "TEMPSECRET", "sts.session_token": "TOKEN", "sts.duration": "1h"})
+	got, err := GetTemporaryCredentials()
+	assert.Nil(err)
+	assert.Equal("TOKEN", got.SessionToken)
+	assert.WithinDuration(time.Now().Add(time.Hour), got.Expiration, time.Minute)
+
+	value, err := got.Credentials().Get()
+	assert.Nil(err)
+	assert.Equal("ASIATEMP", value.AccessKeyID)
+	assert.Equal("TOKEN", value.SessionToken)
+
+	withConfig(t, map[string]string{"sts.duration": "soon"})
+	_, err = GetTemporaryCredentials()
+	assert.Contains(err.Error(), "sts.duration")
+}
+
+func TestSessionTokenSigned(t *testing.T) {
+
+	assert := assert.New(t)
+
+	var token, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, auth = r.Header.Get("X-Amz-Security-Token"), r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	creds := TemporaryCredentials{AccessKey: "ASIATEMP", SecretKey: "TEMPSECRET", SessionToken: "TOKEN"}
+	c := NewRawClient(creds.Credentials())
+	c.Endpoint = strings.TrimPrefix(server.URL, "http://")
+	c.Secure = false
+
+	_, err := c.Do(NewRawRequest("GET", "bucket", "key", ""))
+	assert.Nil(err)
+	assert.Equal("TOKEN", token)
+	assert.Contains(auth, "Credential=ASIATEMP/")
+	assert.Contains(auth, "x-amz-security-token")
+}
+
+func TestSTSCredentialsSetTogether(t *testing.T) {
+
+	assert := assert.New(t)
+
+	withConfig(t, map[string]string{"sts.access_key": "ASIATEMP", "sts.access_secret": "", "sts.session_token": "", "sts.expired_access_key": "", "sts.expired_access_secret": "", "sts.expired_session_token": ""})
+	assert.Contains(validateConfig(), "sts.access_key, sts.access_secret, sts.session_token must be set together")
+
+	withConfig(t, map[string]string{"sts.access_key": "", "sts.expired_session_token": "TOKEN"})
+	assert.Contains(validateConfig(), "sts.expired_access_key, sts.expired_access_secret, sts.expired_session_token must be set together")
+	_, ok := ExpiredCredentials()
+	assert.False(ok)
+}
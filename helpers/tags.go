@@ -0,0 +1,476 @@
+package helpers
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/viper"
+)
+
+// TestInfo is the metadata of a suite test, taken from the comment block
+// opening its body:
+//
+//	Resource : object, method: put, get
+//	Resource : bucket, method: get acl
+//	Scenario : ...
+//	Assertion: ...
+//	Tags     : slow, smoke
+//
+// Each Resource line adds its methods on each of its resources.
+type TestInfo struct {
+	Name string
+	Ops  []Operation
+	Tags []string
+}
+
+// Operation is an S3 operation a test covers, as a resource and a method
+// from KnownOperations.
+type Operation struct {
+	Resource string
+	Method   string
+}
+
+func (op Operation) String() string {
+
+	return op.Resource + " " + op.Method
+}
+
+// Operations returns the S3 operations the test covers, such as
+// "object put".
+func (info TestInfo) Operations() []string {
+
+	ops := []string{}
+	for _, op := range info.Ops {
+		ops = append(ops, op.String())
+	}
+
+	return ops
+}
+
+// HasTag reports whether the test carries tag.
+func (info TestInfo) HasTag(tag string) bool {
+
+	return Contains(info.Tags, tag)
+}
+
+// KnownOperations is the vocabulary of test metadata: the methods each
+// resource may name. Every S3 operation has exactly one spelling, so that
+// selections and the report group a test with the others covering the
+// same operation.
+var KnownOperations = map[string][]string{
+	"service": {"list buckets"},
+	"bucket": {"create", "delete", "head", "get location", "get acl", "put acl", "put policy",
+		"get versioning", "put versioning", "get lifecycle", "put lifecycle"},
+	"object": {"put", "get", "head", "delete", "delete objects", "copy", "list", "get acl", "put acl",
+		"multipart upload", "upload part", "complete multipart upload", "abort multipart upload"},
+	// signature covers the request signer alone, without a gateway.
+	"signature": {"sign", "presign"},
+}
+
+// checkOperations returns an error naming the operations of info that are
+// not in KnownOperations.
+func checkOperations(info TestInfo) error {
+
+	unknown := []string{}
+	for _, op := range info.Ops {
+		if !Contains(KnownOperations[op.Resource], op.Method) {
+			unknown = append(unknown, fmt.Sprintf("%q", op.String()))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown operation %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// ParseTestInfo reads the metadata of every Test method declared in the
+// _test.go files of dir, keyed by method name. It fails on any operation
+// outside KnownOperations.
+func ParseTestInfo(dir string) (map[string]TestInfo, error) {
+
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := map[string]TestInfo{}
+	problems := []string{}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+				continue
+			}
+			info := TestInfo{Name: fn.Name.Name}
+			for _, group := range f.Comments {
+				if group.Pos() > fn.Body.Lbrace && group.End() < fn.Body.Rbrace && parseTestComment(group.Text(), &info) {
+					break
+				}
+			}
+			if err := checkOperations(info); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: %v", fset.Position(fn.Pos()), info.Name, err))
+			}
+			infos[info.Name] = info
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid test metadata:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+
+	return infos, nil
+}
+
+// parseTestComment fills info from a comment block, reporting whether the
+// block had a Resource line.
+func parseTestComment(text string, info *TestInfo) bool {
+
+	found := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		value := line[colon+1:]
+
+		switch strings.ToLower(strings.TrimSpace(line[:colon])) {
+		case "resource":
+			found = true
+			resources, methods := value, ""
+			if i := strings.Index(strings.ToLower(value), "method"); i >= 0 {
+				resources = value[:i]
+				if j := strings.Index(value[i:], ":"); j >= 0 {
+					methods = value[i+j+1:]
+				}
+			}
+			for _, resource := range splitTestTerms(resources, ",") {
+				for _, method := range splitTestTerms(methods, ",/") {
+					info.Ops = append(info.Ops, Operation{resource, method})
+				}
+			}
+		case "tags":
+			info.Tags = splitTestTerms(value, ",")
+		}
+	}
+
+	return found
+}
+
+// splitTestTerms splits s at any of seps into lower-case terms with single
+// spaces.
+func splitTestTerms(s string, seps string) []string {
+
+	terms := []string{}
+	for _, term := range strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+		if term = strings.ToLower(strings.Join(strings.Fields(term), " ")); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// Selection picks tests by their metadata. Empty fields select everything.
+type Selection struct {
+	// Resources and Methods keep the tests covering an operation on any of
+	// the resources with any of the methods.
+	Resources []string
+	Methods   []string
+	// Tags keeps the tests carrying any of them; ExcludeTags drops the tests
+	// carrying any of them.
+	Tags        []string
+	ExcludeTags []string
+}
+
+// Profiles are the named selections of -s3tests.profile:
+//
+//	smoke  a few core tests, to check a gateway is set up at all
+//	quick  everything but the tests tagged slow
+//	full   everything
+var Profiles = map[string]Selection{
+	"smoke": {Tags: []string{"smoke"}},
+	"quick": {ExcludeTags: []string{"slow"}},
+	"full":  {},
+}
+
+// The selection flags. Each defaults to the select key of the same name in
+// the config, so that $S3TESTS_SELECT_* work too.
+var (
+	resourceFlag   = flag.String("s3tests.resource", "", "run only the tests of these comma-separated resources, such as object,bucket")
+	methodFlag     = flag.String("s3tests.method", "", "run only the tests of these comma-separated methods, such as get,put acl")
+	tagFlag        = flag.String("s3tests.tag", "", "run only the tests carrying one of these comma-separated tags")
+	excludeTagFlag = flag.String("s3tests.exclude-tag", "", "skip the tests carrying one of these comma-separated tags")
+	profileFlag    = flag.String("s3tests.profile", "", "run a named selection: smoke, quick or full")
+	listFlag       = flag.Bool("s3tests.list", false, "list the selected tests with their operations instead of running them")
+	reportFlag     = flag.String("s3tests.report", "", "write results grouped by S3 operation to this file, - for stdout")
+)
+
+// selectValue returns the flag value, or the config key when the flag is
+// not set.
+func selectValue(value string, key string) string {
+
+	if value != "" {
+		return value
+	}
+
+	return viper.GetString(key)
+}
+
+// GetSelection returns the selection of the flags and the select section of
+// the config: the profile, narrowed by the other settings.
+func GetSelection() (Selection, error) {
+
+	var sel Selection
+	if name := selectValue(*profileFlag, "select.profile"); name != "" {
+		profile, ok := Profiles[name]
+		if !ok {
+			return sel, fmt.Errorf("unknown profile %q: use one of %s", name, strings.Join(profileNames(), ", "))
+		}
+		sel = profile
+	}
+
+	sel.Resources = append(sel.Resources, splitTestTerms(selectValue(*resourceFlag, "select.resource"), ",")...)
+	sel.Methods = append(sel.Methods, splitTestTerms(selectValue(*methodFlag, "select.method"), ",")...)
+	sel.Tags = append(sel.Tags, splitTestTerms(selectValue(*tagFlag, "select.tag"), ",")...)
+	sel.ExcludeTags = append(sel.ExcludeTags, splitTestTerms(selectValue(*excludeTagFlag, "select.exclude_tag"), ",")...)
+
+	resources, methods := knownTerms()
+	for _, resource := range sel.Resources {
+		if !Contains(resources, resource) {
+			return sel, fmt.Errorf("unknown resource %q: use one of %s", resource, strings.Join(resources, ", "))
+		}
+	}
+	for _, method := range sel.Methods {
+		if !Contains(methods, method) {
+			return sel, fmt.Errorf("unknown method %q: use one of %s", method, strings.Join(methods, ", "))
+		}
+	}
+
+	return sel, nil
+}
+
+// knownTerms returns the sorted resources and methods of KnownOperations.
+func knownTerms() ([]string, []string) {
+
+	resources := []string{}
+	methods := []string{}
+	for resource, ms := range KnownOperations {
+		resources = append(resources, resource)
+		for _, method := range ms {
+			if !Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+	sort.Strings(resources)
+	sort.Strings(methods)
+
+	return resources, methods
+}
+
+func profileNames() []string {
+
+	names := []string{}
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ListTests reports whether -s3tests.list asks for the selected tests
+// instead of a run.
+func ListTests() bool {
+
+	return *listFlag
+}
+
+// ReportPath returns where to write the results, if anywhere.
+func ReportPath() string {
+
+	return selectValue(*reportFlag, "select.report")
+}
+
+// Empty reports whether s selects every test.
+func (s Selection) Empty() bool {
+
+	return len(s.Resources) == 0 && len(s.Methods) == 0 && len(s.Tags) == 0 && len(s.ExcludeTags) == 0
+}
+
+// Match reports whether s selects the test described by info: one of its
+// operations must be on one of the resources and use one of the methods.
+func (s Selection) Match(info TestInfo) bool {
+
+	if len(s.Resources) > 0 || len(s.Methods) > 0 {
+		matched := false
+		for _, op := range info.Ops {
+			if (len(s.Resources) == 0 || Contains(s.Resources, op.Resource)) &&
+				(len(s.Methods) == 0 || Contains(s.Methods, op.Method)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(s.Tags) > 0 && !overlaps(s.Tags, info.Tags) {
+		return false
+	}
+
+	return !overlaps(s.ExcludeTags, info.Tags)
+}
+
+// Select returns the sorted names of the tests in infos that s selects.
+func (s Selection) Select(infos map[string]TestInfo) []string {
+
+	names := []string{}
+	for name, info := range infos {
+		if s.Match(info) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// MethodPattern returns a regular expression matching exactly names, for
+// the -testify.m flag.
+func MethodPattern(names []string) string {
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+func overlaps(a []string, b []string) bool {
+
+	for _, v := range a {
+		if Contains(b, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Test outcomes, as recorded by TestResults.
+const (
+	TestPassed  = "pass"
+	TestFailed  = "fail"
+	TestSkipped = "skip"
+)
+
+// TestResults collects test outcomes for a report grouped by S3 operation.
+type TestResults struct {
+	mu       sync.Mutex
+	infos    map[string]TestInfo
+	outcomes map[string]map[string]string
+}
+
+// NewTestResults returns an empty collection for the tests in infos.
+func NewTestResults(infos map[string]TestInfo) *TestResults {
+
+	return &TestResults{infos: infos, outcomes: map[string]map[string]string{}}
+}
+
+// Record notes the outcome of the test run as name; the metadata is looked
+// up by the last element of name.
+func (r *TestResults) Record(name string, failed bool, skipped bool) {
+
+	outcome := TestPassed
+	if failed {
+		outcome = TestFailed
+	} else if skipped {
+		outcome = TestSkipped
+	}
+
+	info, ok := r.infos[name[strings.LastIndex(name, "/")+1:]]
+	ops := info.Operations()
+	if !ok || len(ops) == 0 {
+		ops = []string{"untagged"}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, op := range ops {
+		if r.outcomes[op] == nil {
+			r.outcomes[op] = map[string]string{}
+		}
+		r.outcomes[op][name] = outcome
+	}
+}
+
+// Report writes one line per operation with its test counts and failed
+// tests.
+func (r *TestResults) Report(w io.Writer) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ops := []string{}
+	for op := range r.outcomes {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "operation\ttests\tpass\tfail\tskip\tfailed")
+
+	for _, op := range ops {
+		counts := map[string]int{}
+		failed := []string{}
+		for name, outcome := range r.outcomes[op] {
+			counts[outcome]++
+			if outcome == TestFailed {
+				failed = append(failed, name)
+			}
+		}
+		sort.Strings(failed)
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", op, len(r.outcomes[op]),
+			counts[TestPassed], counts[TestFailed], counts[TestSkipped], strings.Join(failed, " "))
+	}
+
+	tw.Flush()
+}
+
+// WriteReport writes the report to path, or to stdout when path is "-".
+func (r *TestResults) WriteReport(path string) error {
+
+	if path == "-" {
+		r.Report(os.Stdout)
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r.Report(f)
+
+	return f.Close()
+}
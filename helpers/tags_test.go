@@ -0,0 +1,183 @@
+package helpers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTestInfo(t *testing.T) {
+
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	source := `package s3test
+
+func (suite *ObjectSuite) TestTagged() {
+
+	/*
+		Resource : object, method: put/get, Put ACL
+		Resource : bucket, method: create
+		Scenario : tagged.
+		Assertion: parsed.
+		Tags     : Slow, smoke
+	*/
+
+	// Resource : service, method: ignored
+}
+
+func (suite *ObjectSuite) TestUntagged() {
+
+	// no block
+}
+
+func helper() {
+
+	/*
+		Resource : object, method: put
+	*/
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "a_test.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := ParseTestInfo(dir)
+	assert.Nil(err)
+	assert.Equal(2, len(infos))
+
+	info := infos["TestTagged"]
+	assert.Equal([]string{"slow", "smoke"}, info.Tags)
+	assert.True(info.HasTag("slow"))
+	assert.Equal([]string{"object put", "object get", "object put acl", "bucket create"}, info.Operations())
+
+	assert.Equal(TestInfo{Name: "TestUntagged"}, infos["TestUntagged"])
+}
+
+func TestParseTestInfoUnknownOperation(t *testing.T) {
+
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	source := `package s3test
+
+func (suite *BucketSuite) TestCreate() {
+
+	/*
+		Resource : bucket, object, method: put
+	*/
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "a_test.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ParseTestInfo(dir)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), `a_test.go:3:1: TestCreate: unknown operation "bucket put"`)
+		assert.NotContains(err.Error(), "object put")
+	}
+}
+
+// TestSuiteTestsTagged keeps every suite test selectable by resource and
+// method, with operations from KnownOperations.
+func TestSuiteTestsTagged(t *testing.T) {
+
+	assert := assert.New(t)
+
+	infos, err := ParseTestInfo(filepath.Join("..", "s3test"))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.NotEqual(0, len(infos))
+
+	for name, info := range infos {
+		assert.NotEqual(0, len(info.Ops), "%s has no operation", name)
+	}
+}
+
+func TestSelection(t *testing.T) {
+
+	assert := assert.New(t)
+	infos := map[string]TestInfo{
+		"TestGet":  {Ops: []Operation{{"object", "get"}}, Tags: []string{"smoke"}},
+		"TestPut":  {Ops: []Operation{{"object", "put"}}},
+		"TestRace": {Ops: []Operation{{"object", "put"}}, Tags: []string{"slow"}},
+		"TestAcl":  {Ops: []Operation{{"bucket", "put acl"}, {"object", "get"}}},
+		"TestBare": {},
+	}
+
+	for _, c := range []struct {
+		sel  Selection
+		want []string
+	}{
+		{Selection{}, []string{"TestAcl", "TestBare", "TestGet", "TestPut", "TestRace"}},
+		{Selection{Resources: []string{"object"}, Methods: []string{"get"}}, []string{"TestAcl", "TestGet"}},
+		{Selection{Methods: []string{"put"}, ExcludeTags: []string{"slow"}}, []string{"TestPut"}},
+		// The resource and the method must belong to the same operation.
+		{Selection{Resources: []string{"bucket"}, Methods: []string{"get"}}, []string{}},
+		{Profiles["smoke"], []string{"TestGet"}},
+		{Profiles["quick"], []string{"TestAcl", "TestBare", "TestGet", "TestPut"}},
+	} {
+		assert.Equal(c.want, c.sel.Select(infos), "%+v", c.sel)
+	}
+
+	assert.True(Selection{}.Empty())
+	assert.True(Profiles["full"].Empty())
+
+	pattern := regexp.MustCompile(MethodPattern([]string{"TestGet", "TestPut"}))
+	assert.True(pattern.MatchString("TestPut"))
+	assert.False(pattern.MatchString("TestPutAcl"))
+	assert.False(regexp.MustCompile(MethodPattern(nil)).MatchString("TestGet"))
+}
+
+func TestGetSelection(t *testing.T) {
+
+	assert := assert.New(t)
+
+	withConfig(t, map[string]string{"select.profile": "quick", "select.resource": "Object, bucket", "select.exclude_tag": "fault"})
+	sel, err := GetSelection()
+	assert.Nil(err)
+	assert.Equal(Selection{Resources: []string{"object", "bucket"}, ExcludeTags: []string{"slow", "fault"}}, sel)
+	assert.Equal([]string{"slow"}, Profiles["quick"].ExcludeTags)
+
+	withConfig(t, map[string]string{"select.profile": "nightly"})
+	_, err = GetSelection()
+	assert.Contains(err.Error(), "use one of full, quick, smoke")
+
+	withConfig(t, map[string]string{"select.profile": "", "select.resource": "", "select.method": "list objects"})
+	_, err = GetSelection()
+	assert.Contains(err.Error(), `unknown method "list objects"`)
+}
+
+func TestTestResultsReport(t *testing.T) {
+
+	assert := assert.New(t)
+	results := NewTestResults(map[string]TestInfo{
+		"TestGet":   {Ops: []Operation{{"object", "get"}}},
+		"TestCopy":  {Ops: []Operation{{"object", "get"}, {"object", "put"}}},
+		"TestOther": {},
+	})
+
+	results.Record("TestSuite/TestGet", false, false)
+	results.Record("TestSuite/TestCopy", true, false)
+	results.Record("TestSuite/TestOther", false, true)
+
+	var buf bytes.Buffer
+	results.Report(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i := range lines {
+		lines[i] = strings.Join(strings.Fields(lines[i]), " ")
+	}
+	assert.Equal([]string{
+		"operation tests pass fail skip failed",
+		"object get 2 1 1 0 TestSuite/TestCopy",
+		"object put 1 0 1 0 TestSuite/TestCopy",
+		"untagged 1 0 0 1",
+	}, lines)
+}
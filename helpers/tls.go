@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// TLSConfig builds the client TLS settings from the tls section of the
+// config:
+//
+//	ca_file               PEM bundle trusted on top of the system roots
+//	cert_file, key_file   client certificate and key, for mutual TLS
+//	server_name           name sent as SNI and verified, instead of the host dialed
+//	insecure_skip_verify  accept any server certificate
+//
+// Config validation reports its errors before any test runs.
+func TLSConfig() (*tls.Config, error) {
+
+	conf := &tls.Config{
+		ServerName:         viper.GetString("tls.server_name"),
+		InsecureSkipVerify: viper.GetBool("tls.insecure_skip_verify"),
+	}
+
+	if caFile := viper.GetString("tls.ca_file"); caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return conf, fmt.Errorf("tls.ca_file: %v", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return conf, fmt.Errorf("tls.ca_file: no PEM certificates in %s", caFile)
+		}
+		conf.RootCAs = roots
+	}
+
+	certFile, keyFile := viper.GetString("tls.cert_file"), viper.GetString("tls.key_file")
+	if (certFile == "") != (keyFile == "") {
+		return conf, fmt.Errorf("tls.cert_file and tls.key_file must be set together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return conf, fmt.Errorf("tls.cert_file, tls.key_file: %v", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return conf, nil
+}
+
+// tlsClientConfig returns base for a connection to host, verifying host
+// unless base names another server. A nil base means the defaults.
+func tlsClientConfig(base *tls.Config, host string) *tls.Config {
+
+	conf := &tls.Config{}
+	if base != nil {
+		conf = base.Clone()
+	}
+	if conf.ServerName == "" {
+		conf.ServerName = host
+	}
+
+	return conf
+}
+
+// newTransport returns the transport of the SDK clients, using the TLS
+// settings of the config and dial, when not nil, to open connections.
+func newTransport(dial func(ctx context.Context, network string, addr string) (net.Conn, error)) *http.Transport {
+
+	conf, _ := TLSConfig()
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dial,
+		TLSClientConfig:       conf,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
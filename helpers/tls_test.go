@@ -0,0 +1,231 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPKI is a CA with a server certificate for 127.0.0.1 and s3.test and
+// a client certificate, all written as PEM files under dir.
+type testPKI struct {
+	dir    string
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	server tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+
+	p := &testPKI{dir: t.TempDir()}
+
+	var caPEM []byte
+	p.ca, p.caKey, caPEM = p.issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	p.write(t, "ca.pem", caPEM)
+
+	_, key, certPEM := p.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "s3.test"},
+		DNSNames:    []string{"s3.test"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	server, err := tls.X509KeyPair(certPEM, keyPEM(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.server = server
+
+	_, key, certPEM = p.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	p.write(t, "client.pem", certPEM)
+	p.write(t, "client-key.pem", keyPEM(t, key))
+
+	return p
+}
+
+// issue signs template with the CA, or self-signs it while there is none.
+func (p *testPKI) issue(t *testing.T, template *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, signer := template, key
+	if p.ca != nil {
+		parent, signer = p.ca, p.caKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (p *testPKI) write(t *testing.T, name string, data []byte) {
+
+	if err := ioutil.WriteFile(filepath.Join(p.dir, name), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *testPKI) path(name string) string {
+
+	return filepath.Join(p.dir, name)
+}
+
+// serve starts an HTTPS server answering 200, requiring a client
+// certificate from the CA when mutual is set.
+func (p *testPKI) serve(t *testing.T, mutual bool) *httptest.Server {
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<ListAllMyBucketsResult></ListAllMyBucketsResult>"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{p.server}}
+	if mutual {
+		pool := x509.NewCertPool()
+		pool.AddCert(p.ca)
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+		server.TLS.ClientCAs = pool
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// rawGet sends an anonymous GET / over TLS with the settings of the config.
+func rawGet(server *httptest.Server) (*RawResponse, error) {
+
+	conf, err := TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	c := &RawClient{Endpoint: strings.TrimPrefix(server.URL, "https://"), Secure: true, TLS: conf, Timeout: 5 * time.Second}
+
+	return c.Do(NewRawRequest("GET", "", "", ""))
+}
+
+func TestTLSConfigFiles(t *testing.T) {
+
+	assert := assert.New(t)
+	pki := newTestPKI(t)
+
+	conf, err := TLSConfig()
+	assert.Nil(err)
+	assert.Nil(conf.RootCAs)
+	assert.Equal(0, len(conf.Certificates))
+
+	withConfig(t, map[string]string{"tls.ca_file": pki.path("ca.pem"), "tls.cert_file": pki.path("client.pem"), "tls.key_file": pki.path("client-key.pem")})
+	conf, err = TLSConfig()
+	assert.Nil(err)
+	assert.NotNil(conf.RootCAs)
+	assert.Equal(1, len(conf.Certificates))
+
+	withConfig(t, map[string]string{"tls.key_file": ""})
+	_, err = TLSConfig()
+	assert.Contains(err.Error(), "must be set together")
+
+	withConfig(t, map[string]string{"tls.key_file": pki.path("client-key.pem"), "tls.ca_file": pki.path("client-key.pem")})
+	_, err = TLSConfig()
+	assert.Contains(err.Error(), "no PEM certificates")
+	assert.Contains(validateConfig(), err.Error())
+}
+
+func TestTLSVerification(t *testing.T) {
+
+	assert := assert.New(t)
+	pki := newTestPKI(t)
+	server := pki.serve(t, false)
+
+	_, err := rawGet(server)
+	assert.NotNil(err, "untrusted CA")
+
+	withConfig(t, map[string]string{"tls.insecure_skip_verify": "true"})
+	resp, err := rawGet(server)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	withConfig(t, map[string]string{"tls.insecure_skip_verify": "false", "tls.ca_file": pki.path("ca.pem")})
+	resp, err = rawGet(server)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	withConfig(t, map[string]string{"tls.server_name": "s3.test"})
+	resp, err = rawGet(server)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	withConfig(t, map[string]string{"tls.server_name": "other.test"})
+	_, err = rawGet(server)
+	assert.NotNil(err, "name not in the certificate")
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+
+	assert := assert.New(t)
+	pki := newTestPKI(t)
+	server := pki.serve(t, true)
+
+	withConfig(t, map[string]string{"tls.ca_file": pki.path("ca.pem")})
+	_, err := rawGet(server)
+	assert.NotNil(err, "no client certificate")
+
+	withConfig(t, map[string]string{"tls.cert_file": pki.path("client.pem"), "tls.key_file": pki.path("client-key.pem")})
+	resp, err := rawGet(server)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	client := s3.New(sess, aws.NewConfig().
+		WithRegion("us-east-1").
+		WithEndpoint(server.URL).
+		WithS3ForcePathStyle(true).
+		WithMaxRetries(0).
+		WithHTTPClient(&http.Client{Transport: newTransport(nil)}).
+		WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", "")))
+	_, err = client.ListBuckets(&s3.ListBucketsInput{})
+	assert.Nil(err)
+}
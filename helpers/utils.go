@@ -11,13 +11,50 @@ import (
 
 const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 
+// runSeed is the seed the run started with; Seed does not change it.
+var runSeed = initialSeed()
+
+var randSeed = runSeed
+
 var seededRand *rand.Rand = rand.New(
-	rand.NewSource(time.Now().UnixNano()))
+	rand.NewSource(randSeed))
+
+func initialSeed() int64 {
+
+	loadedConfig()
+	if seed := viper.GetInt64("fixtures.seed"); seed != 0 {
+		return seed
+	}
+
+	return time.Now().UnixNano()
+}
+
+// Seed resets the generator behind String and StringWithCharset so that a
+// generated run can be reproduced.
+func Seed(seed int64) {
+
+	randSeed = seed
+	seededRand = rand.New(rand.NewSource(seed))
+}
+
+// RunSeed returns the seed the run started with: fixtures.seed when it is
+// set, otherwise the start time.
+func RunSeed() int64 {
+
+	return runSeed
+}
+
+// GetSeed returns the seed the generators were last seeded with.
+func GetSeed() int64 {
+
+	return randSeed
+}
 
 func StringWithCharset(length int, charset string) string {
-	b := make([]byte, length)
+	runes := []rune(charset)
+	b := make([]rune, length)
 	for i := range b {
-		b[i] = charset[seededRand.Intn(len(charset))]
+		b[i] = runes[seededRand.Intn(len(runes))]
 	}
 	return string(b)
 }
@@ -27,7 +64,14 @@ func String(length int) string {
 }
 
 var bucket_counter = 1
-var prefix = viper.GetString("fixtures.bucket_prefix")
+var prefix = bucketPrefix()
+
+func bucketPrefix() string {
+
+	loadedConfig()
+
+	return viper.GetString("fixtures.bucket_prefix")
+}
 
 func GetPrefix() string {
 
@@ -45,6 +89,21 @@ func GetBucketName() string {
 	return name
 }
 
+// FixtureBucketName returns the bucket name of the shared fixture called
+// name. Its random part comes from the run seed, so that runs sharing an
+// endpoint and prefix do not collide, while a replay with the recorded
+// fixtures.seed gets the recorded names.
+func FixtureBucketName(name string) string {
+
+	r := rand.New(rand.NewSource(runSeed))
+	random := make([]byte, 5)
+	for i := range random {
+		random[i] = charset[r.Intn(len(charset))]
+	}
+
+	return fmt.Sprintf("%s-%s-fixture-%s", GetPrefix(), random, name)
+}
+
 func Contains(slice []string, item string) bool {
 	set := make(map[string]struct{}, len(slice))
 	for _, s := range slice {
@@ -10,7 +10,7 @@ func TestString(t *testing.T) {
 
 	assert := assert.New(t)
 
-	res0 := string(10)
+	res0 := string(rune(10))
 	res1 := String(10)
 
 	assert.NotEqual(res0, res1)
@@ -28,6 +28,21 @@ func TestGetBucketName(t *testing.T) {
 
 }
 
+func TestFixtureBucketName(t *testing.T) {
+
+	assert := assert.New(t)
+
+	res0 := FixtureBucketName("reads")
+	assert.Equal(res0, FixtureBucketName("reads"))
+	assert.NotEqual(res0, FixtureBucketName("listing"))
+	assert.Nil(CheckBucketName(res0))
+
+	saved := runSeed
+	defer func() { runSeed = saved }()
+	runSeed++
+	assert.NotEqual(res0, FixtureBucketName("reads"))
+}
+
 func TestContains(t *testing.T) {
 
 	assert := assert.New(t)
@@ -0,0 +1,357 @@
+package s3test
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+	"github.com/spf13/viper"
+)
+
+// owners returns the canonical users of s3main and s3alt, skipping the
+// test when there is no alt user.
+func (suite *baseSuite) owners() (*s3.Owner, *s3.Owner) {
+
+	if !helpers.HasAltUser() {
+		suite.T().Skip("s3alt user is not configured")
+	}
+
+	owner, err := helpers.GetOwner(svc)
+	suite.Require().Nil(err)
+	alt, err := helpers.GetOwner(altSvc)
+	suite.Require().Nil(err)
+
+	return owner, alt
+}
+
+// aclProbe is an operation the alt user tries, allowed only by the
+// listed permissions.
+type aclProbe struct {
+	name    string
+	allowed []string
+	try     func() error
+}
+
+// runACLProbes checks every probe against the permission just granted.
+func (suite *baseSuite) runACLProbes(permission string, probes []aclProbe) {
+
+	assert := suite
+
+	for _, p := range probes {
+		err := p.try()
+		if helpers.Contains(p.allowed, permission) {
+			assert.Nil(err, "%s with %s", p.name, permission)
+		} else if diff := helpers.CheckError(err, helpers.ExpectError("AccessDenied")); diff != "" {
+			suite.Fail(diff, "%s with %s", p.name, permission)
+		}
+	}
+}
+
+func (suite *AuthSuite) TestBucketACLDefault() {
+
+	/*
+		Resource : bucket, method: get acl
+		Scenario : read the ACL of a new bucket.
+		Assertion: the owner is the s3main user and holds the only grant, FULL_CONTROL.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	owner, err := helpers.GetOwner(svc)
+	assert.Nil(err)
+
+	acl, err := helpers.GetBucketACL(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(aws.StringValue(owner.ID), aws.StringValue(acl.Owner.ID))
+	if name := viper.GetString("s3main.display_name"); name != "" {
+		assert.Equal(name, aws.StringValue(acl.Owner.DisplayName))
+	}
+	assert.Equal(1, len(acl.Grants))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(aws.StringValue(owner.ID), s3.PermissionFullControl)))
+}
+
+func (suite *AuthSuite) TestBucketACLPolicyXML() {
+
+	/*
+		Resource : bucket, method: get acl
+		Scenario : raw GET ?acl of a new bucket.
+		Assertion: an AccessControlPolicy document with a typed CanonicalUser grantee.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	owner, err := helpers.GetOwner(svc)
+	assert.Nil(err)
+
+	req := helpers.NewRawRequest("GET", bucket, "", "")
+	req.Query = "acl"
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	body := string(resp.Body)
+	assert.Contains(body, "<AccessControlPolicy")
+	assert.Contains(body, `xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`)
+	assert.Contains(body, `xsi:type="CanonicalUser"`)
+	assert.Contains(body, "<Owner><ID>"+aws.StringValue(owner.ID)+"</ID>")
+	assert.Contains(body, "<Permission>FULL_CONTROL</Permission>")
+}
+
+func (suite *AuthSuite) TestBucketACLExplicitGrants() {
+
+	/*
+		Resource : bucket, method: put acl, get acl
+		Scenario : put an AccessControlPolicy with canonical, email and group grantees.
+		Assertion: every grant reads back; the email grantee as the alt user's canonical ID.
+	*/
+
+	assert := suite
+	owner, alt := suite.owners()
+	bucket := suite.bucketName()
+	ownerID, altID := aws.StringValue(owner.ID), aws.StringValue(alt.ID)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutBucketGrants(svc, bucket, owner,
+		helpers.CanonicalGrant(ownerID, s3.PermissionFullControl),
+		helpers.CanonicalGrant(altID, s3.PermissionRead),
+		helpers.EmailGrant(viper.GetString("s3alt.email"), s3.PermissionReadAcp),
+		helpers.GroupGrant(helpers.AllUsersURI, s3.PermissionRead),
+		helpers.GroupGrant(helpers.AuthenticatedUsersURI, s3.PermissionWrite))
+	assert.Nil(err)
+
+	acl, err := helpers.GetBucketACL(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(ownerID, aws.StringValue(acl.Owner.ID))
+	assert.Equal(5, len(acl.Grants))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(ownerID, s3.PermissionFullControl)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(altID, s3.PermissionRead)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(altID, s3.PermissionReadAcp)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.GroupGrant(helpers.AllUsersURI, s3.PermissionRead)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.GroupGrant(helpers.AuthenticatedUsersURI, s3.PermissionWrite)))
+}
+
+func (suite *AuthSuite) TestBucketACLGrantHeaders() {
+
+	/*
+		Resource : bucket, method: put acl, get acl
+		Scenario : replace the ACL with x-amz-grant-* headers.
+		Assertion: the ACL holds exactly the header grants.
+	*/
+
+	assert := suite
+	owner, alt := suite.owners()
+	bucket := suite.bucketName()
+	ownerID, altID := aws.StringValue(owner.ID), aws.StringValue(alt.ID)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutBucketGrantHeaders(svc, bucket, helpers.GrantHeaders{
+		FullControl: helpers.Grantees(helpers.CanonicalGrant(ownerID, "")),
+		Read:        helpers.Grantees(helpers.GroupGrant(helpers.AllUsersURI, ""), helpers.CanonicalGrant(altID, "")),
+		WriteACP:    helpers.Grantees(helpers.EmailGrant(viper.GetString("s3alt.email"), "")),
+	})
+	assert.Nil(err)
+
+	acl, err := helpers.GetBucketACL(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(4, len(acl.Grants))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(ownerID, s3.PermissionFullControl)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.GroupGrant(helpers.AllUsersURI, s3.PermissionRead)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(altID, s3.PermissionRead)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(altID, s3.PermissionWriteAcp)))
+}
+
+func (suite *AuthSuite) TestObjectACLDefault() {
+
+	/*
+		Resource : object, method: get acl
+		Scenario : read the ACL of a new object.
+		Assertion: the writer owns it with the only grant, FULL_CONTROL.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	owner, err := helpers.GetOwner(svc)
+	assert.Nil(err)
+
+	acl, err := helpers.GetObjectACL(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal(aws.StringValue(owner.ID), aws.StringValue(acl.Owner.ID))
+	if name := viper.GetString("s3main.display_name"); name != "" {
+		assert.Equal(name, aws.StringValue(acl.Owner.DisplayName))
+	}
+	assert.Equal(1, len(acl.Grants))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(aws.StringValue(owner.ID), s3.PermissionFullControl)))
+}
+
+func (suite *AuthSuite) TestObjectACLExplicitGrants() {
+
+	/*
+		Resource : object, method: put acl, get acl
+		Scenario : put an AccessControlPolicy on an object with user and group grantees.
+		Assertion: every grant reads back.
+	*/
+
+	assert := suite
+	owner, alt := suite.owners()
+	bucket := suite.bucketName()
+	ownerID, altID := aws.StringValue(owner.ID), aws.StringValue(alt.ID)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	err = helpers.PutObjectGrants(svc, bucket, "key1", owner,
+		helpers.CanonicalGrant(ownerID, s3.PermissionFullControl),
+		helpers.EmailGrant(viper.GetString("s3alt.email"), s3.PermissionRead),
+		helpers.GroupGrant(helpers.AuthenticatedUsersURI, s3.PermissionReadAcp))
+	assert.Nil(err)
+
+	acl, err := helpers.GetObjectACL(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal(3, len(acl.Grants))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(ownerID, s3.PermissionFullControl)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(altID, s3.PermissionRead)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.GroupGrant(helpers.AuthenticatedUsersURI, s3.PermissionReadAcp)))
+}
+
+func (suite *AuthSuite) TestObjectACLGrantHeaders() {
+
+	/*
+		Resource : object, method: put acl, get acl
+		Scenario : replace the ACL of an object with x-amz-grant-* headers.
+		Assertion: the ACL holds exactly the header grants and the alt user can read.
+	*/
+
+	assert := suite
+	owner, alt := suite.owners()
+	bucket := suite.bucketName()
+	ownerID, altID := aws.StringValue(owner.ID), aws.StringValue(alt.ID)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	err = helpers.PutObjectGrantHeaders(svc, bucket, "key1", helpers.GrantHeaders{
+		FullControl: helpers.Grantees(helpers.CanonicalGrant(ownerID, "")),
+		Read:        helpers.Grantees(helpers.CanonicalGrant(altID, "")),
+	})
+	assert.Nil(err)
+
+	acl, err := helpers.GetObjectACL(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal(2, len(acl.Grants))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(ownerID, s3.PermissionFullControl)))
+	assert.True(helpers.HasGrant(acl.Grants, helpers.CanonicalGrant(altID, s3.PermissionRead)))
+
+	data, err := helpers.GetObject(altSvc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
+
+func (suite *AuthSuite) TestBucketACLPermissionEnforcement() {
+
+	/*
+		Resource : object, method: list, put
+		Resource : bucket, method: get acl, put acl
+		Scenario : grant the alt user each bucket permission in turn.
+		Assertion: the alt user can do exactly what the permission allows, AccessDenied otherwise.
+	*/
+
+	assert := suite
+	owner, alt := suite.owners()
+	ownerID, altID := aws.StringValue(owner.ID), aws.StringValue(alt.ID)
+
+	for _, permission := range helpers.ACLPermissions {
+		bucket := suite.bucketName()
+		grants := []*s3.Grant{
+			helpers.CanonicalGrant(ownerID, s3.PermissionFullControl),
+			helpers.CanonicalGrant(altID, permission),
+		}
+
+		err := helpers.CreateBucket(svc, bucket)
+		assert.Nil(err)
+		err = helpers.PutBucketGrants(svc, bucket, owner, grants...)
+		assert.Nil(err)
+
+		suite.runACLProbes(permission, []aclProbe{
+			{"list objects", []string{s3.PermissionRead, s3.PermissionFullControl}, func() error {
+				_, err := helpers.ListObjects(altSvc, bucket)
+				return err
+			}},
+			{"put object", []string{s3.PermissionWrite, s3.PermissionFullControl}, func() error {
+				return helpers.PutObjectToBucket(altSvc, bucket, "alt-key", "bar")
+			}},
+			{"get bucket acl", []string{s3.PermissionReadAcp, s3.PermissionFullControl}, func() error {
+				_, err := helpers.GetBucketACL(altSvc, bucket)
+				return err
+			}},
+			{"put bucket acl", []string{s3.PermissionWriteAcp, s3.PermissionFullControl}, func() error {
+				return helpers.PutBucketGrants(altSvc, bucket, owner, grants...)
+			}},
+		})
+	}
+}
+
+func (suite *AuthSuite) TestObjectACLPermissionEnforcement() {
+
+	/*
+		Resource : object, method: get, get acl, put acl
+		Scenario : grant the alt user each object permission in turn.
+		Assertion: the alt user can do exactly what the permission allows, AccessDenied otherwise.
+	*/
+
+	assert := suite
+	owner, alt := suite.owners()
+	ownerID, altID := aws.StringValue(owner.ID), aws.StringValue(alt.ID)
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	// WRITE does not apply to objects; overwriting is governed by the bucket.
+	for _, permission := range []string{s3.PermissionRead, s3.PermissionReadAcp, s3.PermissionWriteAcp, s3.PermissionFullControl} {
+		grants := []*s3.Grant{
+			helpers.CanonicalGrant(ownerID, s3.PermissionFullControl),
+			helpers.CanonicalGrant(altID, permission),
+		}
+		err = helpers.PutObjectGrants(svc, bucket, "key1", owner, grants...)
+		assert.Nil(err)
+
+		suite.runACLProbes(permission, []aclProbe{
+			{"get object", []string{s3.PermissionRead, s3.PermissionFullControl}, func() error {
+				_, err := helpers.GetObject(altSvc, bucket, "key1")
+				return err
+			}},
+			{"get object acl", []string{s3.PermissionReadAcp, s3.PermissionFullControl}, func() error {
+				_, err := helpers.GetObjectACL(altSvc, bucket, "key1")
+				return err
+			}},
+			{"put object acl", []string{s3.PermissionWriteAcp, s3.PermissionFullControl}, func() error {
+				return helpers.PutObjectGrants(altSvc, bucket, "key1", owner, grants...)
+			}},
+		})
+	}
+}
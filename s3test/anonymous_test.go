@@ -0,0 +1,196 @@
+package s3test
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// anonSvc and anonRaw send unsigned requests.
+var anonSvc = helpers.NewAnonConn()
+var anonRaw = helpers.NewRawClient(nil)
+
+// setupAnonBucket creates a bucket with bucketACL holding a private object
+// and one with objectACL.
+func (suite *baseSuite) setupAnonBucket(bucketACL string, objectACL string) string {
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	_, err = helpers.SetACL(svc, bucket, bucketACL)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "private", "secret")
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "shared", "hello")
+	assert.Nil(err)
+	err = helpers.SetObjectACL(svc, bucket, "shared", objectACL)
+	assert.Nil(err)
+
+	return bucket
+}
+
+func (suite *AuthSuite) TestAnonPrivateBucket() {
+
+	/*
+		Resource : object, method: get, head, list, put, delete
+		Resource : bucket, method: head
+		Scenario : anonymous requests against a private bucket and object.
+		Assertion: every request is denied and nothing is written.
+	*/
+
+	assert := suite
+	bucket := suite.setupAnonBucket("private", "private")
+
+	_, err := helpers.GetObject(anonSvc, bucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	_, err = helpers.HeadObject(anonSvc, bucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("Forbidden"))
+
+	_, err = helpers.ListObjects(anonSvc, bucket)
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	_, err = anonSvc.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	suite.assertS3Error(err, helpers.ExpectError("Forbidden"))
+
+	err = helpers.PutObjectToBucket(anonSvc, bucket, "anon", "bar")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+	suite.assertNotFound(bucket, "anon")
+
+	err = helpers.DeleteObject(anonSvc, bucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	data, err := helpers.GetObject(svc, bucket, "private")
+	assert.Nil(err)
+	assert.Equal("secret", data)
+}
+
+func (suite *AuthSuite) TestAnonPublicReadObject() {
+
+	/*
+		Resource : object, method: get, head
+		Scenario : anonymous reads of a public-read object in a private bucket.
+		Assertion: the public object is readable; its neighbour and the listing are not.
+	*/
+
+	assert := suite
+	bucket := suite.setupAnonBucket("private", "public-read")
+
+	data, err := helpers.GetObject(anonSvc, bucket, "shared")
+	assert.Nil(err)
+	assert.Equal("hello", data)
+
+	head, err := helpers.HeadObject(anonSvc, bucket, "shared")
+	assert.Nil(err)
+	assert.Equal(int64(5), aws.Int64Value(head.ContentLength))
+
+	_, err = helpers.GetObject(anonSvc, bucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	_, err = helpers.ListObjects(anonSvc, bucket)
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	_, err = helpers.GetObjectACL(anonSvc, bucket, "shared")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+}
+
+func (suite *AuthSuite) TestAnonPublicReadBucket() {
+
+	/*
+		Resource : object, method: list, get, put
+		Resource : bucket, method: get acl
+		Scenario : anonymous requests against a public-read bucket.
+		Assertion: listing works; private objects stay unreadable and writes are denied.
+	*/
+
+	assert := suite
+	bucket := suite.setupAnonBucket("public-read", "private")
+
+	objects, err := helpers.ListObjects(anonSvc, bucket)
+	assert.Nil(err)
+	assert.Equal(2, len(objects))
+
+	_, err = helpers.GetObject(anonSvc, bucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	err = helpers.PutObjectToBucket(anonSvc, bucket, "anon", "bar")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+	suite.assertNotFound(bucket, "anon")
+
+	_, err = helpers.GetBucketACL(anonSvc, bucket)
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+}
+
+func (suite *AuthSuite) TestAnonPublicReadWriteBucket() {
+
+	/*
+		Resource : object, method: list, put, get, delete
+		Scenario : anonymous requests against a public-read-write bucket.
+		Assertion: listing, writing and deleting work; private objects stay unreadable.
+	*/
+
+	assert := suite
+	bucket := suite.setupAnonBucket("public-read-write", "private")
+
+	err := helpers.PutObjectToBucket(anonSvc, bucket, "anon", "bar")
+	assert.Nil(err)
+
+	_, keys, err := helpers.GetKeys(svc, bucket)
+	assert.Nil(err)
+	assert.Equal([]string{"anon", "private", "shared"}, keys)
+
+	_, err = helpers.GetObject(anonSvc, bucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	err = helpers.DeleteObject(anonSvc, bucket, "anon")
+	assert.Nil(err)
+	suite.assertNotFound(bucket, "anon")
+}
+
+func (suite *AuthSuite) TestAnonListBuckets() {
+
+	/*
+		Resource : service, method: list buckets
+		Scenario : anonymous GET / with buckets present.
+		Assertion: AccessDenied, and no bucket name appears in the response.
+	*/
+
+	assert := suite
+	bucket := suite.setupAnonBucket("public-read", "public-read")
+
+	_, err := anonSvc.ListBuckets(&s3.ListBucketsInput{})
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	resp, err := anonRaw.Do(helpers.NewRawRequest("GET", "", "", ""))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("AccessDenied"))
+	assert.NotContains(string(resp.Body), bucket)
+}
+
+func (suite *AuthSuite) TestAnonRawNoLeak() {
+
+	/*
+		Resource : object, method: get, head
+		Scenario : raw unsigned GET and HEAD of a private object.
+		Assertion: 403 with no object data, ETag or metadata in the response.
+	*/
+
+	assert := suite
+	bucket := suite.setupAnonBucket("private", "private")
+
+	resp, err := anonRaw.Do(helpers.NewRawRequest("GET", bucket, "private", ""))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("AccessDenied"))
+	assert.NotContains(string(resp.Body), "secret")
+	assert.Equal("", resp.Header.Get("ETag"))
+
+	resp, err = anonRaw.Do(helpers.NewRawRequest("HEAD", bucket, "private", ""))
+	assert.Nil(err)
+	assert.Equal(http.StatusForbidden, resp.Status)
+	assert.Equal("", resp.Header.Get("ETag"))
+	assert.Equal("", resp.Header.Get("Last-Modified"))
+}
@@ -0,0 +1,26 @@
+package s3test
+
+import (
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// assertS3Error fails the test with a field-by-field diff unless err is the
+// S3 error described by spec.
+func (suite *baseSuite) assertS3Error(err error, spec helpers.ErrorSpec) bool {
+
+	if diff := helpers.CheckError(err, spec); diff != "" {
+		return suite.Fail(diff)
+	}
+
+	return true
+}
+
+// assertRawError is assertS3Error for a raw response.
+func (suite *baseSuite) assertRawError(resp *helpers.RawResponse, spec helpers.ErrorSpec) bool {
+
+	if diff := helpers.CheckRawError(resp, spec); diff != "" {
+		return suite.Fail(diff)
+	}
+
+	return true
+}
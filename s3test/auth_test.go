@@ -0,0 +1,73 @@
+package s3test
+
+import (
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+//..................................Authorization header.........................................................
+
+func (suite *AuthSuite) TestObjectCreateBadAuthorizationUnreadable() {
+
+	/*
+		Resource : object, method: put
+		Scenario : create w/non-graphic authorization.
+		Assertion: suceeds.... but should fail
+			"Authorization" is in the ingnored header list, so its value does not matter
+	*/
+
+	assert := suite
+	content := "bar"
+
+	bucket := suite.bucketName()
+	key := "key1"
+	err := helpers.CreateBucket(svc, bucket)
+
+	//TODO: Not Used
+	headers := map[string]string{"Authorization": "\x01"}
+
+	err = helpers.SetupObjectWithHeader(svc, bucket, key, content, headers)
+	assert.Nil(err)
+}
+
+func (suite *AuthSuite) TestObjectCreateBadAuthorizationEmpty() {
+
+	/*
+		Resource : object, method: put
+		Scenario :create w/empty authorization.
+		Assertion: fails
+	*/
+
+	assert := suite
+	content := "bar"
+
+	bucket := suite.bucketName()
+	key := "key1"
+	err := helpers.CreateBucket(svc, bucket)
+	//TODO: Not Used
+	headers := map[string]string{"Authorization": " "}
+
+	err = helpers.SetupObjectWithHeader(svc, bucket, key, content, headers)
+	assert.Nil(err)
+}
+
+func (suite *AuthSuite) TestObjectCreateBadAuthorizationNone() {
+
+	/*
+		Resource : object, method: put
+		Scenario :create w/no authorization.
+		Assertion: fails
+	*/
+
+	assert := suite
+	content := "bar"
+
+	bucket := suite.bucketName()
+	key := "key1"
+	err := helpers.CreateBucket(svc, bucket)
+
+	//TODO: Not Used
+	headers := map[string]string{"Authorization": ""}
+
+	err = helpers.SetupObjectWithHeader(svc, bucket, key, content, headers)
+	assert.Nil(err)
+}
@@ -13,7 +13,14 @@ import (
 	"github.com/spf13/viper"
 )
 
-func (suite *S3Suite) TestPresignRequest() {
+func (suite *AuthSuite) TestPresignRequest() {
+
+	/*
+		Resource : signature, method: presign
+		Scenario : presign a request at a fixed time, without sending it.
+		Assertion: the query carries the credential scope, signed headers and date.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -29,7 +36,14 @@ func (suite *S3Suite) TestPresignRequest() {
 	assert.Equal("19700101T000000Z", qry.Get("X-Amz-Date"))
 }
 
-func (suite *S3Suite) TestSignRequest() {
+func (suite *AuthSuite) TestSignRequest() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign a request at a fixed time, without sending it.
+		Assertion: the Authorization header carries the credential scope and signed headers.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -46,7 +60,14 @@ func (suite *S3Suite) TestSignRequest() {
 	assert.Equal("19700101T000000Z", qry.Get("X-Amz-Date"))
 }
 
-func (suite *S3Suite) TestSignBody() {
+func (suite *AuthSuite) TestSignBody() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign a request with a body.
+		Assertion: X-Amz-Content-Sha256 is the SHA-256 of the body.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -59,7 +80,14 @@ func (suite *S3Suite) TestSignBody() {
 	assert.Equal("0e6807fb3a06ab2a6ee35df3d89365b2af1266eb390e9e687e9a500de32571bd", hash)
 }
 
-func (suite *S3Suite) TestPresignEmptyBody() {
+func (suite *AuthSuite) TestPresignEmptyBody() {
+
+	/*
+		Resource : signature, method: presign
+		Scenario : presign a request with an empty body.
+		Assertion: no X-Amz-Content-Sha256 header is set.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -72,7 +100,14 @@ func (suite *S3Suite) TestPresignEmptyBody() {
 	assert.Equal("", hash)
 }
 
-func (suite *S3Suite) TestSignUnsignedpayload() {
+func (suite *AuthSuite) TestSignUnsignedpayload() {
+
+	/*
+		Resource : signature, method: presign
+		Scenario : presign a request with a body.
+		Assertion: no X-Amz-Content-Sha256 header is set.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -85,7 +120,14 @@ func (suite *S3Suite) TestSignUnsignedpayload() {
 	assert.Equal("", hash)
 }
 
-func (suite *S3Suite) TestSignWithRequestBody() {
+func (suite *AuthSuite) TestSignWithRequestBody() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign a POST with a body and send it to a local server.
+		Assertion: the server receives the body.
+		Tags     : offline
+	*/
 
 	assert := suite
 	signer := v4.NewSigner(helpers.Creds)
@@ -110,7 +152,14 @@ func (suite *S3Suite) TestSignWithRequestBody() {
 	assert.Equal(http.StatusOK, resp.StatusCode)
 }
 
-func (suite *S3Suite) TestSignWithRequestBodyOverwrite() {
+func (suite *AuthSuite) TestSignWithRequestBodyOverwrite() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign a GET with a nil body over a request that has one.
+		Assertion: the server receives no body.
+		Tags     : offline
+	*/
 
 	assert := suite
 	signer := v4.NewSigner(helpers.Creds)
@@ -137,7 +186,14 @@ func (suite *S3Suite) TestSignWithRequestBodyOverwrite() {
 	assert.Equal(http.StatusOK, resp.StatusCode)
 }
 
-func (suite *S3Suite) TestSignWithBodyReplaceRequestBody() {
+func (suite *AuthSuite) TestSignWithBodyReplaceRequestBody() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign with a seekable body different from the request body.
+		Assertion: the signer replaces the request body.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -154,7 +210,14 @@ func (suite *S3Suite) TestSignWithBodyReplaceRequestBody() {
 	assert.NotNil(req.Body)
 }
 
-func (suite *S3Suite) TestSignWithBodyNoReplaceRequestBody() {
+func (suite *AuthSuite) TestSignWithBodyNoReplaceRequestBody() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign with request body overwrite disabled.
+		Assertion: the request body is kept.
+		Tags     : offline
+	*/
 
 	assert := suite
 	region := viper.GetString("s3main.region")
@@ -180,7 +243,7 @@ func (suite *S3Suite) TestSignWithBodyNoReplaceRequestBody() {
 // The expected behavior of other cases testing (pre)sign of requests should also be verified,
 // even if the tests are passing.
 
-// func (suite *S3Suite) TestPresignHandler() {
+// func (suite *AuthSuite) TestPresignHandler() {
 
 // 	assert := suite
 // 	req, _ := svc.PutObjectRequest(&s3.PutObjectInput{
@@ -213,7 +276,14 @@ func (suite *S3Suite) TestSignWithBodyNoReplaceRequestBody() {
 // 	assert.NotContains(urlstr, "+") // + encoded as %20
 // }
 
-func (suite *S3Suite) TestStandaloneSignCustomURIEscape() {
+func (suite *AuthSuite) TestStandaloneSignCustomURIEscape() {
+
+	/*
+		Resource : signature, method: sign
+		Scenario : sign an already escaped opaque path with URI escaping disabled.
+		Assertion: the Authorization header matches the expected scope and headers.
+		Tags     : offline
+	*/
 
 	assert := suite
 	var credentials string = viper.GetString("s3main.access_key") + "/" + "19700101" + "/"
@@ -1,21 +1,23 @@
 package s3test
 
 import (
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"net/http"
+
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/huangnauh/go_s3tests/helpers"
 )
 
-func (suite *S3Suite) TestBucketCreateReadDelete() {
+func (suite *BucketSuite) TestBucketCreateReadDelete() {
 
 	/*
 		Resource : bucket, method: create/delete
 		Scenario : create and delete bucket.
 		Assertion: bucket exists after create and is gone after delete.
+		Tags     : smoke
 	*/
 
 	assert := suite
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 
 	err := helpers.CreateBucket(svc, bucket)
 	assert.Nil(err)
@@ -29,12 +31,10 @@ func (suite *S3Suite) TestBucketCreateReadDelete() {
 	err = helpers.DeleteBucket(svc, bucket)
 	assert.NotNil(err)
 
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "NoSuchBucket")
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchBucket"))
 }
 
-func (suite *S3Suite) TestBucketDeleteNotExist() {
+func (suite *BucketSuite) TestBucketDeleteNotExist() {
 
 	/*
 		Resource : bucket, method: delete
@@ -43,17 +43,15 @@ func (suite *S3Suite) TestBucketDeleteNotExist() {
 	*/
 
 	assert := suite
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 
 	err := helpers.DeleteBucket(svc, bucket)
 	assert.NotNil(err)
 
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "NoSuchBucket")
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchBucket"))
 }
 
-func (suite *S3Suite) TestBucketDeleteNotEmpty() {
+func (suite *BucketSuite) TestBucketDeleteNotEmpty() {
 
 	/*
 		Resource : bucket, method: delete
@@ -62,7 +60,7 @@ func (suite *S3Suite) TestBucketDeleteNotEmpty() {
 	*/
 
 	assert := suite
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 	objects := map[string]string{"key1": "echo"}
 
 	err := helpers.CreateBucket(svc, bucket)
@@ -73,12 +71,10 @@ func (suite *S3Suite) TestBucketDeleteNotEmpty() {
 	err = helpers.DeleteBucket(svc, bucket)
 	assert.NotNil(err)
 
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "BucketNotEmpty")
+	suite.assertS3Error(err, helpers.ExpectError("BucketNotEmpty"))
 }
 
-func (suite *S3Suite) TestBucketListEmpty() {
+func (suite *BucketSuite) TestBucketListEmpty() {
 
 	/*
 		Resource : object, method: list
@@ -87,7 +83,7 @@ func (suite *S3Suite) TestBucketListEmpty() {
 	*/
 
 	assert := suite
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 	var empty_list []*s3.Object
 
 	err := helpers.CreateBucket(svc, bucket)
@@ -98,17 +94,18 @@ func (suite *S3Suite) TestBucketListEmpty() {
 	assert.Equal(empty_list, resp.Contents)
 }
 
-func (suite *S3Suite) TestBucketListDistinct() {
+func (suite *BucketSuite) TestBucketListDistinct() {
 
 	/*
 		Resource : object, method: list
 		Scenario : bucket not empty
 		Assertion: distinct buckets have different contents.
+		Tags     : smoke
 	*/
 
 	assert := suite
-	bucket1 := helpers.GetBucketName()
-	bucket2 := helpers.GetBucketName()
+	bucket1 := suite.bucketName()
+	bucket2 := suite.bucketName()
 	objects1 := map[string]string{"key1": "Hello"}
 	objects2 := map[string]string{"key2": "Manze"}
 
@@ -127,189 +124,172 @@ func (suite *S3Suite) TestBucketListDistinct() {
 
 }
 
-func (suite *S3Suite) TestObjectAclCreateContentlengthNone() {
+func (suite *BucketSuite) TestObjectAclCreateContentlengthNone() {
 
 	/*
-		Resource : bucket, method: acls
+		Resource : bucket, method: put acl
 		Scenario :set w/no content length.
 		Assertion: suceeds
 	*/
 
 	assert := suite
-	conLength := map[string]string{"Content-Length": ""}
-	// acl := map[string]string{"ACL": "public-read"}
-	content := "bar"
+	bucket := suite.bucketName()
 
-	bucket := helpers.GetBucketName()
-	key := "key1"
 	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
 
-	err = helpers.SetupObjectWithHeader(svc, bucket, key, content, conLength)
-	_, err = helpers.SetACL(svc, bucket, "public-read")
+	req := helpers.NewRawRequest("PUT", bucket, "", "").Header("X-Amz-Acl", "public-read")
+	req.Query = "acl"
+	req.NoContentLength = true
+	resp, err := rawClient.Do(req)
 	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	acl, err := helpers.GetBucketACL(svc, bucket)
+	assert.Nil(err)
+	assert.True(helpers.HasGrant(acl.Grants, helpers.GroupGrant(helpers.AllUsersURI, s3.PermissionRead)))
 }
 
-func (suite *S3Suite) TestBucketPutCanned_acl() {
+func (suite *BucketSuite) TestBucketPutCanned_acl() {
 
 	/*
-		Resource : bucket, method: put
-		Scenario :set w/invalid permission.
-		Assertion: fails
+		Resource : bucket, method: put acl
+		Scenario :set public-read canned acl.
+		Assertion: succeeds
 	*/
 
 	assert := suite
 
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 	err := helpers.CreateBucket(svc, bucket)
 
-	_, err = helpers.SetACL(svc, bucket, "public-ready")
 	_, err = helpers.SetACL(svc, bucket, "public-read")
 	assert.Nil(err)
-	if err != nil {
-		if awsErr, ok := err.(awserr.Error); ok {
-
-			assert.Equal(awsErr.Code(), "AccessDenied")
-			assert.Equal(awsErr.Message(), "")
-		}
-	}
 }
 
-func (suite *S3Suite) TestBucketCreateBadExpectMismatch() {
+func (suite *BucketSuite) TestBucketCreateBadExpectMismatch() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/expect 200.
-		Assertion: garbage, but S3 succeeds!
+		Assertion: fails with 417 ExpectationFailed.
 	*/
 
 	assert := suite
-	acl := map[string]string{"Expect": "200"}
+	bucket := suite.bucketName()
 
-	bucket := helpers.GetBucketName()
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
-	assert.NotNil(err)
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "ExpectationFailed")
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, "", "").Header("Expect", "200"))
+	assert.Nil(err)
+	assert.Equal(http.StatusExpectationFailed, resp.Status)
+	assert.Equal("ExpectationFailed", resp.Code())
 }
 
-func (suite *S3Suite) TestBucketCreateBadExpectEmpty() {
+func (suite *BucketSuite) TestBucketCreateBadExpectEmpty() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/expect empty.
 		Assertion: garbage, but S3 succeeds!
 	*/
 
 	assert := suite
-	acl := map[string]string{"Expect": " "}
+	bucket := suite.bucketName()
 
-	bucket := helpers.GetBucketName()
-
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, "", "").Header("Expect", " "))
 	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
 }
 
-func (suite *S3Suite) TestBucketCreateBadExpectUnreadable() {
+func (suite *BucketSuite) TestBucketCreateBadExpectUnreadable() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/expect nongraphic.
-		Assertion: fails with "invalid header field value"
+		Assertion: fails with a 4xx
 	*/
 
 	assert := suite
-	acl := map[string]string{"Expect": "\x07"}
-
-	bucket := helpers.GetBucketName()
-
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
+	bucket := suite.bucketName()
 
-	assert.NotNil(err)
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, "", "").Header("Expect", "\x07"))
+	assert.Nil(err)
+	assert.True(resp.Status >= 400 && resp.Status < 500)
 }
 
-func (suite *S3Suite) TestBucketCreateBadContentLengthEmpty() {
+func (suite *BucketSuite) TestBucketCreateBadContentLengthEmpty() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/empty content length.
 		Assertion: fails
 	*/
 
 	assert := suite
-	acl := map[string]string{"Content-Length": " "}
-
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
-	assert.NotNil(err)
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "MissingContentLength")
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, "", "").Header("Content-Length", " "))
+	assert.Nil(err)
+	assert.True(resp.Status >= 400 && resp.Status < 500)
+	assert.Equal("MissingContentLength", resp.Code())
 }
 
-func (suite *S3Suite) TestBucketCreateBadContentlengthNegative() {
+func (suite *BucketSuite) TestBucketCreateBadContentlengthNegative() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/negative content length.
 		Assertion: fails
 	*/
 
 	assert := suite
-	acl := map[string]string{"Content-Length": "-1"}
-
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
-	assert.NotNil(err)
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "MissingContentLength")
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, "", "").Header("Content-Length", "-1"))
+	assert.Nil(err)
+	assert.True(resp.Status >= 400 && resp.Status < 500)
+	assert.Equal("MissingContentLength", resp.Code())
 }
 
-func (suite *S3Suite) TestBucketCreateBadContentlengthNone() {
+func (suite *BucketSuite) TestBucketCreateBadContentlengthNone() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/no content length.
 		Assertion: suceeds
 	*/
 
 	assert := suite
-	acl := map[string]string{"Content-Length": ""}
-
-	bucket := helpers.GetBucketName()
+	bucket := suite.bucketName()
 
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
+	req := helpers.NewRawRequest("PUT", bucket, "", "")
+	req.NoContentLength = true
+	resp, err := rawClient.Do(req)
 	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
 }
 
-func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
+func (suite *BucketSuite) TestBucket_CreateBadContentlengthUnreadable() {
 
 	/*
-		Resource : bucket, method: put
+		Resource : bucket, method: create
 		Scenario :create w/unreadable content length.
 		Assertion: fails
 	*/
 
 	assert := suite
-	acl := map[string]string{"Content-Length": "\x07"}
+	bucket := suite.bucketName()
 
-	bucket := helpers.GetBucketName()
-
-	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
-	assert.NotNil(err)
-	awsErr, ok := err.(awserr.Error)
-	assert.True(ok)
-	assert.Equal(awsErr.Code(), "MissingContentLength")
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, "", "").Header("Content-Length", "\x07"))
+	assert.Nil(err)
+	assert.True(resp.Status >= 400 && resp.Status < 500)
+	assert.Equal("MissingContentLength", resp.Code())
 }
 
 //TODO:
-// func (suite *S3Suite) TestBucketCreateBadAuthorizationUnreadable() {
+// func (suite *BucketSuite) TestBucketCreateBadAuthorizationUnreadable() {
 
 // 	/*
-// 		Resource : bucket, method: put
+// 		Resource : bucket, method: create
 // 		Scenario :create w/non-graphic authorization.
 // 		Assertion: expected to fail..but suceeded
 // 	*/
@@ -317,7 +297,7 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // 	assert := suite
 // 	acl := map[string]string{"Authorization": "\x07"}
 
-// 	bucket := helpers.GetBucketName()
+// 	bucket := suite.bucketName()
 
 // 	err := helpers.CreateBucketWithHeader(svc, bucket, acl)
 // 	assert.Nil(err)
@@ -331,10 +311,10 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // }
 
 //TODO:
-// func (suite *S3Suite) TestBucketCreateBadAuthorizationEmpty() {
+// func (suite *BucketSuite) TestBucketCreateBadAuthorizationEmpty() {
 
 // 	/*
-// 		Resource : bucket, method: put
+// 		Resource : bucket, method: create
 // 		Scenario :create w/empty authorization.
 // 		Assertion: expected to fail..but suceeded
 // 	*/
@@ -343,7 +323,7 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 
 // 	acl := map[string]string{"Authorization": " "}
 
-// 	bucket := helpers.GetBucketName()
+// 	bucket := suite.bucketName()
 // 	err := helpers.CreateBucket(svc, bucket)
 
 // 	err = helpers.CreateBucketWithHeader(svc, bucket, acl)
@@ -358,10 +338,10 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // }
 
 //TODO:
-// func (suite *S3Suite) TestBucketCreateBadAuthorizationNone() {
+// func (suite *BucketSuite) TestBucketCreateBadAuthorizationNone() {
 
 // 	/*
-// 		Resource : bucket, method: put
+// 		Resource : bucket, method: create
 // 		Scenario :create w/no authorization.
 // 		Assertion: expected to fail..but suceeded
 // 	*/
@@ -369,7 +349,7 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // 	assert := suite
 // 	acl := map[string]string{"Authorization": ""}
 
-// 	bucket := helpers.GetBucketName()
+// 	bucket := suite.bucketName()
 // 	err := helpers.CreateBucket(svc, bucket)
 
 // 	err = helpers.CreateBucketWithHeader(svc, bucket, acl)
@@ -384,10 +364,10 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // }
 
 //TODO:
-// func (suite *S3Suite) TestLifecycleGetNoLifecycle() {
+// func (suite *BucketSuite) TestLifecycleGetNoLifecycle() {
 
 // 	/*
-// 		Resource : bucket, method: get
+// 		Resource : bucket, method: get lifecycle
 // 		Scenario : get lifecycle config that has not been set.
 // 		Assertion: fails
 // 	*/
@@ -395,7 +375,7 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // 	assert := suite
 // 	//acl := map[string]string{"Authorization": ""}
 
-// 	bucket := helpers.GetBucketName()
+// 	bucket := suite.bucketName()
 // 	err := helpers.CreateBucket(svc, bucket)
 
 // 	_, err = helpers.GetLifecycle(svc, bucket)
@@ -410,17 +390,17 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // }
 
 //TODO:
-// func (suite *S3Suite) TestLifecycleInvalidMD5() {
+// func (suite *BucketSuite) TestLifecycleInvalidMD5() {
 
 // 	/*
-// 		Resource : bucket, method: get
+// 		Resource : bucket, method: put lifecycle
 // 		Scenario : set lifecycle config with invalid md5.
 // 		Assertion: fails
 // 	*/
 
 // 	assert := suite
 
-// 	bucket := helpers.GetBucketName()
+// 	bucket := suite.bucketName()
 // 	err := helpers.CreateBucket(svc, bucket)
 
 // 	content := strings.NewReader("Enabled")
@@ -443,17 +423,17 @@ func (suite *S3Suite) TestBucket_CreateBadContentlengthUnreadable() {
 // }
 
 //TODO:
-// func (suite *S3Suite) TestLifecycleInvalidStatus() {
+// func (suite *BucketSuite) TestLifecycleInvalidStatus() {
 
 // 	/*
-// 		Resource : bucket, method: get
+// 		Resource : bucket, method: put lifecycle
 // 		Scenario : invalid status in lifecycle rule.
 // 		Assertion: fails
 // 	*/
 
 // 	assert := suite
 
-// 	bucket := helpers.GetBucketName()
+// 	bucket := suite.bucketName()
 // 	err := helpers.CreateBucket(svc, bucket)
 
 // 	content := strings.NewReader("Enabled")
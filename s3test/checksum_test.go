@@ -0,0 +1,332 @@
+package s3test
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// checksumRead GETs or HEADs bucket/key with x-amz-checksum-mode: ENABLED.
+func (suite *baseSuite) checksumRead(method string, bucket string, key string) *helpers.RawResponse {
+
+	resp, err := rawClient.Do(helpers.NewRawRequest(method, bucket, key, "").Header("X-Amz-Checksum-Mode", "ENABLED"))
+	suite.Require().Nil(err)
+	suite.Require().Equal(http.StatusOK, resp.Status)
+
+	return resp
+}
+
+func (suite *ObjectSuite) TestChecksumPutObject() {
+
+	/*
+		Resource : object, method: put, get, head
+		Scenario : PUT with a correct x-amz-checksum-* header for each algorithm.
+		Assertion: succeeds and GET/HEAD with checksum mode return the same checksum.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	content := "checksummed content"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, a := range helpers.ChecksumAlgorithms {
+		key := "key-" + strings.ToLower(string(a))
+		checksum := a.Checksum([]byte(content))
+
+		resp, err := rawClient.Do(helpers.NewChecksumPut(bucket, key, content, a, checksum))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, resp.Status, string(a))
+		assert.Equal(checksum, resp.Header.Get(a.Header()), string(a))
+
+		get := suite.checksumRead("GET", bucket, key)
+		assert.Equal(content, string(get.Body))
+		assert.Equal(checksum, get.Header.Get(a.Header()), string(a))
+
+		head := suite.checksumRead("HEAD", bucket, key)
+		assert.Equal(checksum, head.Header.Get(a.Header()), string(a))
+	}
+}
+
+func (suite *ObjectSuite) TestChecksumGetWithoutMode() {
+
+	/*
+		Resource : object, method: get
+		Scenario : GET of a checksummed object without x-amz-checksum-mode.
+		Assertion: the checksum header is not returned.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	a := helpers.ChecksumCRC32C
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewChecksumPut(bucket, "key1", "bar", a, a.Checksum([]byte("bar"))))
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	resp, err = rawClient.Do(helpers.NewRawRequest("GET", bucket, "key1", ""))
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	assert.Equal("", resp.Header.Get(a.Header()))
+}
+
+func (suite *ObjectSuite) TestChecksumPutObjectWrong() {
+
+	/*
+		Resource : object, method: put
+		Scenario : PUT whose x-amz-checksum-* header belongs to other content.
+		Assertion: fails with BadDigest for each algorithm and nothing is stored.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, a := range helpers.ChecksumAlgorithms {
+		key := "key-" + strings.ToLower(string(a))
+		resp, err := rawClient.Do(helpers.NewChecksumPut(bucket, key, "bar", a, a.Checksum([]byte("baz"))))
+		assert.Nil(err)
+		if diff := helpers.CheckRawError(resp, helpers.ExpectError("BadDigest")); diff != "" {
+			suite.Fail(diff, string(a))
+		}
+		suite.assertNotFound(bucket, key)
+	}
+}
+
+func (suite *ObjectSuite) TestChecksumPutObjectMalformed() {
+
+	/*
+		Resource : object, method: put
+		Scenario : PUT with a checksum that is not base64 or has the wrong length.
+		Assertion: fails with InvalidRequest.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	a := helpers.ChecksumSHA256
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, checksum := range []string{"not base64!", helpers.ChecksumCRC32.Checksum([]byte("bar"))} {
+		resp, err := rawClient.Do(helpers.NewChecksumPut(bucket, "key1", "bar", a, checksum))
+		assert.Nil(err)
+		if diff := helpers.CheckRawError(resp, helpers.ExpectError("InvalidRequest")); diff != "" {
+			suite.Fail(diff, checksum)
+		}
+	}
+	suite.assertNotFound(bucket, "key1")
+}
+
+func (suite *ObjectSuite) TestChecksumPutObjectMismatchedAlgorithm() {
+
+	/*
+		Resource : object, method: put
+		Scenario : x-amz-sdk-checksum-algorithm names CRC32 but the checksum header is SHA256.
+		Assertion: fails with InvalidRequest.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	req := helpers.NewRawRequest("PUT", bucket, "key1", "bar").
+		Header("X-Amz-Sdk-Checksum-Algorithm", string(helpers.ChecksumCRC32)).
+		Header(helpers.ChecksumSHA256.Header(), helpers.ChecksumSHA256.Checksum([]byte("bar")))
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("InvalidRequest"))
+	suite.assertNotFound(bucket, "key1")
+}
+
+func (suite *ObjectSuite) TestChecksumMultipartComposite() {
+
+	/*
+		Resource : object, method: multipart upload, head
+		Scenario : multipart upload with per-part checksums for each algorithm.
+		Assertion: the completed object reports the composite checksum with the part count.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	parts := []string{strings.Repeat("a", 5*1024*1024), "tail"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, a := range helpers.ChecksumAlgorithms {
+		key := "key-" + strings.ToLower(string(a))
+
+		uploadID, resp, err := helpers.CreateChecksumUpload(rawClient, bucket, key, a)
+		suite.Require().Nil(err)
+		suite.Require().Equal(http.StatusOK, resp.Status)
+
+		var completed []helpers.ChecksumPart
+		var checksums []string
+		for i, data := range parts {
+			checksum := a.Checksum([]byte(data))
+			resp, err := helpers.UploadChecksumPart(rawClient, bucket, key, uploadID, i+1, data, a, checksum)
+			assert.Nil(err)
+			assert.Equal(http.StatusOK, resp.Status, string(a))
+			assert.Equal(checksum, resp.Header.Get(a.Header()), string(a))
+			completed = append(completed, helpers.ChecksumPart{Number: i + 1, ETag: resp.Header.Get("ETag"), Checksum: checksum})
+			checksums = append(checksums, checksum)
+		}
+
+		resp, err = helpers.CompleteChecksumUpload(rawClient, bucket, key, uploadID, a, completed)
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, resp.Status, string(a))
+
+		composite, err := a.CompositeChecksum(checksums)
+		assert.Nil(err)
+		assert.Equal(composite, helpers.CompletedChecksum(resp, a), string(a))
+
+		head := suite.checksumRead("HEAD", bucket, key)
+		assert.Equal(composite, head.Header.Get(a.Header()), string(a))
+	}
+}
+
+func (suite *ObjectSuite) TestChecksumUploadPartWrong() {
+
+	/*
+		Resource : object, method: upload part
+		Scenario : part whose checksum belongs to other content.
+		Assertion: fails with BadDigest.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	a := helpers.ChecksumCRC32
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	uploadID, _, err := helpers.CreateChecksumUpload(rawClient, bucket, "key1", a)
+	suite.Require().Nil(err)
+
+	resp, err := helpers.UploadChecksumPart(rawClient, bucket, "key1", uploadID, 1, "bar", a, a.Checksum([]byte("baz")))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("BadDigest"))
+
+	_, err = helpers.AbortMultiPartUpload(svc, bucket, "key1", uploadID)
+	assert.Nil(err)
+}
+
+func (suite *ObjectSuite) TestChecksumUploadPartMismatchedAlgorithm() {
+
+	/*
+		Resource : object, method: upload part
+		Scenario : upload created for CRC32 receives a part checksummed with SHA256.
+		Assertion: fails with InvalidRequest.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	uploadID, _, err := helpers.CreateChecksumUpload(rawClient, bucket, "key1", helpers.ChecksumCRC32)
+	suite.Require().Nil(err)
+
+	a := helpers.ChecksumSHA256
+	resp, err := helpers.UploadChecksumPart(rawClient, bucket, "key1", uploadID, 1, "bar", a, a.Checksum([]byte("bar")))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("InvalidRequest"))
+
+	_, err = helpers.AbortMultiPartUpload(svc, bucket, "key1", uploadID)
+	assert.Nil(err)
+}
+
+func (suite *ObjectSuite) TestChecksumCompleteWrongPartChecksum() {
+
+	/*
+		Resource : object, method: complete multipart upload
+		Scenario : CompleteMultipartUpload lists a checksum that differs from the uploaded part's.
+		Assertion: fails with InvalidPart and the object is not created.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	a := helpers.ChecksumSHA1
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	uploadID, _, err := helpers.CreateChecksumUpload(rawClient, bucket, "key1", a)
+	suite.Require().Nil(err)
+
+	resp, err := helpers.UploadChecksumPart(rawClient, bucket, "key1", uploadID, 1, "bar", a, a.Checksum([]byte("bar")))
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	resp, err = helpers.CompleteChecksumUpload(rawClient, bucket, "key1", uploadID, a, []helpers.ChecksumPart{
+		{Number: 1, ETag: resp.Header.Get("ETag"), Checksum: a.Checksum([]byte("baz"))},
+	})
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("InvalidPart"))
+	suite.assertNotFound(bucket, "key1")
+
+	_, err = helpers.AbortMultiPartUpload(svc, bucket, "key1", uploadID)
+	assert.Nil(err)
+}
+
+func (suite *ObjectSuite) TestChecksumTrailerChunked() {
+
+	/*
+		Resource : object, method: put
+		Scenario : aws-chunked unsigned payload with the checksum in a trailer, for each algorithm.
+		Assertion: succeeds, the object holds the decoded content and reports the checksum.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	content := strings.Repeat("trailer content ", 1000)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, a := range helpers.ChecksumAlgorithms {
+		key := "key-" + strings.ToLower(string(a))
+		checksum := a.Checksum([]byte(content))
+
+		resp, err := rawClient.Do(helpers.NewTrailerPut(bucket, key, content, 4096, a, checksum))
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, resp.Status, string(a))
+
+		get := suite.checksumRead("GET", bucket, key)
+		assert.Equal(content, string(get.Body), string(a))
+		assert.Equal(checksum, get.Header.Get(a.Header()), string(a))
+		assert.Equal("", get.Header.Get("Content-Encoding"), string(a))
+	}
+}
+
+func (suite *ObjectSuite) TestChecksumTrailerWrong() {
+
+	/*
+		Resource : object, method: put
+		Scenario : aws-chunked upload whose trailer checksum belongs to other content.
+		Assertion: fails with BadDigest and nothing is stored.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	a := helpers.ChecksumCRC32C
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewTrailerPut(bucket, "key1", "hello world", 4, a, a.Checksum([]byte("hello"))))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("BadDigest"))
+	suite.assertNotFound(bucket, "key1")
+}
@@ -0,0 +1,440 @@
+package s3test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// conditionalTarget writes key with content and returns its etag and
+// last-modified time as the gateway reports them.
+func (suite *baseSuite) conditionalTarget(bucket string, key string, content string) (string, time.Time) {
+
+	err := helpers.PutObjectToBucket(svc, bucket, key, content)
+	suite.Require().Nil(err)
+
+	return suite.objectVersion(bucket, key)
+}
+
+// objectVersion returns the etag and last-modified time of key.
+func (suite *baseSuite) objectVersion(bucket string, key string) (string, time.Time) {
+
+	head, err := helpers.HeadObject(svc, bucket, key)
+	suite.Require().Nil(err)
+
+	return aws.StringValue(head.ETag), aws.TimeValue(head.LastModified)
+}
+
+// runReadMatrix sends every header combination to op against bucket/foo,
+// which the requests do not modify.
+func (suite *baseSuite) runReadMatrix(op helpers.ConditionalOp, bucket string) {
+
+	assert := suite
+	etag, modified := suite.objectVersion(bucket, "foo")
+
+	for _, c := range helpers.ConditionCases() {
+		cond := c.Conditions(etag, modified)
+		status, err := helpers.ConditionalRequest(svc, op, bucket, "foo", "", cond)
+		assert.Nil(err, c.String())
+		assert.Equal(cond.Expect(op, true, etag, modified), status, "%s %s", op, c)
+	}
+}
+
+// runWriteMatrix sends every header combination to op, each against a fresh
+// object, and checks the object changed only when the request succeeded.
+func (suite *baseSuite) runWriteMatrix(op helpers.ConditionalOp) {
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for i, c := range helpers.ConditionCases() {
+		key := fmt.Sprintf("foo-%02d", i)
+		etag, modified := suite.conditionalTarget(bucket, key, "bar")
+		cond := c.Conditions(etag, modified)
+
+		status, err := helpers.ConditionalRequest(svc, op, bucket, key, "zar", cond)
+		assert.Nil(err, c.String())
+		want := cond.Expect(op, true, etag, modified)
+		assert.Equal(want, status, "%s %s", op, c)
+
+		data, err := helpers.GetObject(svc, bucket, key)
+		assert.Nil(err, c.String())
+		if want == http.StatusOK {
+			assert.Equal("zar", data, c.String())
+		} else {
+			assert.Equal("bar", data, c.String())
+		}
+	}
+}
+
+func (suite *ConditionalSuite) TestConditionalMatrixGet() {
+
+	/*
+		Resource : object, method: get
+		Scenario : every combination of If-Match, If-None-Match, If-Modified-Since and If-Unmodified-Since.
+		Assertion: 200, 304 or 412 exactly as the RFC 7232 precedence rules prescribe.
+	*/
+
+	suite.runReadMatrix(helpers.CondGet, suite.target)
+}
+
+func (suite *ConditionalSuite) TestConditionalMatrixHead() {
+
+	/*
+		Resource : object, method: head
+		Scenario : every combination of the four precondition headers.
+		Assertion: 200, 304 or 412 exactly as the RFC 7232 precedence rules prescribe.
+	*/
+
+	suite.runReadMatrix(helpers.CondHead, suite.target)
+}
+
+func (suite *ConditionalSuite) TestConditionalMatrixCopy() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : every combination of the four x-amz-copy-source-if-* headers.
+		Assertion: the copy succeeds or fails with 412 as the RFC 7232 precedence rules prescribe.
+	*/
+
+	// The copies are written next to the source, so not to the fixture.
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	suite.conditionalTarget(bucket, "foo", "bar")
+
+	suite.runReadMatrix(helpers.CondCopy, bucket)
+}
+
+func (suite *ConditionalSuite) TestConditionalMatrixPut() {
+
+	/*
+		Resource : object, method: put
+		Scenario : overwrite an object under every combination of the four precondition headers.
+		Assertion: 200 replaces the data, 412 leaves the previous data in place.
+	*/
+
+	suite.runWriteMatrix(helpers.CondPut)
+}
+
+func (suite *ConditionalSuite) TestConditionalMatrixCompleteMultipart() {
+
+	/*
+		Resource : object, method: multipart upload
+		Scenario : complete an upload over an object under every combination of the four precondition headers.
+		Assertion: 200 replaces the data, 412 leaves the previous data in place.
+	*/
+
+	suite.runWriteMatrix(helpers.CondComplete)
+}
+
+func (suite *ConditionalSuite) TestConditionalWildcards() {
+
+	/*
+		Resource : object, method: get, head, put, multipart upload
+		Scenario : If-Match: * and If-None-Match: * against existing and missing objects,
+			including overwriting an existing object w/ If-None-Match: *.
+		Assertion: * matches any existing object and nothing else.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	cases := []struct {
+		op     helpers.ConditionalOp
+		cond   helpers.Conditions
+		exists bool
+	}{
+		{helpers.CondGet, helpers.Conditions{IfMatch: "*"}, true},
+		{helpers.CondGet, helpers.Conditions{IfNoneMatch: "*"}, true},
+		{helpers.CondHead, helpers.Conditions{IfMatch: "*"}, true},
+		{helpers.CondHead, helpers.Conditions{IfNoneMatch: "*"}, true},
+		{helpers.CondPut, helpers.Conditions{IfMatch: "*"}, true},
+		{helpers.CondPut, helpers.Conditions{IfNoneMatch: "*"}, true},
+		{helpers.CondPut, helpers.Conditions{IfNoneMatch: "*"}, false},
+		{helpers.CondComplete, helpers.Conditions{IfMatch: "*"}, true},
+		{helpers.CondComplete, helpers.Conditions{IfNoneMatch: "*"}, true},
+		{helpers.CondComplete, helpers.Conditions{IfNoneMatch: "*"}, false},
+	}
+
+	for i, c := range cases {
+		key := fmt.Sprintf("wildcard-%02d", i)
+		name := fmt.Sprintf("%s %v exists=%v", c.op, c.cond.Headers(), c.exists)
+
+		etag, modified := "", time.Time{}
+		if c.exists {
+			etag, modified = suite.conditionalTarget(bucket, key, "bar")
+		}
+
+		status, err := helpers.ConditionalRequest(svc, c.op, bucket, key, "zar", c.cond)
+		assert.Nil(err, name)
+		want := c.cond.Expect(c.op, c.exists, etag, modified)
+		assert.Equal(want, status, name)
+
+		if c.op == helpers.CondPut || c.op == helpers.CondComplete {
+			data, err := helpers.GetObject(svc, bucket, key)
+			if want == http.StatusOK {
+				assert.Nil(err, name)
+				assert.Equal("zar", data, name)
+			} else if c.exists {
+				assert.Nil(err, name)
+				assert.Equal("bar", data, name)
+			}
+		}
+	}
+}
+
+//...................................... get object with conditions....................
+
+func (suite *ConditionalSuite) TestGetObjectIfmatchGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-Match: the latest ETag
+		Assertion: suceeds.
+		Tags     : smoke
+	*/
+
+	assert := suite
+	bucket := suite.target
+	etag, _ := suite.objectVersion(bucket, "foo")
+
+	got, err := helpers.GetObjectWithIfMatch(svc, bucket, "foo", etag)
+	assert.Nil(err)
+	assert.Equal(got, "bar")
+
+}
+
+func (suite *ConditionalSuite) TestGetObjectIfmatchFailed() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-Match: bogus ETag
+		Assertion: fails.
+	*/
+
+	assert := suite
+	bucket := suite.target
+
+	_, err := helpers.GetObjectWithIfMatch(svc, bucket, "foo", "ABCORZ")
+	assert.NotNil(err)
+	suite.assertS3Error(err, helpers.ExpectError("PreconditionFailed"))
+
+}
+
+func (suite *ConditionalSuite) TestGetObjectIfNoneMatchGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-None-Match: the latest ETag
+		Assertion: fails.
+	*/
+
+	assert := suite
+	bucket := suite.target
+	etag, _ := suite.objectVersion(bucket, "foo")
+
+	_, err := helpers.GetObjectWithIfNoneMatch(svc, bucket, "foo", etag)
+	assert.NotNil(err)
+	suite.assertS3Error(err, helpers.ExpectError("NotModified").WithMessage("^Not Modified$"))
+}
+
+func (suite *ConditionalSuite) TestGetObjectIfNoneMatchFailed() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-None-Match: bogus ETag
+		Assertion: suceeds.
+	*/
+
+	assert := suite
+	bucket := suite.target
+
+	got, err := helpers.GetObjectWithIfNoneMatch(svc, bucket, "foo", "ABCORZ")
+	assert.Nil(err)
+	assert.Equal(got, "bar")
+}
+
+func (suite *ConditionalSuite) TestGetObjectIfModifiedSinceGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-Modified-Since: before
+		Assertion: suceeds.
+	*/
+
+	assert := suite
+	bucket := suite.target
+	_, modified := suite.objectVersion(bucket, "foo")
+	before := modified.Add(-time.Hour)
+
+	got, err := helpers.GetObjectWithIfModifiedSince(svc, bucket, "foo", before)
+	assert.Nil(err)
+	assert.Equal(got, "bar")
+}
+
+func (suite *ConditionalSuite) TestGetObjectIfUnModifiedSinceGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-Unmodified-Since: before
+		Assertion: fails.
+	*/
+
+	assert := suite
+	bucket := suite.target
+	_, modified := suite.objectVersion(bucket, "foo")
+	before := modified.Add(-time.Hour)
+
+	_, err := helpers.GetObjectWithIfUnModifiedSince(svc, bucket, "foo", before)
+	assert.NotNil(err)
+
+	suite.assertS3Error(err, helpers.ExpectError("PreconditionFailed"))
+}
+
+func (suite *ConditionalSuite) TestGetObjectIfUnModifiedSinceFailed() {
+
+	/*
+		Resource : object, method: get
+		Scenario : get w/ If-Unmodified-Since: after
+		Assertion: suceeds.
+	*/
+
+	assert := suite
+	bucket := suite.target
+	future := time.Now().Add(time.Hour * 24 * 3)
+
+	got, err := helpers.GetObjectWithIfUnModifiedSince(svc, bucket, "foo", future)
+	assert.Nil(err)
+	assert.Equal(got, "bar")
+}
+
+//................put object with condition..............................................
+
+func (suite *ConditionalSuite) TestPutObjectIfMatchGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : data re-write w/ If-Match: the latest ETag
+		Assertion: replaces previous data.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	objects := map[string]string{"foo": "bar"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+
+	gotData, err := helpers.GetObject(svc, bucket, "foo")
+	assert.Equal(gotData, "bar")
+
+	object, err := helpers.GetObj(svc, bucket, "foo")
+	err = helpers.PutObjectWithIfMatch(svc, bucket, "foo", "zar", *object.ETag)
+	assert.Nil(err)
+
+	new_data, _ := helpers.GetObject(svc, bucket, "foo")
+	assert.Nil(err)
+	assert.Equal(new_data, "zar")
+}
+
+func (suite *ConditionalSuite) TestPutObjectIfMatchFailed() {
+
+	/*
+		Resource : object, method: get
+		Scenario : data re-write w/ If-Match: outdated ETag
+		Assertion: replaces previous data.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	objects := map[string]string{"key1": "bar"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+
+	gotData, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Equal(gotData, "bar")
+
+	err = helpers.PutObjectWithIfMatch(svc, bucket, "key1", "zar", "ABCORZmmmm")
+
+	oldData, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal(oldData, "zar")
+}
+
+func (suite *ConditionalSuite) TestPutObjectIfmatchNonexistedFailed() {
+
+	/*
+		Resource : object, method: put
+		Scenario : overwrite non-existing object w/ If-Match: *
+		Assertion: fails
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectWithIfMatch(svc, bucket, "foo", "zar", "*")
+	assert.NotNil(err)
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchKey"))
+}
+
+func (suite *ConditionalSuite) TestPutObjectIfNonMatchGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : overwrite existing object w/ If-None-Match: outdated ETag'
+		Assertion: replaces previous data and metadata.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	objects := map[string]string{"foo": "bar"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+
+	gotData, err := helpers.GetObject(svc, bucket, "foo")
+	assert.Equal(gotData, "bar")
+
+	err = helpers.PutObjectWithIfNoneMatch(svc, bucket, "foo", "zar", "ABCORZ")
+	assert.Nil(err)
+
+	new_data, _ := helpers.GetObject(svc, bucket, "foo")
+	assert.Nil(err)
+	assert.Equal(new_data, "zar")
+}
+
+func (suite *ConditionalSuite) TestPutObjectIfNonMatchNonexistedGood() {
+
+	/*
+		Resource : object, method: get
+		Scenario : overwrite non-existing object w/ If-None-Match: *
+		Assertion: succeeds.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+
+	err = helpers.PutObjectWithIfNoneMatch(svc, bucket, "key1", "bar", "*")
+	assert.Nil(err)
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Equal(data, "bar")
+}
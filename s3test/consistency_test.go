@@ -0,0 +1,107 @@
+package s3test
+
+import (
+	"strings"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// assertConsistent runs checks with concurrent writers in a fresh bucket,
+// logs the staleness report and fails on every violation of the configured
+// consistency model.
+func (suite *baseSuite) assertConsistent(checks ...string) {
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	conf, err := helpers.GetConsistencyConfig()
+	suite.Require().Nil(err)
+
+	err = helpers.CreateBucket(svc, bucket)
+	suite.Require().Nil(err)
+
+	report, err := helpers.RunConsistency(svc, bucket, conf, checks...)
+	assert.Nil(err)
+
+	var b strings.Builder
+	report.Report(&b)
+	suite.T().Logf("%s consistency, window %v, %d writers x %d keys\n%s",
+		conf.Model, conf.Window, conf.Writers, conf.Keys, b.String())
+
+	for _, violation := range report.Violations(conf) {
+		suite.Fail(violation)
+	}
+}
+
+func (suite *ResilienceSuite) TestConsistencyReadAfterWrite() {
+
+	/*
+		Resource : object, method: put, get
+		Scenario : concurrent writers GET each new object right after writing it.
+		Assertion: the new content is read within the configured consistency model.
+		Tags     : slow
+	*/
+
+	suite.assertConsistent(helpers.ReadAfterWrite)
+}
+
+func (suite *ResilienceSuite) TestConsistencyReadAfterOverwrite() {
+
+	/*
+		Resource : object, method: put, get
+		Scenario : concurrent writers overwrite each object and GET it right away.
+		Assertion: the new content, not the old, is read within the configured consistency model.
+		Tags     : slow
+	*/
+
+	suite.assertConsistent(helpers.ReadAfterOverwrite)
+}
+
+func (suite *ResilienceSuite) TestConsistencyReadAfterDelete() {
+
+	/*
+		Resource : object, method: delete, get
+		Scenario : concurrent writers delete each object and GET it right away.
+		Assertion: NoSuchKey is returned within the configured consistency model.
+		Tags     : slow
+	*/
+
+	suite.assertConsistent(helpers.ReadAfterDelete)
+}
+
+func (suite *ResilienceSuite) TestConsistencyListAfterWrite() {
+
+	/*
+		Resource : object, method: list
+		Scenario : concurrent writers list each new object right after writing it.
+		Assertion: the key is listed within the configured consistency model.
+		Tags     : slow
+	*/
+
+	suite.assertConsistent(helpers.ListAfterWrite)
+}
+
+func (suite *ResilienceSuite) TestConsistencyListAfterDelete() {
+
+	/*
+		Resource : object, method: list
+		Scenario : concurrent writers list each object right after deleting it.
+		Assertion: the key is no longer listed within the configured consistency model.
+		Tags     : slow
+	*/
+
+	suite.assertConsistent(helpers.ListAfterDelete)
+}
+
+func (suite *ResilienceSuite) TestConsistencyAllChecks() {
+
+	/*
+		Resource : object, method: put, get, delete, list
+		Scenario : concurrent writers take every key through write, overwrite and
+			delete, reading and listing after each step.
+		Assertion: every check holds within the configured consistency model.
+		Tags     : slow
+	*/
+
+	suite.assertConsistent()
+}
@@ -0,0 +1,379 @@
+package s3test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+func (suite *ObjectSuite) TestCopyObjectMetadataDirectiveCopy() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy with the default (COPY) metadata directive.
+		Assertion: content type and user metadata of the source are kept.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	metadata := map[string]string{"foo": "bar", "color": "blue"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	_, err = helpers.PutObjectWithMetadata(svc, bucket, "src", "content", "text/plain", metadata)
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "src", bucket, "dst", helpers.CopyMetadata("COPY", map[string]string{"ignored": "yes"}))
+	assert.Nil(err)
+
+	head, err := helpers.HeadObject(svc, bucket, "dst")
+	assert.Nil(err)
+	assert.Equal("text/plain", aws.StringValue(head.ContentType))
+	assert.Equal(metadata, helpers.NormalizeMetadata(head.Metadata))
+
+	data, err := helpers.GetObject(svc, bucket, "dst")
+	assert.Nil(err)
+	assert.Equal("content", data)
+}
+
+func (suite *ObjectSuite) TestCopyObjectMetadataDirectiveReplace() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy with the REPLACE metadata directive.
+		Assertion: the destination carries only the new metadata and content type.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	replaced := map[string]string{"mood": "happy"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	_, err = helpers.PutObjectWithMetadata(svc, bucket, "src", "content", "text/plain", map[string]string{"foo": "bar"})
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "src", bucket, "dst",
+		helpers.CopyMetadata("REPLACE", replaced), helpers.CopyContentType("application/json"))
+	assert.Nil(err)
+
+	head, err := helpers.HeadObject(svc, bucket, "dst")
+	assert.Nil(err)
+	assert.Equal("application/json", aws.StringValue(head.ContentType))
+	assert.Equal(replaced, helpers.NormalizeMetadata(head.Metadata))
+
+	head, err = helpers.HeadObject(svc, bucket, "src")
+	assert.Nil(err)
+	assert.Equal(map[string]string{"foo": "bar"}, helpers.NormalizeMetadata(head.Metadata))
+}
+
+func (suite *ObjectSuite) TestCopyObjectTaggingDirective() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy with tagging directive COPY, then REPLACE.
+		Assertion: COPY keeps the source tags, REPLACE stores only the new ones.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	tags := map[string]string{"project": "s3tests", "env": "ci"}
+	replaced := map[string]string{"owner": "copy"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectWithTagging(svc, bucket, "src", "content", tags)
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "src", bucket, "copied", helpers.CopyTagging("COPY", nil))
+	assert.Nil(err)
+
+	got, err := helpers.GetObjectTags(svc, bucket, "copied")
+	assert.Nil(err)
+	assert.Equal(tags, got)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "src", bucket, "replaced", helpers.CopyTagging("REPLACE", replaced))
+	assert.Nil(err)
+
+	got, err = helpers.GetObjectTags(svc, bucket, "replaced")
+	assert.Nil(err)
+	assert.Equal(replaced, got)
+}
+
+func (suite *ObjectSuite) TestCopyObjectOntoItselfNoChange() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy an object onto itself without changing anything.
+		Assertion: rejected with InvalidRequest, the object is untouched.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	_, err = helpers.PutObjectWithMetadata(svc, bucket, "key", "content", "", map[string]string{"foo": "bar"})
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "key", bucket, "key")
+	suite.assertS3Error(err, helpers.ExpectError("InvalidRequest"))
+
+	head, err := helpers.HeadObject(svc, bucket, "key")
+	assert.Nil(err)
+	assert.Equal(map[string]string{"foo": "bar"}, helpers.NormalizeMetadata(head.Metadata))
+}
+
+func (suite *ObjectSuite) TestCopyObjectOntoItselfReplaceMetadata() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy an object onto itself with the REPLACE metadata directive.
+		Assertion: the metadata changes and the content stays the same.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	replaced := map[string]string{"foo": "baz", "new": "value"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	_, err = helpers.PutObjectWithMetadata(svc, bucket, "key", "content", "", map[string]string{"foo": "bar"})
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "key", bucket, "key", helpers.CopyMetadata("REPLACE", replaced))
+	assert.Nil(err)
+
+	head, err := helpers.HeadObject(svc, bucket, "key")
+	assert.Nil(err)
+	assert.Equal(replaced, helpers.NormalizeMetadata(head.Metadata))
+
+	data, err := helpers.GetObject(svc, bucket, "key")
+	assert.Nil(err)
+	assert.Equal("content", data)
+}
+
+func (suite *ObjectSuite) TestCopyObjectSourceConditions() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy guarded by x-amz-copy-source-if-* headers.
+		Assertion: satisfied conditions copy, failed ones return PreconditionFailed.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	put, err := helpers.PutObjectWithMetadata(svc, bucket, "src", "content", "", nil)
+	assert.Nil(err)
+	etag := aws.StringValue(put.ETag)
+
+	head, err := helpers.HeadObject(svc, bucket, "src")
+	assert.Nil(err)
+	before := head.LastModified.Add(-24 * time.Hour)
+	after := head.LastModified.Add(24 * time.Hour)
+
+	cases := []struct {
+		name string
+		opt  helpers.CopyOption
+		code string
+	}{
+		{"if-match", helpers.CopyIfMatch(etag), ""},
+		{"if-match-fail", helpers.CopyIfMatch(`"ABCORZ"`), "PreconditionFailed"},
+		{"if-none-match", helpers.CopyIfNoneMatch(`"ABCORZ"`), ""},
+		{"if-none-match-fail", helpers.CopyIfNoneMatch(etag), "PreconditionFailed"},
+		{"if-modified-since", helpers.CopyIfModifiedSince(before), ""},
+		{"if-modified-since-fail", helpers.CopyIfModifiedSince(after), "PreconditionFailed"},
+		{"if-unmodified-since", helpers.CopyIfUnmodifiedSince(after), ""},
+		{"if-unmodified-since-fail", helpers.CopyIfUnmodifiedSince(before), "PreconditionFailed"},
+	}
+
+	for _, c := range cases {
+		_, err = helpers.CopyObjectWith(svc, bucket, "src", bucket, c.name, c.opt)
+		if c.code == "" {
+			assert.Nil(err, c.name)
+			data, err := helpers.GetObject(svc, bucket, c.name)
+			assert.Nil(err, c.name)
+			assert.Equal("content", data, c.name)
+		} else {
+			suite.assertS3Error(err, helpers.ExpectError(c.code))
+			suite.assertNotFound(bucket, c.name)
+		}
+	}
+}
+
+func (suite *ObjectSuite) TestCopyObjectMultipartSource() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy an object that was created by a multipart upload.
+		Assertion: the copy has the full content and size of the source.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	payloads := []string{strings.Repeat("a", 5*1024*1024), strings.Repeat("b", 1024)}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	_, err = helpers.MultipartUploadParts(svc, bucket, "mymultipart", payloads)
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "mymultipart", bucket, "copy")
+	assert.Nil(err)
+
+	head, err := helpers.HeadObject(svc, bucket, "copy")
+	assert.Nil(err)
+	assert.Equal(int64(len(payloads[0])+len(payloads[1])), aws.Int64Value(head.ContentLength))
+
+	data, err := helpers.GetObject(svc, bucket, "copy")
+	assert.Nil(err)
+	assert.True(data == payloads[0]+payloads[1])
+}
+
+func (suite *ObjectSuite) TestCopyObjectZeroByte() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy a zero-byte object.
+		Assertion: the copy exists and is empty.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectToBucket(svc, bucket, "empty", "")
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "empty", bucket, "copy")
+	assert.Nil(err)
+
+	head, err := helpers.HeadObject(svc, bucket, "copy")
+	assert.Nil(err)
+	assert.Equal(int64(0), aws.Int64Value(head.ContentLength))
+
+	data, err := helpers.GetObject(svc, bucket, "copy")
+	assert.Nil(err)
+	assert.Equal("", data)
+}
+
+func (suite *ObjectSuite) TestCopyObjectEncodedKeys() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy source keys that need URL-encoding in x-amz-copy-source.
+		Assertion: each key is copied to the same key in another bucket.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	other := suite.bucketName()
+	keys := []string{"a b", "a+b", "a%2Bb", "dir/sub dir/file", "foo?bar&baz=1", "ünïcødé", "#hash", "~tilde"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, other)
+	assert.Nil(err)
+
+	for _, key := range keys {
+		err = helpers.PutObjectToBucket(svc, bucket, key, key)
+		assert.Nil(err, key)
+
+		_, err = helpers.CopyObjectWith(svc, bucket, key, other, key)
+		assert.Nil(err, key)
+
+		data, err := helpers.GetObject(svc, other, key)
+		assert.Nil(err, key)
+		assert.Equal(key, data)
+	}
+}
+
+func (suite *ObjectSuite) TestCopyObjectAcrossBuckets() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy between two buckets of the same owner.
+		Assertion: the copy matches the source and the source is kept.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	other := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, other)
+	assert.Nil(err)
+
+	put, err := helpers.PutObjectWithMetadata(svc, bucket, "src", "content", "", nil)
+	assert.Nil(err)
+
+	out, err := helpers.CopyObjectWith(svc, bucket, "src", other, "dst")
+	assert.Nil(err)
+	assert.Equal(aws.StringValue(put.ETag), aws.StringValue(out.CopyObjectResult.ETag))
+
+	data, err := helpers.GetObject(svc, other, "dst")
+	assert.Nil(err)
+	assert.Equal("content", data)
+
+	data, err = helpers.GetObject(svc, bucket, "src")
+	assert.Nil(err)
+	assert.Equal("content", data)
+}
+
+func (suite *ObjectSuite) TestCopyObjectAcrossUsers() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : the s3alt user copies from and into buckets of the main user.
+		Assertion: a private source is AccessDenied, a public-read source copies,
+			and writing into a bucket the alt user does not own is AccessDenied.
+	*/
+
+	if !helpers.HasAltUser() {
+		suite.T().Skip("s3alt user is not configured")
+	}
+
+	assert := suite
+	bucket := suite.bucketName()
+	altBucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(altSvc, altBucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectToBucket(svc, bucket, "private", "secret")
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "public", "hello")
+	assert.Nil(err)
+	err = helpers.SetObjectACL(svc, bucket, "public", "public-read")
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(altSvc, bucket, "private", altBucket, "private")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	_, err = helpers.CopyObjectWith(altSvc, bucket, "public", altBucket, "public")
+	assert.Nil(err)
+
+	data, err := helpers.GetObject(altSvc, altBucket, "public")
+	assert.Nil(err)
+	assert.Equal("hello", data)
+
+	_, err = helpers.CopyObjectWith(altSvc, altBucket, "public", bucket, "from-alt")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+}
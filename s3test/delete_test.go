@@ -0,0 +1,290 @@
+package s3test
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// deleteKeys returns n distinct keys.
+func deleteKeys(n int) []string {
+
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%04d", i)
+	}
+
+	return keys
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsExistingAndMissing() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : delete a list mixing existing and never-written keys.
+		Assertion: every key is reported deleted, no errors, other objects are untouched.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateObjects(svc, bucket, map[string]string{"foo": "echo", "bar": "lima", "keep": "golf"})
+	assert.Nil(err)
+
+	out, err := helpers.DeleteKeys(svc, bucket, []string{"foo", "bar", "missing"}, false)
+	assert.Nil(err)
+	deleted := helpers.DeletedKeys(out)
+	sort.Strings(deleted)
+	assert.Equal([]string{"bar", "foo", "missing"}, deleted)
+	assert.Equal(0, len(out.Errors))
+
+	_, keys, err := helpers.GetKeys(svc, bucket)
+	assert.Nil(err)
+	assert.Equal([]string{"keep"}, keys)
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsQuiet() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : delete in quiet mode.
+		Assertion: the objects are gone and the response lists nothing.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateObjects(svc, bucket, map[string]string{"foo": "echo", "bar": "lima"})
+	assert.Nil(err)
+
+	out, err := helpers.DeleteKeys(svc, bucket, []string{"foo", "bar"}, true)
+	assert.Nil(err)
+	assert.Equal(0, len(out.Deleted))
+	assert.Equal(0, len(out.Errors))
+
+	_, keys, err := helpers.GetKeys(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(0, len(keys))
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsKeyLimit() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : delete exactly 1000 keys, then 1001.
+		Assertion: 1000 succeeds with 1000 results; 1001 fails with MalformedXML and deletes nothing.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	keys := deleteKeys(helpers.MaxDeleteKeys + 1)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, keys[0], "bar")
+	assert.Nil(err)
+
+	_, err = helpers.DeleteKeys(svc, bucket, keys, false)
+	suite.assertS3Error(err, helpers.ExpectError("MalformedXML"))
+
+	data, err := helpers.GetObject(svc, bucket, keys[0])
+	assert.Nil(err)
+	assert.Equal("bar", data)
+
+	out, err := helpers.DeleteKeys(svc, bucket, keys[:helpers.MaxDeleteKeys], false)
+	assert.Nil(err)
+	assert.Equal(helpers.MaxDeleteKeys, len(out.Deleted))
+	assert.Equal(0, len(out.Errors))
+	suite.assertNotFound(bucket, keys[0])
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsEmptyList() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : Delete document without any Object.
+		Assertion: fails with MalformedXML.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewDeleteRequest(bucket, helpers.DeleteXML(false), true))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("MalformedXML"))
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsContentMD5() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : multi-delete without Content-MD5, with a wrong one, then a correct one.
+		Assertion: missing is InvalidRequest, wrong is BadDigest, both delete nothing; correct succeeds.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	body := helpers.DeleteXML(false, "key1")
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewDeleteRequest(bucket, body, false))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("InvalidRequest"))
+
+	resp, err = rawClient.Do(helpers.NewDeleteRequest(bucket, body, false).Header("Content-MD5", helpers.ContentMD5("other")))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("BadDigest"))
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+
+	resp, err = rawClient.Do(helpers.NewDeleteRequest(bucket, body, true))
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	suite.assertNotFound(bucket, "key1")
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsMalformedXML() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : multi-delete bodies that are truncated, not XML, or the wrong document.
+		Assertion: each fails with MalformedXML and the object survives.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	for _, body := range []string{
+		"<Delete><Object><Key>key1</Key>",
+		"key1",
+		"<Remove><Object><Key>key1</Key></Object></Remove>",
+		"<Delete><Object><Version>1</Version></Object></Delete>",
+	} {
+		resp, err := rawClient.Do(helpers.NewDeleteRequest(bucket, body, true))
+		assert.Nil(err)
+		if diff := helpers.CheckRawError(resp, helpers.ExpectError("MalformedXML")); diff != "" {
+			suite.Fail(diff, body)
+		}
+	}
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsVersioned() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : multi-delete in a versioned bucket, with and without VersionId.
+		Assertion: a VersionId removes that version only; no VersionId adds a delete
+			marker; deleting the marker by VersionId restores the object.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.EnableVersioning(svc, bucket)
+	assert.Nil(err)
+
+	first, err := helpers.PutObjectWithMetadata(svc, bucket, "key1", "one", "", nil)
+	assert.Nil(err)
+	_, err = helpers.PutObjectWithMetadata(svc, bucket, "key1", "two", "", nil)
+	assert.Nil(err)
+
+	out, err := helpers.DeleteObjectIDs(svc, bucket, []*s3.ObjectIdentifier{
+		{Key: aws.String("key1"), VersionId: first.VersionId},
+	}, false)
+	assert.Nil(err)
+	if assert.Equal(1, len(out.Deleted)) {
+		assert.Equal(aws.StringValue(first.VersionId), aws.StringValue(out.Deleted[0].VersionId))
+		assert.False(aws.BoolValue(out.Deleted[0].DeleteMarker))
+	}
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("two", data)
+
+	out, err = helpers.DeleteKeys(svc, bucket, []string{"key1"}, false)
+	assert.Nil(err)
+	suite.Require().Equal(1, len(out.Deleted))
+	marker := out.Deleted[0]
+	assert.True(aws.BoolValue(marker.DeleteMarker))
+	assert.NotEqual("", aws.StringValue(marker.DeleteMarkerVersionId))
+
+	_, err = helpers.GetObject(svc, bucket, "key1")
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchKey"))
+
+	out, err = helpers.DeleteObjectIDs(svc, bucket, []*s3.ObjectIdentifier{
+		{Key: aws.String("key1"), VersionId: marker.DeleteMarkerVersionId},
+	}, false)
+	assert.Nil(err)
+	assert.Equal(1, len(out.Deleted))
+
+	data, err = helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("two", data)
+}
+
+func (suite *ObjectSuite) TestDeleteObjectsPerKeyErrors() {
+
+	/*
+		Resource : object, method: delete objects
+		Scenario : the alt user multi-deletes from a private bucket of the main user.
+		Assertion: the request succeeds but every key is an AccessDenied entry in
+			Errors, in quiet mode too, and nothing is deleted.
+	*/
+
+	assert := suite
+	if !helpers.HasAltUser() {
+		suite.T().Skip("s3alt user is not configured")
+	}
+	bucket := suite.bucketName()
+	keys := []string{"foo", "bar"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateObjects(svc, bucket, map[string]string{"foo": "echo", "bar": "lima"})
+	assert.Nil(err)
+
+	for _, quiet := range []bool{false, true} {
+		out, err := helpers.DeleteKeys(altSvc, bucket, keys, quiet)
+		assert.Nil(err)
+		assert.Equal(0, len(out.Deleted))
+		if assert.Equal(len(keys), len(out.Errors), "quiet=%v", quiet) {
+			for _, e := range out.Errors {
+				assert.True(helpers.Contains(keys, aws.StringValue(e.Key)))
+				assert.Equal("AccessDenied", aws.StringValue(e.Code))
+				assert.NotEqual("", aws.StringValue(e.Message))
+			}
+		}
+	}
+
+	_, listed, err := helpers.GetKeys(svc, bucket)
+	assert.Nil(err)
+	assert.Equal([]string{"bar", "foo"}, listed)
+}
@@ -0,0 +1,553 @@
+package s3test
+
+//..............................................SSE-C encrypted transfer....................................................
+
+// func (suite *EncryptionSuite) TestEncryptedTransfer1B() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 1byte
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.EncryptionSSECustomerWrite(svc, 1)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+// 		assert.NotNil(awsErr)
+// 	} else {
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptedTransfer1KB() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 1KB
+// 		Assertion: success.
+// 	*/
+// 	assert := suite
+
+// 	rdata, data, err := helpers.EncryptionSSECustomerWrite(svc, 1024)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptedTransfer1MB() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 1MB
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.EncryptionSSECustomerWrite(svc, 1024*1024)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptedTransfer13B() {
+
+// 	// Resource : object, method: put
+// 	// Scenario : Test SSE-C encrypted transfer 13 bytes
+// 	// Assertion: success.
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.EncryptionSSECustomerWrite(svc, 13)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptionSSECPresent() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : write encrypted with SSE-C and read without SSE-C
+// 		Assertion: fails.
+// 	*/
+// 	assert := suite
+
+// 	data := strings.Repeat("A", 10)
+// 	key := "testobj"
+// 	bucket := suite.bucketName()
+// 	sse := []string{"AES256", "pO3upElrwuEXSoFwCfnZPdSsmt/xWeFa0N9KgDijwVs=", "DWygnHRtgiJ77HCm+1rvHw=="}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSECEcrypted(svc, bucket, key, data, sse)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		_, err = helpers.GetObjects(svc, bucket)
+// 		assert.NotNil(err)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptionSSECOtherKey() {
+
+// 	/*
+// 		Resource : object, method: put/get
+// 		Scenario : write encrypted with SSE-C but read with other key
+// 		Assertion: fails.
+// 	*/
+
+// 	assert := suite
+
+// 	data := strings.Repeat("A", 10)
+// 	key := "testobj"
+// 	bucket := suite.bucketName()
+// 	sse0 := []string{"AES256", "pO3upElrwuEXSoFwCfnZPdSsmt/xWeFa0N9KgDijwVs=", "DWygnHRtgiJ77HCm+1rvHw=="}
+// 	sse1 := []string{"AES256", "6b+WOZ1T3cqZMxgThRcXAQBrS5mXKdDUphvpxptl9/4=", "arxBvwY2V4SiOne6yppVPQ=="}
+
+// 	_ = helpers.CreateBucket(svc, bucket)
+
+// 	err := helpers.WriteSSECEcrypted(svc, bucket, key, data, sse0)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		_, err = helpers.ReadSSECEcrypted(svc, bucket, key, sse1)
+// 		assert.NotNil(err)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptionSSECInvalidMd5() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : write encrypted with SSE-C, but md5 is bad
+// 		Assertion: fails.
+// 	*/
+
+// 	assert := suite
+
+// 	data := strings.Repeat("A", 10)
+// 	key := "testobj"
+// 	bucket := suite.bucketName()
+// 	sse := []string{"AES256", "pO3upElrwuEXSoFwCfnZPdSsmt/xWeFa0N9KgDijwVs=", "AAAAAAAAAAAAAAAAAAAAAA=="}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSECEcrypted(svc, bucket, key, data, sse)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		_, err = helpers.GetObjects(svc, bucket)
+// 		assert.NotNil(err)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptionSSECNoMd5() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : write encrypted with SSE-C, but dont provide MD5'
+// 		Assertion: fails.
+// 	*/
+
+// 	assert := suite
+
+// 	data := strings.Repeat("A", 10)
+// 	key := "testobj"
+// 	bucket := suite.bucketName()
+// 	sse := []string{"AES256", "pO3upElrwuEXSoFwCfnZPdSsmt/xWeFa0N9KgDijwVs=", " "}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSECEcrypted(svc, bucket, key, data, sse)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		_, err = helpers.GetObjects(svc, bucket)
+// 		assert.NotNil(err)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptionSSECNoKey() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : declare SSE-C but do not provide key'
+// 		Assertion: fails.
+// 	*/
+
+// 	assert := suite
+
+// 	data := strings.Repeat("A", 10)
+// 	key := "testobj"
+// 	bucket := suite.bucketName()
+// 	sse := []string{"AES256", " ", " "}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSECEcrypted(svc, bucket, key, data, sse)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		_, err = helpers.GetObjects(svc, bucket)
+// 		assert.NotNil(err)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestEncryptionKeyNoSSEC() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : 'Do not declare SSE-C but provide key and MD5
+// 		Assertion: operation successfull, no encryption.
+// 	*/
+
+// 	assert := suite
+
+// 	data := strings.Repeat("A", 10)
+// 	key := "testobj"
+// 	bucket := suite.bucketName()
+// 	sse := []string{" ", "pO3upElrwuEXSoFwCfnZPdSsmt/xWeFa0N9KgDijwVs=", "DWygnHRtgiJ77HCm+1rvHw=="}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSECEcrypted(svc, bucket, key, data, sse)
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		_, err = helpers.GetObjects(svc, bucket)
+// 		assert.Nil(err)
+
+// 	}
+
+// }
+
+//.................................SSE and KMS......................................................................
+
+// func (suite *EncryptionSuite) TestSSEKMSbarbTransfer13B() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 13 bytes
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSkeyIdCustomerWrite(svc, 13)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+
+// }
+
+// func (suite *EncryptionSuite) TestSSEKMSbarbTransfer1MB() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 13 bytes
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSkeyIdCustomerWrite(svc, 1024*1024)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+
+// }
+
+// func (suite *EncryptionSuite) TestSSEKMSbarbTransfer1KB() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 13 bytes
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSkeyIdCustomerWrite(svc, 1024)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestSSEKMSbarbTransfer1B() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-C encrypted transfer 13 bytes
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSkeyIdCustomerWrite(svc, 1)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+
+// }
+
+// func (suite *EncryptionSuite) TestSSEKMSTransfer13B() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-KMS encrypted transfer 13 bytes
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSCustomerWrite(svc, 13)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestSSEKMSTransfer1MB() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-KMS encrypted transfer 1 mega byte
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSCustomerWrite(svc, 1024*1024)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+// }
+
+// func (suite *EncryptionSuite) TestSSEKMSTransfer1KB() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-KMS encrypted transfer 1 kilobyte
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSCustomerWrite(svc, 1024)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+
+// }
+
+// TODO:
+// func (suite *EncryptionSuite) TestSSEKMSTransfer1B() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : Test SSE-KMS encrypted transfer 1 byte
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	rdata, data, err := helpers.SSEKMSCustomerWrite(svc, 1)
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		assert.Equal(rdata, data)
+
+// 	}
+
+// }
+
+// TODO:
+// func (suite *EncryptionSuite) TestSSEKMSPresent() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : write encrypted with SSE-KMS and read without SSE-KMS
+// 		Assertion: success.
+// 	*/
+
+// 	assert := suite
+
+// 	bucket := suite.bucketName()
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSEKMSkeyId(svc, bucket, "kay1", "test", viper.GetString("s3main.SSE"), viper.GetString("s3main.kmskeyid"))
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+
+// 	} else {
+
+// 		assert.Nil(err)
+// 		data, _ := helpers.GetObject(svc, bucket, "kay1")
+
+// 		assert.Equal("test", data)
+// 	}
+
+// }
+
+//TODO:
+// func (suite *EncryptionSuite) TestSSEKMSNoKey() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : declare SSE-KMS but do not provide key_id'
+// 		Assertion: fails.
+// 	*/
+
+// 	assert := suite
+
+// 	bucket := suite.bucketName()
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSEKMSkeyId(svc, bucket, "kay1", "test", viper.GetString("s3main.SSE"), "")
+
+// 	if awsErr, ok := err.(awserr.Error); ok {
+// 		assert.NotNil(awsErr)
+// 		assert.Equal("InvalidAccessKeyId", awsErr.Code())
+// 	} else {
+
+// 		assert.NotNil(err)
+// 	}
+
+// }
+
+// TODO:
+// func (suite *EncryptionSuite) TestSSEKMSNotDeclared() {
+
+// 	/*
+// 		Resource : object, method: put
+// 		Scenario : dDo not declare SSE-KMS but provide key_id
+// 		Assertion: fails if either the aws:kms or key_id is not declared
+// 	*/
+
+// 	assert := suite
+
+// 	bucket := suite.bucketName()
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	err = helpers.WriteSSEKMSkeyId(svc, bucket, "kay1", "test", "", viper.GetString("s3main.kmskeyid"))
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+// 	}
+// 	err = helpers.WriteSSEKMSkeyId(svc, bucket, "kay1", "test", viper.GetString("s3main.SSE"), "")
+// 	if awsErr, ok := err.(awserr.Error); ok {
+
+// 		assert.NotNil(awsErr)
+// 	}
+// }
@@ -0,0 +1,307 @@
+package s3test
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+func (suite *baseSuite) newFaultProxy() *helpers.FaultProxy {
+
+	proxy, err := helpers.NewFaultProxyForConfig()
+	suite.Require().Nil(err)
+
+	return proxy
+}
+
+func (suite *baseSuite) assertNotFound(bucket string, key string) {
+
+	_, err := svc.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	suite.assertS3Error(err, helpers.ExpectError("NotFound"))
+}
+
+func (suite *ResilienceSuite) TestFaultResetPutNotExposed() {
+
+	/*
+		Resource : object, method: put
+		Scenario : connection reset halfway through the request body of a new object.
+		Assertion: the put fails and no partial object becomes visible.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "reset"
+	content := strings.Repeat("12345", 1024*1024)
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.ResetRequestAfter(int64(len(content)/2)).On("PUT", ""))
+
+	err = helpers.PutObjectToBucket(client, bucket, key, content)
+	assert.NotNil(err)
+
+	time.Sleep(time.Second)
+	suite.assertNotFound(bucket, key)
+}
+
+func (suite *ResilienceSuite) TestFaultResetOverwriteKeepsOldObject() {
+
+	/*
+		Resource : object, method: put
+		Scenario : connection reset halfway through overwriting an existing object.
+		Assertion: the put fails and the previous content is still returned whole.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "reset"
+	content := strings.Repeat("67890", 1024*1024)
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, key, "original")
+	assert.Nil(err)
+
+	proxy.Inject(helpers.ResetRequestAfter(int64(len(content)/2)).On("PUT", ""))
+
+	err = helpers.PutObjectToBucket(client, bucket, key, content)
+	assert.NotNil(err)
+
+	time.Sleep(time.Second)
+	data, err := helpers.GetObject(svc, bucket, key)
+	assert.Nil(err)
+	assert.Equal("original", data)
+}
+
+func (suite *ResilienceSuite) TestFaultRetriedUploadPartIdempotent() {
+
+	/*
+		Resource : object, method: multipart upload
+		Scenario : first UploadPart attempt is reset mid-body, the SDK retries it.
+		Assertion: a single part is listed and the completed object is intact.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "mymultipart"
+	payload := strings.Repeat("12345", 1024*1024)
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(3)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	result, err := helpers.InitiateMultipartUpload(svc, bucket, key)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.ResetRequestAfter(1024).On("PUT", "partNumber=").Once())
+
+	part, err := helpers.Uploadpart(client, bucket, key, *result.UploadId, payload, 1)
+	assert.Nil(err)
+	assert.True(len(proxy.Requests()) >= 2)
+
+	parts, err := helpers.Listparts(svc, bucket, key, *result.UploadId)
+	assert.Nil(err)
+	assert.Equal(1, len(parts.Parts))
+	assert.Equal(*part.ETag, *parts.Parts[0].ETag)
+	assert.Equal(int64(len(payload)), *parts.Parts[0].Size)
+
+	_, err = helpers.CompleteMultiUpload(svc, bucket, key, 1, *result.UploadId, *part.ETag)
+	assert.Nil(err)
+
+	data, err := helpers.GetObject(svc, bucket, key)
+	assert.Nil(err)
+	assert.True(data == payload)
+}
+
+func (suite *ResilienceSuite) TestFaultDuplicatedUploadPartIdempotent() {
+
+	/*
+		Resource : object, method: multipart upload
+		Scenario : every UploadPart request reaches the gateway twice.
+		Assertion: each part is stored once with the same etag and the object is intact.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "mymultipart"
+	payloads := []string{strings.Repeat("a", 5*1024*1024), strings.Repeat("b", 1024)}
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	result, err := helpers.InitiateMultipartUpload(svc, bucket, key)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.DuplicateRequest().On("PUT", "partNumber="))
+
+	completed := []*s3.CompletedPart{}
+	for i, payload := range payloads {
+		part, err := helpers.Uploadpart(client, bucket, key, *result.UploadId, payload, int64(i+1))
+		assert.Nil(err)
+		completed = append(completed, &s3.CompletedPart{ETag: part.ETag, PartNumber: aws.Int64(int64(i + 1))})
+	}
+
+	parts, err := helpers.Listparts(svc, bucket, key, *result.UploadId)
+	assert.Nil(err)
+	assert.Equal(len(payloads), len(parts.Parts))
+	for i, p := range parts.Parts {
+		assert.Equal(*completed[i].ETag, *p.ETag)
+	}
+
+	_, err = svc.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        result.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	assert.Nil(err)
+
+	data, err := helpers.GetObject(svc, bucket, key)
+	assert.Nil(err)
+	assert.True(data == payloads[0]+payloads[1])
+}
+
+func (suite *ResilienceSuite) TestFaultContentLengthLongerThanBody() {
+
+	/*
+		Resource : object, method: put
+		Scenario : Content-Length declares more bytes than the body carries.
+		Assertion: rejected, nothing stored.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "short-body"
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.DeclareContentLength(10).On("PUT", ""))
+
+	err = helpers.PutObjectToBucket(client, bucket, key, "bar")
+	assert.NotNil(err)
+
+	suite.assertNotFound(bucket, key)
+}
+
+func (suite *ResilienceSuite) TestFaultContentLengthShorterThanBody() {
+
+	/*
+		Resource : object, method: put
+		Scenario : Content-Length declares fewer bytes than the signed body.
+		Assertion: rejected, the truncated body is not stored.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "long-body"
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.DeclareContentLength(-1).On("PUT", ""))
+
+	err = helpers.PutObjectToBucket(client, bucket, key, "barbaz")
+	assert.NotNil(err)
+
+	suite.assertNotFound(bucket, key)
+}
+
+func (suite *ResilienceSuite) TestFaultSlowResponseNeverPartial() {
+
+	/*
+		Resource : object, method: put
+		Scenario : the put response is delayed past the client timeout.
+		Assertion: the client fails, and the object is either absent or complete.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "slow"
+	content := strings.Repeat("slow", 1024)
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+	client.Config.HTTPClient = &http.Client{Timeout: 500 * time.Millisecond}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.DelayResponse(2*time.Second).On("PUT", ""))
+
+	err = helpers.PutObjectToBucket(client, bucket, key, content)
+	assert.NotNil(err)
+
+	time.Sleep(3 * time.Second)
+	data, err := helpers.GetObject(svc, bucket, key)
+	if err == nil {
+		assert.True(data == content)
+	} else {
+		suite.assertS3Error(err, helpers.ExpectError("NoSuchKey"))
+	}
+}
+
+func (suite *ResilienceSuite) TestFaultTruncatedGetBody() {
+
+	/*
+		Resource : object, method: get
+		Scenario : the connection drops halfway through the response body.
+		Assertion: the client sees an error instead of short data.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "truncated"
+	content := strings.Repeat("data", 1024)
+
+	proxy := suite.newFaultProxy()
+	defer proxy.Close()
+	client := proxy.Client(0)
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, key, content)
+	assert.Nil(err)
+
+	proxy.Inject(helpers.TruncateResponseAfter(int64(len(content)/2)).On("GET", ""))
+
+	_, err = helpers.GetObject(client, bucket, key)
+	assert.NotNil(err)
+}
@@ -0,0 +1,204 @@
+package s3test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+const (
+	keySeedDir      = "testdata/fuzz/FuzzObjectKey"
+	metadataSeedDir = "testdata/fuzz/FuzzObjectMetadata"
+	generatedRuns   = 50
+)
+
+// keyConn returns a client for RoundTripObject that leaves keys such as
+// "a//b" uncleaned, addressing buckets in the style of the current pass.
+func keyConn() *s3.S3 {
+
+	return helpers.NewKeyConn(helpers.Creds, currentStyle)
+}
+
+func setupFuzzBuckets(f *testing.F) (*s3.S3, string, string) {
+
+	keySvc := keyConn()
+	bucket := helpers.GetBucketName()
+	copyBucket := helpers.GetBucketName()
+
+	for _, b := range []string{bucket, copyBucket} {
+		if err := helpers.CreateBucket(keySvc, b); err != nil {
+			f.Fatalf("create bucket %s: %v", b, err)
+		}
+	}
+
+	f.Cleanup(func() { helpers.DeleteBuckets(keySvc, bucket, copyBucket) })
+
+	return keySvc, bucket, copyBucket
+}
+
+func FuzzObjectKey(f *testing.F) {
+
+	/*
+		Resource : object, method: put/get/head/list/copy/delete
+		Scenario : round-trip fuzzed keys.
+		Assertion: every operation agrees on the key.
+	*/
+
+	for _, key := range helpers.EdgeCaseKeys() {
+		f.Add(key)
+	}
+
+	keySvc, bucket, copyBucket := setupFuzzBuckets(f)
+
+	f.Fuzz(func(t *testing.T, key string) {
+		if !helpers.IsValidObjectKey(key) {
+			t.Skip()
+		}
+
+		err := helpers.RoundTripObject(keySvc, bucket, copyBucket, key, key, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzObjectMetadata(f *testing.F) {
+
+	/*
+		Resource : object, method: put/get/head/copy
+		Scenario : round-trip fuzzed user metadata.
+		Assertion: every operation returns the metadata that was sent.
+	*/
+
+	for name, value := range helpers.EdgeCaseMetadata() {
+		f.Add(name, value)
+	}
+
+	keySvc, bucket, copyBucket := setupFuzzBuckets(f)
+
+	f.Fuzz(func(t *testing.T, name string, value string) {
+		if !helpers.IsValidMetadata(name, value) {
+			t.Skip()
+		}
+
+		metadata := map[string]string{name: value}
+		err := helpers.RoundTripObject(keySvc, bucket, copyBucket, "meta", value, metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func (suite *ObjectSuite) TestObjectKeyRoundTripEdgeCases() {
+
+	/*
+		Resource : object, method: put/get/head/list/copy/delete
+		Scenario : round-trip keys with unicode, '%', '+', spaces, '//' and control characters.
+		Assertion: every operation agrees on the key.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	copyBucket := suite.bucketName()
+	keySvc := keyConn()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, copyBucket)
+	assert.Nil(err)
+
+	for _, key := range helpers.EdgeCaseKeys() {
+		err = helpers.RoundTripObject(keySvc, bucket, copyBucket, key, key, nil)
+		assert.Nil(err, "key %q", key)
+	}
+}
+
+func (suite *ObjectSuite) TestObjectKeyRoundTripGenerated() {
+
+	/*
+		Resource : object, method: put/get/head/list/copy/delete
+		Scenario : round-trip randomly generated keys.
+		Assertion: every operation agrees on the key; failures become fuzz seeds.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	copyBucket := suite.bucketName()
+	keySvc := keyConn()
+	suite.T().Logf("generator seed %d", helpers.GetSeed())
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, copyBucket)
+	assert.Nil(err)
+
+	for i := 0; i < generatedRuns; i++ {
+		key := helpers.RandomKey(1 + i*helpers.MaxKeyLength/generatedRuns)
+
+		err = helpers.RoundTripObject(keySvc, bucket, copyBucket, key, key, nil)
+		if !assert.Nil(err, "key %q", key) {
+			path, _ := helpers.SaveFuzzSeed(keySeedDir, key)
+			suite.T().Logf("saved regression seed %s", path)
+		}
+	}
+}
+
+func (suite *ObjectSuite) TestObjectMetadataRoundTripEdgeCases() {
+
+	/*
+		Resource : object, method: put/get/head/copy
+		Scenario : round-trip user metadata with unusual header values.
+		Assertion: every operation returns the metadata that was sent.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	copyBucket := suite.bucketName()
+	keySvc := keyConn()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, copyBucket)
+	assert.Nil(err)
+
+	err = helpers.RoundTripObject(keySvc, bucket, copyBucket, "meta", "echo", helpers.EdgeCaseMetadata())
+	assert.Nil(err)
+}
+
+func (suite *ObjectSuite) TestObjectMetadataRoundTripGenerated() {
+
+	/*
+		Resource : object, method: put/get/head/copy
+		Scenario : round-trip randomly generated user metadata.
+		Assertion: every operation returns the metadata that was sent; failures become fuzz seeds.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	copyBucket := suite.bucketName()
+	keySvc := keyConn()
+	suite.T().Logf("generator seed %d", helpers.GetSeed())
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, copyBucket)
+	assert.Nil(err)
+
+	for i := 0; i < generatedRuns; i++ {
+		for name, value := range helpers.RandomMetadata(1) {
+			metadata := map[string]string{name: value}
+
+			err = helpers.RoundTripObject(keySvc, bucket, copyBucket, "meta", value, metadata)
+			if !assert.Nil(err, "metadata %q: %q", name, value) {
+				path, _ := helpers.SaveFuzzSeed(metadataSeedDir, name, value)
+				suite.T().Logf("saved regression seed %s", path)
+			}
+		}
+	}
+}
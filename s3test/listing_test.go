@@ -0,0 +1,775 @@
+package s3test
+
+import (
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// func (suite *ListingSuite) TestObjectListPrefixDelimiterPrefixDelimiterNotExist() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list under prefix w/delimiter.
+// 		Assertion: finds nothing w/unmatched prefix and delimiter.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	prefix := "y"
+// 	delimeter := "z"
+// 	var empty_list []*s3.Object
+// 	objects := map[string]string{"b/a/c": "echo", "b/a/g": "lima", "b/a/r": "golf", "g": "golf"}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimeterAndPrefix(svc, bucket, prefix, delimeter)
+// 	assert.Nil(errr)
+// 	assert.Equal(keys, []string{})
+// 	assert.Equal(prefixes, []string{})
+// 	assert.Equal(empty_list, list.Contents)
+// }
+
+// func (suite *ListingSuite) TestObjectListPrefixDelimiterDelimiterNotExist() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list under prefix w/delimiter.
+// 		Assertion: over-ridden slash ceases to be a delimiter.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	prefix := "b"
+// 	delimeter := "z"
+// 	objects := map[string]string{"b/a/c": "echo", "b/a/g": "lima", "b/a/r": "golf", "golffie": "golfyy"}
+// 	expectedkeys := []string{"b/a/c", "b/a/g", "b/a/r"}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimeterAndPrefix(svc, bucket, prefix, delimeter)
+// 	assert.Nil(errr)
+// 	assert.Equal(len(list.Contents), 3)
+// 	assert.Equal(keys, expectedkeys)
+// 	assert.Equal(prefixes, []string{})
+// }
+
+func (suite *ListingSuite) TestObjectListPrefixDelimiterPrefixNotExist() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix w/delimiter.
+		Assertion: finds nothing w/unmatched prefix and delimiter.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	prefix := "d"
+	delimeter := "/"
+	var empty_list []*s3.Object
+	objects := map[string]string{"b/a/r": "echo", "b/a/c": "lima", "b/a/g": "golf", "g": "g"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithDelimeterAndPrefix(svc, bucket, prefix, delimeter)
+	assert.Nil(errr)
+	assert.Equal(keys, []string{})
+	assert.Equal(prefixes, []string{})
+	assert.Equal(empty_list, list.Contents)
+}
+
+// func (suite *ListingSuite) TestObjectListPrefixDelimiterAlt() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list under prefix w/delimiter.
+// 		Assertion: non-slash delimiters.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	prefix := "ba"
+// 	delimeter := "a"
+// 	objects := map[string]string{"bar": "echo", "bazar": "lima", "cab": "golf", "foo": "g"}
+// 	expected_keys := []string{"bar"}
+// 	expected_prefixes := []string{"baza"}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimeterAndPrefix(svc, bucket, prefix, delimeter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Prefix, prefix)
+// 	assert.Equal(*list.Delimiter, delimeter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(prefixes, expected_prefixes)
+// }
+
+func (suite *ListingSuite) TestObjectListPrefixDelimiterBasic() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix w/delimiter.
+		Assertion: returns only objects directly under prefix.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	prefix := "foo/"
+	delimeter := "/"
+	objects := map[string]string{"foo/": "", "foo/bar": "echo", "foo/baz/xyzzy": "lima", "quux/thud": "golf"}
+	expected_keys := []string{"foo/bar", "foo/"}
+	expected_prefixes := []string{"foo/baz/"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithDelimeterAndPrefix(svc, bucket, prefix, delimeter)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(*list.Delimiter, delimeter)
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+}
+
+func (suite *ListingSuite) TestObjectListPrefixUnreadable() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix.
+		Assertion: non-printable prefix can be specified.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	prefix := "\x0a"
+	objects := map[string]string{"foo/bar": "echo", "foo/baz/xyzzy": "lima", "quux/thud": "golf"}
+	expected_keys := []string{}
+	expected_prefixes := []string{}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithPrefix(svc, bucket, prefix)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(prefixes, expected_prefixes)
+	assert.Equal(keys, expected_keys)
+
+}
+
+func (suite *ListingSuite) TestObjectListPrefixNotExist() {
+
+	/*
+		Resource : object, method: List
+		Scenario : list under prefix.
+		Assertion: nonexistent prefix returns nothing.
+	*/
+
+	assert := suite
+	bucket := suite.prefixes
+	prefix := "d"
+	expected_keys := []string{}
+	expected_prefixes := []string{}
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithPrefix(svc, bucket, prefix)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+func (suite *ListingSuite) TestObjectListPrefixNone() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix.
+		Assertion: unspecified prefix returns everything.
+	*/
+
+	assert := suite
+	bucket := suite.prefixes
+	prefix := ""
+	expected_keys := []string{"foo/bar", "foo/baz", "quux"}
+	expected_prefixes := []string{}
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithPrefix(svc, bucket, prefix)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+}
+
+func (suite *ListingSuite) TestObjectListPrefixEmpty() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix.
+		Assertion: empty prefix returns everything.
+	*/
+
+	assert := suite
+	bucket := suite.prefixes
+	prefix := ""
+	expected_keys := []string{"foo/bar", "foo/baz", "quux"}
+	expected_prefixes := []string{}
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithPrefix(svc, bucket, prefix)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+func (suite *ListingSuite) TestObjectListPrefixAlt() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix.
+		Assertion: prefixes w/o delimiters.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	prefix := "ba"
+	objects := map[string]string{"bar": "echo", "baz": "lima", "foo": "golf"}
+	expected_keys := []string{"bar", "baz"}
+	expected_prefixes := []string{}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithPrefix(svc, bucket, prefix)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+func (suite *ListingSuite) TestObjectListPrefixBasic() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list under prefix.
+		Assertion: returns only objects under prefix.
+		Tags     : smoke
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	prefix := "foo/"
+	objects := map[string]string{"foo/": "", "foo/bar": "echo", "foo/baz": "lima", "quux": "golf"}
+	expected_keys := []string{"foo/bar", "foo/baz", "foo/"}
+	expected_prefixes := []string{}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithPrefix(svc, bucket, prefix)
+	assert.Nil(errr)
+	assert.Equal(*list.Prefix, prefix)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+func (suite *ListingSuite) TestObjectListDelimiterNotExist() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list .
+		Assertion: unused delimiter is not found.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	delimiter := "/"
+	objects := map[string]string{"bar": "echo", "baz": "lima", "cab": "golf", "foo": "golf"}
+	expected_keys := []string{"bar", "baz", "cab", "foo"}
+	expected_prefixes := []string{}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+	assert.Nil(errr)
+	assert.Equal(*list.Delimiter, delimiter)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+// func (suite *ListingSuite) TestObjectListDelimiterNone() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list .
+// 		Assertion: unspecified delimiter defaults to none.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	delimiter := " "
+// 	objects := map[string]string{"bar": "echo", "baz": "lima", "cab": "golf", "foo": "golf"}
+// 	expected_keys := []string{"bar", "baz", "cab", "foo"}
+// 	expected_prefixes := []string{}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Delimiter, delimiter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(prefixes, expected_prefixes)
+
+// }
+
+// func (suite *ListingSuite) TestObjectListDelimiterEmpty() {
+
+// 	// Resource : object, method: list
+// 	// Scenario : list .
+// 	// Assertion: empty delimiter can be specified.
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	delimiter := " "
+// 	objects := map[string]string{"bar": "echo", "baz": "lima", "cab": "golf", "foo": "golf"}
+// 	expected_keys := []string{"bar", "baz", "cab", "foo"}
+// 	expected_prefixes := []string{}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Delimiter, delimiter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(prefixes, expected_prefixes)
+
+// }
+
+// func (suite *ListingSuite) TestObjectListDelimiterUnreadable() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list .
+// 		Assertion: non-printable delimiter can be specified.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	delimiter := "\x0a"
+// 	objects := map[string]string{"bar": "echo", "baz": "lima", "cab": "golf", "foo": "golf"}
+// 	expected_keys := []string{"bar", "baz", "cab", "foo"}
+// 	expected_prefixes := []string{}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Delimiter, delimiter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(prefixes, expected_prefixes)
+
+// }
+
+// func (suite *ListingSuite) TestObjectListDelimiterDot() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list .
+// 		Assertion: dot delimiter characters.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	delimiter := "."
+// 	objects := map[string]string{"b.ar": "echo", "b.az": "lima", "c.ab": "golf", "foo": "golf"}
+// 	expected_keys := []string{"foo"}
+// 	expected_prefixes := []string{"b.", "c."}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Delimiter, delimiter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(len(prefixes), 2)
+// 	assert.Equal(prefixes, expected_prefixes)
+
+// }
+
+// func (suite *ListingSuite) TestObjectListDelimiterPercentage() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list .
+// 		Assertion: percentage delimiter characters.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	delimiter := "%"
+// 	objects := map[string]string{"b%ar": "echo", "b%az": "lima", "c%ab": "golf", "foo": "golf"}
+// 	expected_keys := []string{"foo"}
+// 	expected_prefixes := []string{"b%", "c%"}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Delimiter, delimiter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(len(prefixes), 2)
+// 	assert.Equal(prefixes, expected_prefixes)
+
+// }
+
+func (suite *ListingSuite) TestObjectListDelimiterWhiteSpace() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list .
+		Assertion: whitespace delimiter characters.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	delimiter := " "
+	objects := map[string]string{"b ar": "echo", "b az": "lima", "c ab": "golf", "foo": "golf"}
+	expected_keys := []string{"foo"}
+	expected_prefixes := []string{"b ", "c "}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+	assert.Nil(errr)
+	assert.Equal(*list.Delimiter, delimiter)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(len(prefixes), 2)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+// func (suite *ListingSuite) TestObjectListDelimiterAlt() {
+
+// 	/*
+// 		Resource : object, method: list
+// 		Scenario : list .
+// 		Assertion: non-slash delimiter characters.
+// 	*/
+
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	delimiter := "a"
+// 	objects := map[string]string{"bar": "echo", "baz": "lima", "cab": "golf", "foo": "golf"}
+// 	expected_keys := []string{"foo"}
+// 	expected_prefixes := []string{"ba", "ca"}
+
+// 	err := helpers.CreateBucket(svc, bucket)
+// 	err = helpers.CreateObjects(svc, bucket, objects)
+// 	assert.Nil(err)
+
+// 	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+// 	assert.Nil(errr)
+// 	assert.Equal(*list.Delimiter, delimiter)
+
+// 	assert.Equal(keys, expected_keys)
+// 	assert.Equal(len(prefixes), 2)
+// 	assert.Equal(prefixes, expected_prefixes)
+
+// }
+
+func (suite *ListingSuite) TestObjectListDelimiterBasic() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list .
+		Assertion: prefixes in multi-component object names.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	delimiter := "/"
+	objects := map[string]string{"foo/bar": "echo", "foo/baz/xyzzy": "lima", "quux/thud": "golf", "asdf": "golf"}
+	expected_keys := []string{"asdf"}
+	expected_prefixes := []string{"foo/", "quux/"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	list, keys, prefixes, errr := helpers.ListObjectsWithDelimiter(svc, bucket, delimiter)
+	assert.Nil(errr)
+	assert.Equal(*list.Delimiter, delimiter)
+
+	assert.Equal(keys, expected_keys)
+	assert.Equal(len(prefixes), 2)
+	assert.Equal(prefixes, expected_prefixes)
+
+}
+
+func (suite *ListingSuite) TestObjectListMaxkeysNone() {
+
+	/*
+		Resource : Object, Method: list
+		Operation : List all keys
+		Assertion : pagination w/o max_keys.
+	*/
+
+	assert := suite
+	bucket := suite.maxKeys
+	ExpectedKeys := []string{"key1", "key2", "key3"}
+
+	resp, err := helpers.GetObjects(svc, bucket)
+	assert.Nil(err)
+
+	keys := []string{}
+	for _, key := range resp.Contents {
+		keys = append(keys, *key.Key)
+	}
+	assert.Equal(keys, ExpectedKeys)
+	assert.Equal(*resp.MaxKeys, int64(1000))
+	assert.Equal(*resp.IsTruncated, false)
+}
+
+func (suite *ListingSuite) TestObjectListMaxkeysZero() {
+
+	/*
+		Resource : object, method: get
+		Operation : List all keys .
+		Assertion: pagination w/max_keys=0.
+	*/
+
+	assert := suite
+	bucket := suite.maxKeys
+	maxkeys := int64(0)
+	ExpectedKeys := []string(nil)
+
+	resp, keys, errr := helpers.GetKeysWithMaxKeys(svc, bucket, maxkeys)
+	assert.Nil(errr)
+	assert.Equal(ExpectedKeys, keys)
+	assert.Equal(*resp.IsTruncated, false)
+}
+
+func (suite *ListingSuite) TestObjectListMaxkeysOne() {
+
+	/*
+		Resource : object, method: list
+		Operation : List keys all keys.
+		Assertion: pagination w/max_keys=1, marker.
+	*/
+
+	assert := suite
+	bucket := suite.maxKeys
+	maxkeys := int64(1)
+	EKeysMaxkey := []string{"key1"}
+	EKeysMarker := []string{"key2", "key3"}
+
+	resp, keys, errr := helpers.GetKeysWithMaxKeys(svc, bucket, maxkeys)
+	assert.Nil(errr)
+	assert.Equal(EKeysMaxkey, keys)
+	assert.Equal(*resp.IsTruncated, true)
+
+	resp, keys, errs := helpers.GetKeysWithMarker(svc, bucket, EKeysMaxkey[0])
+	assert.Nil(errs)
+	assert.Equal(*resp.IsTruncated, false)
+	assert.Equal(keys, EKeysMarker)
+
+}
+
+//............................................Test Get object with marker...................................
+
+func (suite *ListingSuite) TestObjectListMarkerBeforeList() {
+
+	/*
+		Resource : object, method: get
+		Scenario : list all objects.
+		Assertion: marker before list.
+	*/
+
+	assert := suite
+	bucket := suite.markers
+	marker := "aaa"
+	expected_keys := []string{"bar", "baz", "quux"}
+
+	resp, keys, errr := helpers.GetKeysWithMarker(svc, bucket, marker)
+	assert.Nil(errr)
+	assert.Equal(*resp.Marker, marker)
+	assert.Equal(keys, expected_keys)
+	assert.Equal(*resp.IsTruncated, false)
+
+}
+
+func (suite *ListingSuite) TestObjectListMarkerAfterList() {
+
+	/*
+		Resource : object, method: get
+		Scenario : list all objects.
+		Assertion: marker after list.
+	*/
+
+	assert := suite
+	bucket := suite.markers
+	marker := "zzz"
+	expected_keys := []string(nil)
+
+	resp, keys, errr := helpers.GetKeysWithMarker(svc, bucket, marker)
+	assert.Nil(errr)
+	assert.Equal(*resp.Marker, marker)
+	assert.Equal(*resp.IsTruncated, false)
+	assert.Equal(keys, expected_keys)
+
+}
+
+func (suite *ListingSuite) TestObjectListMarkerNotInList() {
+
+	/*
+		Resource : object, method: get
+		Scenario : list all objects.
+		Assertion: marker not in list.
+	*/
+
+	assert := suite
+	bucket := suite.markers
+	marker := "blah"
+	expected_keys := []string{"quux"}
+
+	resp, keys, errr := helpers.GetKeysWithMarker(svc, bucket, marker)
+	assert.Nil(errr)
+	assert.Equal(*resp.Marker, marker)
+	assert.Equal(keys, expected_keys)
+}
+
+func (suite *ListingSuite) TestObjectListMarkerUnreadable() {
+
+	/*
+		Resource : object, method: get
+		Scenario : list all objects.
+		Assertion: non-printing marker.
+	*/
+
+	assert := suite
+	bucket := suite.markers
+	marker := "\x0a"
+	expected_keys := []string{"bar", "baz", "quux"}
+
+	resp, keys, errr := helpers.GetKeysWithMarker(svc, bucket, marker)
+	assert.Nil(errr)
+	assert.Equal(*resp.Marker, marker)
+	assert.Equal(*resp.IsTruncated, false)
+	assert.Equal(keys, expected_keys)
+
+}
+
+func (suite *ListingSuite) TestObjectListMarkerEmpty() {
+
+	/*
+		Resource : object, method: get
+		Scenario : list all objects.
+		Assertion: no pagination, empty marker.
+	*/
+
+	assert := suite
+	bucket := suite.markers
+	marker := ""
+	expected_keys := []string{"bar", "baz", "quux"}
+
+	resp, keys, errr := helpers.GetKeysWithMarker(svc, bucket, marker)
+	assert.Nil(errr)
+	assert.Equal(*resp.Marker, marker)
+	assert.Equal(*resp.IsTruncated, false)
+	assert.Equal(keys, expected_keys)
+
+}
+
+func (suite *ListingSuite) TestObjectListMarkerNone() {
+
+	/*
+		Resource : object, method: get
+		Scenario : list all objects.
+		Assertion: no pagination, no marker.
+	*/
+
+	assert := suite
+	bucket := suite.markers
+	marker := ""
+
+	resp, _, errr := helpers.GetKeysWithMarker(svc, bucket, marker)
+	assert.Nil(errr)
+	assert.Equal(*resp.Marker, marker)
+
+}
+
+func (suite *ListingSuite) TestObjectListMany() {
+
+	/*
+		Resource : object, method: list
+		Scenario : list all keys
+		Assertion: pagination w/max_keys=2, no marker.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	maxkeys := int64(2)
+	keys := []string{}
+	objects := map[string]string{"foo": "echo", "bar": "lima", "baz": "golf"}
+	expected_keys := []string{"bar", "baz"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, objects)
+	assert.Nil(err)
+
+	resp, keys, errr := helpers.GetKeysWithMaxKeys(svc, bucket, maxkeys)
+	assert.Nil(errr)
+	assert.Equal(len(resp.Contents), 2)
+	assert.Equal(*resp.IsTruncated, true)
+	assert.Equal(keys, expected_keys)
+
+	resp, keys, errs := helpers.GetKeysWithMarker(svc, bucket, expected_keys[1])
+	assert.Nil(errs)
+	assert.Equal(len(resp.Contents), 1)
+	assert.Equal(*resp.IsTruncated, false)
+	expected_keys = []string{"foo"}
+
+}
@@ -0,0 +1,148 @@
+package s3test
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+func (suite *baseSuite) assertBucketRegion(bucket string, region string) {
+
+	assert := suite
+
+	location, err := helpers.GetBucketLocation(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(region, s3.NormalizeBucketLocation(location))
+
+	header, err := helpers.HeadBucketRegion(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(region, header)
+}
+
+func (suite *BucketSuite) TestBucketCreateNoLocationConstraint() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create a bucket without a CreateBucketConfiguration.
+		Assertion: the bucket is placed in the default region, us-east-1 unless s3main.default_bucket_region says otherwise.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucketInRegion(svc, bucket, "")
+	assert.Nil(err)
+	suite.assertBucketRegion(bucket, helpers.DefaultBucketRegion())
+}
+
+func (suite *BucketSuite) TestBucketCreateMatchingLocationConstraint() {
+
+	/*
+		Resource : bucket, method: create, get location, head
+		Scenario : create a bucket constrained to the endpoint's region.
+		Assertion: GetBucketLocation and x-amz-bucket-region both report it.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucketInRegion(svc, bucket, helpers.Region())
+	assert.Nil(err)
+	suite.assertBucketRegion(bucket, helpers.Region())
+}
+
+func (suite *BucketSuite) TestBucketCreateMismatchedLocationConstraint() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create a bucket constrained to a region the endpoint does not serve.
+		Assertion: fails with IllegalLocationConstraintException, no bucket created.
+	*/
+
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucketInRegion(svc, bucket, helpers.OtherRegion())
+	suite.assertS3Error(err, helpers.ExpectError("IllegalLocationConstraintException"))
+
+	_, err = svc.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	suite.assertS3Error(err, helpers.ExpectError("NotFound"))
+}
+
+func (suite *BucketSuite) TestBucketCreateInvalidLocationConstraint() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create a bucket with a location constraint that is no region.
+		Assertion: fails with InvalidLocationConstraint.
+	*/
+
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucketInRegion(svc, bucket, "not-a-region")
+	suite.assertS3Error(err, helpers.ExpectError("InvalidLocationConstraint"))
+}
+
+func (suite *BucketSuite) TestBucketGetLocationNotExist() {
+
+	/*
+		Resource : bucket, method: get location
+		Scenario : GetBucketLocation of a bucket that does not exist.
+		Assertion: fails with NoSuchBucket.
+	*/
+
+	_, err := helpers.GetBucketLocation(svc, suite.bucketName())
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchBucket"))
+}
+
+func (suite *BucketSuite) TestBucketSignedForWrongRegion() {
+
+	/*
+		Resource : object, method: list
+		Scenario : SigV4 request scoped to a region other than the bucket's.
+		Assertion: fails with AuthorizationHeaderMalformed naming the expected region.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	other := helpers.NewRegionConn(helpers.Creds, helpers.OtherRegion())
+	_, err = helpers.ListObjects(other, bucket)
+	suite.assertS3Error(err, helpers.ExpectError("AuthorizationHeaderMalformed").
+		WithMessage("expecting '"+regexp.QuoteMeta(helpers.Region())+"'"))
+}
+
+func (suite *BucketSuite) TestRawSignedForWrongRegionHint() {
+
+	/*
+		Resource : object, method: list
+		Resource : bucket, method: head
+		Scenario : raw requests signed for another region.
+		Assertion: GET carries the bucket's region in the error document, HEAD in x-amz-bucket-region.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	client := helpers.NewRawClient(helpers.Creds)
+	client.Region = helpers.OtherRegion()
+
+	resp, err := client.Do(helpers.NewRawRequest("GET", bucket, "", ""))
+	assert.Nil(err)
+	if suite.assertRawError(resp, helpers.ExpectError("AuthorizationHeaderMalformed")) {
+		assert.Equal(helpers.Region(), resp.Error.Region)
+	}
+
+	resp, err = client.Do(helpers.NewRawRequest("HEAD", bucket, "", ""))
+	assert.Nil(err)
+	assert.Equal(http.StatusBadRequest, resp.Status)
+	assert.Equal(helpers.Region(), resp.Header.Get("X-Amz-Bucket-Region"))
+}
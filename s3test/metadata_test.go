@@ -0,0 +1,282 @@
+package s3test
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// storedHeaders are set on objects and must come back unchanged. The SDK
+// sends dates without a leading zero in the day, so days have two digits.
+var storedHeaders = helpers.ObjectHeaders{
+	ContentType:        "application/x-test",
+	ContentEncoding:    "deflate",
+	ContentDisposition: `attachment; filename="report.txt"`,
+	ContentLanguage:    "de-DE",
+	CacheControl:       "max-age=3600, must-revalidate",
+	Expires:            "Thu, 15 Dec 2033 16:00:00 GMT",
+}
+
+// assertStoredHeaders checks HEAD and GET of bucket/key return headers and
+// metadata.
+func (suite *baseSuite) assertStoredHeaders(bucket string, key string, headers helpers.ObjectHeaders, metadata map[string]string) {
+
+	assert := suite
+
+	got, meta, err := helpers.HeadObjectHeaders(svc, bucket, key)
+	assert.Nil(err)
+	assert.Equal(headers, got, "HEAD")
+	assert.Equal(metadata, meta, "HEAD")
+
+	got, meta, err = helpers.GetObjectHeaders(svc, bucket, key, helpers.ObjectHeaders{})
+	assert.Nil(err)
+	assert.Equal(headers, got, "GET")
+	assert.Equal(metadata, meta, "GET")
+}
+
+func (suite *ObjectSuite) TestObjectMetadataNameCase() {
+
+	/*
+		Resource : object, method: put, head
+		Scenario : metadata names in mixed case.
+		Assertion: names come back lowercased, values keep their case.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectWithHeaders(svc, bucket, "key1", "bar", helpers.ObjectHeaders{}, map[string]string{"MyMeta": "MixedValue", "UPPER": "ABC"})
+	assert.Nil(err)
+
+	_, meta, err := helpers.HeadObjectHeaders(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal(map[string]string{"mymeta": "MixedValue", "upper": "ABC"}, meta)
+}
+
+func (suite *ObjectSuite) TestObjectMetadataEncodedNonASCII() {
+
+	/*
+		Resource : object, method: put, head
+		Scenario : non-ASCII metadata values sent RFC 2047 encoded.
+		Assertion: the encoded words come back verbatim and decode to the original.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	values := map[string]string{
+		"latin": "Grüße aus Köln",
+		"cjk":   "日本語のメタデータ",
+		"emoji": "status 🙂",
+	}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	encoded := map[string]string{}
+	for name, value := range values {
+		encoded[name] = mime.BEncoding.Encode("UTF-8", value)
+	}
+	err = helpers.PutObjectWithHeaders(svc, bucket, "key1", "bar", helpers.ObjectHeaders{}, encoded)
+	assert.Nil(err)
+
+	head, err := helpers.HeadObject(svc, bucket, "key1")
+	assert.Nil(err)
+	for name, value := range encoded {
+		assert.Equal(value, strings.TrimSpace(aws.StringValue(head.Metadata[http.CanonicalHeaderKey(name)])), name)
+	}
+	assert.Equal(values, helpers.NormalizeMetadata(head.Metadata))
+}
+
+func (suite *ObjectSuite) TestObjectMetadataSizeLimit() {
+
+	/*
+		Resource : object, method: put
+		Scenario : user metadata of exactly 2KB, then one byte over.
+		Assertion: 2KB is stored, 2KB+1 fails with MetadataTooLarge.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	metadata := map[string]string{
+		"meta1": strings.Repeat("a", 1000),
+		"meta2": strings.Repeat("b", helpers.MaxMetadataSize-1000-2*len("meta1")),
+	}
+	assert.Equal(helpers.MaxMetadataSize, helpers.MetadataSize(metadata))
+
+	err = helpers.PutObjectWithHeaders(svc, bucket, "fits", "bar", helpers.ObjectHeaders{}, metadata)
+	assert.Nil(err)
+	_, meta, err := helpers.HeadObjectHeaders(svc, bucket, "fits")
+	assert.Nil(err)
+	assert.Equal(metadata, meta)
+
+	metadata["meta2"] += "b"
+	err = helpers.PutObjectWithHeaders(svc, bucket, "too-large", "bar", helpers.ObjectHeaders{}, metadata)
+	suite.assertS3Error(err, helpers.ExpectError("MetadataTooLarge"))
+	suite.assertNotFound(bucket, "too-large")
+}
+
+func (suite *ObjectSuite) TestObjectSystemHeadersRoundTrip() {
+
+	/*
+		Resource : object, method: put, get, head
+		Scenario : PUT with Content-Type, Content-Encoding, Content-Disposition,
+			Content-Language, Cache-Control, Expires and user metadata.
+		Assertion: GET and HEAD return every one of them unchanged.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	metadata := map[string]string{"origin": "upload"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectWithHeaders(svc, bucket, "key1", "bar", storedHeaders, metadata)
+	assert.Nil(err)
+	suite.assertStoredHeaders(bucket, "key1", storedHeaders, metadata)
+}
+
+func (suite *ObjectSuite) TestObjectCopyReplaceHeaders() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy with the REPLACE directive and new headers and metadata.
+		Assertion: the copy has only the new headers and metadata; the source is unchanged.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	metadata := map[string]string{"origin": "upload"}
+	replaced := helpers.ObjectHeaders{
+		ContentType:        "text/plain",
+		ContentDisposition: "inline",
+		CacheControl:       "no-cache",
+	}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectWithHeaders(svc, bucket, "source", "bar", storedHeaders, metadata)
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "source", bucket, "copy",
+		helpers.CopyMetadata("REPLACE", map[string]string{"origin": "copy"}),
+		helpers.CopyHeaders(replaced))
+	assert.Nil(err)
+
+	suite.assertStoredHeaders(bucket, "copy", replaced, map[string]string{"origin": "copy"})
+	suite.assertStoredHeaders(bucket, "source", storedHeaders, metadata)
+}
+
+func (suite *ObjectSuite) TestObjectCopyKeepsHeaders() {
+
+	/*
+		Resource : object, method: copy
+		Scenario : copy with the COPY directive while sending other headers.
+		Assertion: the copy has the source's headers and metadata; the sent ones are ignored.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	metadata := map[string]string{"origin": "upload"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectWithHeaders(svc, bucket, "source", "bar", storedHeaders, metadata)
+	assert.Nil(err)
+
+	_, err = helpers.CopyObjectWith(svc, bucket, "source", bucket, "copy",
+		helpers.CopyMetadata("COPY", map[string]string{"origin": "ignored"}),
+		helpers.CopyHeaders(helpers.ObjectHeaders{ContentType: "text/ignored", CacheControl: "no-store"}))
+	assert.Nil(err)
+
+	suite.assertStoredHeaders(bucket, "copy", storedHeaders, metadata)
+}
+
+func (suite *ObjectSuite) TestObjectMultipartHeaders() {
+
+	/*
+		Resource : object, method: multipart upload
+		Scenario : multipart upload created with standard headers and metadata.
+		Assertion: the completed object returns them on GET and HEAD.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	metadata := map[string]string{"origin": "multipart"}
+	parts := []string{strings.Repeat("a", 5*1024*1024), "tail"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.MultipartUploadWithHeaders(svc, bucket, "key1", parts, storedHeaders, metadata)
+	assert.Nil(err)
+	suite.assertStoredHeaders(bucket, "key1", storedHeaders, metadata)
+}
+
+func (suite *ObjectSuite) TestObjectResponseHeaderOverrides() {
+
+	/*
+		Resource : object, method: get
+		Scenario : signed GET with every response-* override.
+		Assertion: the response carries the overrides; the stored headers are unchanged.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	overrides := helpers.ObjectHeaders{
+		ContentType:        "application/octet-stream",
+		ContentEncoding:    "identity",
+		ContentDisposition: `attachment; filename="download.bin"`,
+		ContentLanguage:    "fr",
+		CacheControl:       "no-store",
+		Expires:            "Wed, 15 Jan 2031 00:00:00 GMT",
+	}
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectWithHeaders(svc, bucket, "key1", "bar", storedHeaders, nil)
+	assert.Nil(err)
+
+	got, _, err := helpers.GetObjectHeaders(svc, bucket, "key1", overrides)
+	assert.Nil(err)
+	assert.Equal(overrides, got)
+
+	suite.assertStoredHeaders(bucket, "key1", storedHeaders, map[string]string{})
+}
+
+func (suite *ObjectSuite) TestObjectResponseHeaderOverridesAnonymous() {
+
+	/*
+		Resource : object, method: get
+		Scenario : anonymous GET of a public-read object with response-content-type.
+		Assertion: fails with InvalidRequest; overrides need a signed request.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+	err = helpers.SetObjectACL(svc, bucket, "key1", "public-read")
+	assert.Nil(err)
+
+	_, err = anonSvc.GetObject(&s3.GetObjectInput{
+		Bucket:              aws.String(bucket),
+		Key:                 aws.String("key1"),
+		ResponseContentType: aws.String("text/html"),
+	})
+	suite.assertS3Error(err, helpers.ExpectError("InvalidRequest"))
+}
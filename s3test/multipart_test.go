@@ -0,0 +1,226 @@
+package s3test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+//......................................Multipart Upload...................................................................
+
+func (suite *MultipartSuite) TestAbortMultipartUploadInvalid() {
+
+	/*
+		Resource : object, method: get
+		Scenario : Abort given invalid arguments.
+		Assertion: fails.
+	*/
+
+	assert := suite
+	bucket := suite.uploads
+	key := "mymultipart"
+
+	_, err := helpers.AbortMultiPartUploadInvalid(svc, bucket, key, key)
+	assert.NotNil(err)
+	awsErr, ok := err.(awserr.Error)
+	assert.True(ok)
+	assert.Equal(awsErr.Code(), "InvalidParameter")
+	assert.Equal(awsErr.Message(), "1 validation error(s) found.")
+}
+
+func (suite *MultipartSuite) TestAbortMultipartUploadNotfound() {
+
+	/*
+		Resource : object, method: get
+		Scenario : Abort non existant multipart upload
+		Assertion: fails.
+	*/
+
+	assert := suite
+	bucket := suite.uploads
+	key := "mymultipart"
+
+	_, err := helpers.AbortMultiPartUpload(svc, bucket, key, key)
+	assert.NotNil(err)
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchUpload"))
+}
+
+func (suite *MultipartSuite) TestAbortMultipartUpload() {
+
+	/*
+		Resource : object, method: get
+		Scenario : Abort multipart upload
+		Assertion: successful.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	bucket2 := suite.bucketName()
+	key := "key"
+	fmtstring := fmt.Sprintf("%s/%s", bucket2, key)
+	objects := map[string]string{fmtstring: "golf"}
+
+	err := helpers.CreateBucket(svc, bucket2)
+	err = helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket2, objects)
+
+	result, err := helpers.InitiateMultipartUpload(svc, bucket, "key")
+	// _, err = helpers.UploadCopyPart(svc, bucket, key, fmtstring, *result.UploadId, int64(1))
+	// assert.Nil(err)
+
+	_, err = helpers.AbortMultiPartUpload(svc, bucket, key, *result.UploadId)
+	assert.Nil(err)
+
+	resp, err := helpers.Listparts(svc, bucket, key, *result.UploadId)
+	assert.Equal(len(resp.Parts), 0)
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchUpload"))
+}
+
+func (suite *MultipartSuite) TestMultipartUploadOverwriteExistingObject() {
+
+	/*
+		Resource : object, method: get
+		Scenario : multi-part upload overwrites existing key
+		Assertion: successful.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	num_parts := 1
+
+	payload := strings.Repeat("12345", 1024*1024)
+	key_name := "mymultipart"
+
+	newObject := map[string]string{key_name: "payload"}
+
+	err := helpers.CreateBucket(svc, bucket)
+	err = helpers.CreateObjects(svc, bucket, newObject)
+
+	result, err := helpers.InitiateMultipartUpload(svc, bucket, key_name)
+
+	resp, err := helpers.Uploadpart(svc, bucket, key_name, *result.UploadId, payload, int64(num_parts))
+	assert.Nil(err)
+
+	_, err = helpers.CompleteMultiUpload(svc, bucket, key_name, int64(num_parts), *result.UploadId, *resp.ETag)
+	assert.Nil(err)
+
+	gotData, err := helpers.GetObject(svc, bucket, key_name)
+	assert.Nil(err)
+	assert.Equal(gotData, payload)
+}
+
+func (suite *MultipartSuite) TestMultipartUploadContents() {
+
+	/*
+		Resource : object, method: get
+		Scenario : check contents of multi-part upload
+		Assertion: successful.
+		Tags     : smoke
+	*/
+	assert := suite
+	bucket := suite.bucketName()
+	num_parts := 2
+
+	payload := strings.Repeat("12345", 1024*1024)
+	key_name := "mymultipart"
+
+	err := helpers.CreateBucket(svc, bucket)
+
+	result, err := helpers.InitiateMultipartUpload(svc, bucket, key_name)
+
+	resp, err := helpers.Uploadpart(svc, bucket, key_name, *result.UploadId, payload, int64(num_parts))
+	assert.Nil(err)
+
+	_, err = helpers.CompleteMultiUpload(svc, bucket, key_name, int64(num_parts), *result.UploadId, *resp.ETag)
+	assert.Nil(err)
+
+	gotData, err := helpers.GetObject(svc, bucket, key_name)
+	assert.Nil(err)
+	assert.Equal(gotData, payload)
+}
+
+func (suite *MultipartSuite) TestMultipartUploadInvalidPart() {
+
+	/*
+		Resource : object, method: get
+		Scenario : check failure on multiple multi-part upload with invalid etag
+		Assertion: fails.
+	*/
+	assert := suite
+	bucket := suite.bucketName()
+	num_parts := 2
+
+	payload := strings.Repeat("12345", 1024*1024)
+	key_name := "mymultipart"
+
+	err := helpers.CreateBucket(svc, bucket)
+
+	result, err := helpers.InitiateMultipartUpload(svc, bucket, key_name)
+
+	_, err = helpers.Uploadpart(svc, bucket, key_name, *result.UploadId, payload, int64(num_parts))
+	assert.Nil(err)
+
+	_, err = helpers.CompleteMultiUpload(svc, bucket, key_name, int64(num_parts), *result.UploadId, "")
+	assert.NotNil(err)
+	suite.assertS3Error(err, helpers.ExpectError("InvalidPart"))
+}
+
+// func (suite *MultipartSuite) TestMultipartUploadNoSuchUpload() {
+// 	/*
+// 		Resource : object, method: get
+// 		Scenario : check failure on multiple multi-part upload with invalid upload id
+// 		Assertion: fails.
+// 	*/
+// 	assert := suite
+// 	bucket := suite.bucketName()
+// 	num_parts := 2
+
+// 	payload := strings.Repeat("12345", 1024*1024)
+// 	key_name := "mymultipart"
+
+// 	err := helpers.CreateBucket(svc, bucket)
+
+// 	result, err := helpers.InitiateMultipartUpload(svc, bucket, key_name)
+// 	fmt.Println("Result: ", result)
+
+// 	resp, err := helpers.Uploadpart(svc, bucket, key_name, *result.UploadId, payload, int64(num_parts))
+
+// 	assert.Nil(err)
+// 	fmt.Println("Resp: ", resp)
+
+// 	_, err = helpers.CompleteMultiUpload(svc, bucket, key_name, int64(num_parts), "*result.UploadId", *resp.ETag)
+//
+// 	assert.NotNil(err)
+// 	if err != nil {
+// 		if awsErr, ok := err.(awserr.Error); ok {
+
+// 			assert.Equal(awsErr.Code(), "NoSuchKey")
+// 			assert.Equal(awsErr.Message(), "")
+// 		}
+// 	}
+// }
+
+func (suite *MultipartSuite) TestUploadPartNoSuchUpload() {
+
+	/*
+		Resource : object, method: get
+		Scenario : check failure on multiple multi-part upload with invalid upload id
+		Assertion: fails.
+	*/
+	assert := suite
+	bucket := suite.bucketName()
+	num_parts := 2
+
+	payload := strings.Repeat("12345", 1024*1024)
+	key_name := "mymultipart"
+
+	err := helpers.CreateBucket(svc, bucket)
+
+	_, err = helpers.InitiateMultipartUpload(svc, bucket, key_name)
+	assert.Nil(err)
+	_, err = helpers.Uploadpart(svc, bucket, key_name, "*result.UploadId", payload, int64(num_parts))
+	assert.NotNil(err)
+	suite.assertS3Error(err, helpers.ExpectError("NoSuchUpload"))
+}
@@ -0,0 +1,84 @@
+package s3test
+
+import (
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+func (suite *BucketSuite) TestBucketNamingRules() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create buckets with names on both sides of each naming rule.
+		Assertion: valid names are created or already exist elsewhere if shared, the others fail with InvalidBucketName.
+	*/
+
+	assert := suite
+
+	for _, c := range helpers.BucketNameCases(helpers.GetBucketName()) {
+		err := helpers.CreateBucket(svc, c.Name)
+		if err == nil {
+			suite.trackBucket(c.Name)
+		}
+		if c.Shared && helpers.CheckError(err, helpers.ExpectError("BucketAlreadyExists")) == "" {
+			continue
+		}
+		if c.Valid {
+			assert.Nil(err, "%s: %q", c.Rule, c.Name)
+		} else if diff := helpers.CheckError(err, helpers.ExpectError("InvalidBucketName")); diff != "" {
+			suite.Fail(diff, "%s: %q", c.Rule, c.Name)
+		}
+	}
+}
+
+func (suite *BucketSuite) TestBucketCreateExistingOwnedByYou() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create a bucket the same user already owns.
+		Assertion: fails with 409 BucketAlreadyOwnedByYou; the bucket keeps its objects.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	err = helpers.CreateBucket(svc, bucket)
+	suite.assertS3Error(err, helpers.ExpectError("BucketAlreadyOwnedByYou"))
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
+
+func (suite *BucketSuite) TestBucketCreateExistingOtherOwner() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : the alt user creates a bucket the main user owns.
+		Assertion: fails with 409 BucketAlreadyExists; ownership does not change.
+	*/
+
+	assert := suite
+	if !helpers.HasAltUser() {
+		suite.T().Skip("s3alt user is not configured")
+	}
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.CreateBucket(altSvc, bucket)
+	suite.assertS3Error(err, helpers.ExpectError("BucketAlreadyExists"))
+
+	buckets, err := helpers.ListBuckets(svc)
+	assert.Nil(err)
+	assert.True(helpers.Contains(buckets, bucket))
+
+	buckets, err = helpers.ListBuckets(altSvc)
+	assert.Nil(err)
+	assert.False(helpers.Contains(buckets, bucket))
+}
@@ -0,0 +1,301 @@
+package s3test
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// raceWriters is how many goroutines each race test releases at once.
+const raceWriters = 16
+
+// raceParts returns the payloads of a two-part upload, the first at the
+// minimum part size.
+func raceParts() []string {
+
+	return []string{strings.Repeat("a", 5*1024*1024), "tail"}
+}
+
+// assertWholeBody checks bucket/key reads, and lists, as exactly one of
+// bodies and returns its index.
+func (suite *baseSuite) assertWholeBody(bucket string, key string, bodies []string) int {
+
+	assert := suite
+
+	data, err := helpers.GetObject(svc, bucket, key)
+	assert.Nil(err)
+	winner := helpers.RaceWinner(data, bodies)
+	assert.NotEqual(-1, winner, "%s/%s holds a mix of writers (%d bytes)", bucket, key, len(data))
+
+	objects, err := helpers.ListObjects(svc, bucket)
+	assert.Nil(err)
+	if assert.Equal(1, len(objects)) && winner >= 0 {
+		assert.Equal(key, aws.StringValue(objects[0].Key))
+		assert.Equal(int64(len(bodies[winner])), aws.Int64Value(objects[0].Size))
+	}
+
+	return winner
+}
+
+func (suite *ResilienceSuite) TestRaceSameKeyPut() {
+
+	/*
+		Resource : object, method: put
+		Scenario : many goroutines PUT different 1MB bodies to the same key at once.
+		Assertion: every PUT succeeds; the object is one writer's whole body and
+			carries that writer's ETag.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	bodies := make([]string, raceWriters)
+	etags := make([]string, raceWriters)
+	for i := range bodies {
+		bodies[i] = helpers.RaceBody(i, 1024*1024)
+	}
+
+	errs := helpers.RunConcurrently(raceWriters, func(i int) error {
+		out, err := helpers.PutObjectWithMetadata(svc, bucket, key, bodies[i], "", nil)
+		if err == nil {
+			etags[i] = aws.StringValue(out.ETag)
+		}
+		return err
+	})
+	assert.Equal(raceWriters, len(helpers.Succeeded(errs)), "%v", errs)
+
+	winner := suite.assertWholeBody(bucket, key, bodies)
+	if winner >= 0 {
+		head, err := helpers.HeadObject(svc, bucket, key)
+		assert.Nil(err)
+		assert.Equal(etags[winner], aws.StringValue(head.ETag))
+	}
+}
+
+func (suite *ResilienceSuite) TestRaceCompleteMultipartSameUpload() {
+
+	/*
+		Resource : object, method: complete multipart upload
+		Scenario : many goroutines complete the same upload ID at once.
+		Assertion: at least one succeeds, the others fail with NoSuchUpload or
+			return the same ETag; the object is the whole upload.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+	payloads := raceParts()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	uploadID, parts, err := helpers.PrepareMultipart(svc, bucket, key, payloads)
+	suite.Require().Nil(err)
+
+	etags := make([]string, raceWriters)
+	errs := helpers.RunConcurrently(raceWriters, func(i int) error {
+		out, err := helpers.CompleteParts(svc, bucket, key, uploadID, parts)
+		if err == nil {
+			etags[i] = aws.StringValue(out.ETag)
+		}
+		return err
+	})
+
+	succeeded := helpers.Succeeded(errs)
+	suite.Require().NotEmpty(succeeded, "%v", errs)
+	for i, err := range errs {
+		if err != nil {
+			suite.assertS3Error(err, helpers.ExpectError("NoSuchUpload"))
+			continue
+		}
+		assert.Equal(etags[succeeded[0]], etags[i])
+	}
+
+	suite.assertWholeBody(bucket, key, []string{strings.Join(payloads, "")})
+}
+
+func (suite *ResilienceSuite) TestRaceAbortVersusComplete() {
+
+	/*
+		Resource : object, method: abort multipart upload, complete multipart upload
+		Scenario : for several uploads, goroutines abort and complete the same upload ID at once.
+		Assertion: if any complete succeeded the object is the whole upload, otherwise it
+			does not exist; losers fail with NoSuchUpload and no upload is left behind.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	payloads := raceParts()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, key := range []string{"key1", "key2", "key3", "key4"} {
+		uploadID, parts, err := helpers.PrepareMultipart(svc, bucket, key, payloads)
+		suite.Require().Nil(err)
+
+		errs := helpers.RunConcurrently(raceWriters, func(i int) error {
+			if i%2 == 0 {
+				_, err := helpers.AbortMultiPartUpload(svc, bucket, key, uploadID)
+				return err
+			}
+			_, err := helpers.CompleteParts(svc, bucket, key, uploadID, parts)
+			return err
+		})
+
+		completed := false
+		for i, err := range errs {
+			if err != nil {
+				suite.assertS3Error(err, helpers.ExpectError("NoSuchUpload"))
+			} else if i%2 == 1 {
+				completed = true
+			}
+		}
+
+		if completed {
+			data, err := helpers.GetObject(svc, bucket, key)
+			assert.Nil(err, key)
+			assert.Equal(strings.Join(payloads, ""), data, key)
+		} else {
+			suite.assertNotFound(bucket, key)
+		}
+	}
+
+	uploads, err := helpers.ListMultipartUploads(svc, bucket)
+	assert.Nil(err)
+	assert.Equal(0, len(uploads.Uploads))
+}
+
+func (suite *ResilienceSuite) TestRaceCreateSameBucket() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : many goroutines create the same bucket name at once.
+		Assertion: exactly one succeeds, the others fail with BucketAlreadyOwnedByYou;
+			the bucket is listed once and is usable.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	errs := helpers.RunConcurrently(raceWriters, func(i int) error {
+		return helpers.CreateBucket(svc, bucket)
+	})
+
+	assert.Equal(1, len(helpers.Succeeded(errs)), "%v", errs)
+	for _, err := range errs {
+		if err != nil {
+			suite.assertS3Error(err, helpers.ExpectError("BucketAlreadyOwnedByYou"))
+		}
+	}
+
+	buckets, err := helpers.ListBuckets(svc)
+	assert.Nil(err)
+	listed := 0
+	for _, name := range buckets {
+		if name == bucket {
+			listed++
+		}
+	}
+	assert.Equal(1, listed)
+
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+}
+
+func (suite *ResilienceSuite) TestRaceCreateSameBucketTwoOwners() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : the main and alt users race to create the same bucket name.
+		Assertion: exactly one owns it, the other fails with BucketAlreadyExists
+			and cannot write to it.
+		Tags     : slow
+	*/
+
+	assert := suite
+	if !helpers.HasAltUser() {
+		suite.T().Skip("s3alt user is not configured")
+	}
+	bucket := suite.bucketName()
+	clients := []*s3.S3{svc, altSvc}
+
+	errs := helpers.RunConcurrently(len(clients), func(i int) error {
+		return helpers.CreateBucket(clients[i], bucket)
+	})
+
+	succeeded := helpers.Succeeded(errs)
+	suite.Require().Equal(1, len(succeeded), "%v", errs)
+	owner, loser := clients[succeeded[0]], clients[1-succeeded[0]]
+	suite.assertS3Error(errs[1-succeeded[0]], helpers.ExpectError("BucketAlreadyExists"))
+
+	err := helpers.PutObjectToBucket(owner, bucket, "key1", "bar")
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(loser, bucket, "key2", "bar")
+	suite.assertS3Error(err, helpers.ExpectError("AccessDenied"))
+
+	if owner == altSvc {
+		err = helpers.DeleteObjects(altSvc, bucket)
+		assert.Nil(err)
+		err = helpers.DeleteBucket(altSvc, bucket)
+		assert.Nil(err)
+	}
+}
+
+func (suite *ResilienceSuite) TestRaceDeleteVersusPut() {
+
+	/*
+		Resource : object, method: put, delete
+		Scenario : goroutines delete and PUT different bodies to an existing key at once.
+		Assertion: the key is either gone, from both GET and LIST, or one PUT's
+			whole body; the original content never survives.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for round := 0; round < 4; round++ {
+		original := helpers.RaceBody(raceWriters, 256*1024)
+		err = helpers.PutObjectToBucket(svc, bucket, key, original)
+		suite.Require().Nil(err)
+
+		bodies := make([]string, raceWriters)
+		for i := range bodies {
+			bodies[i] = helpers.RaceBody(i, 256*1024)
+		}
+
+		errs := helpers.RunConcurrently(raceWriters, func(i int) error {
+			if i%2 == 0 {
+				return helpers.DeleteObject(svc, bucket, key)
+			}
+			return helpers.PutObjectToBucket(svc, bucket, key, bodies[i])
+		})
+		assert.Equal(raceWriters, len(helpers.Succeeded(errs)), "%v", errs)
+
+		_, err := helpers.GetObject(svc, bucket, key)
+		if err != nil {
+			suite.assertS3Error(err, helpers.ExpectError("NoSuchKey"))
+			_, keys, err := helpers.GetKeys(svc, bucket)
+			assert.Nil(err)
+			assert.Equal(0, len(keys))
+			continue
+		}
+
+		winner := suite.assertWholeBody(bucket, key, bodies)
+		assert.True(winner < 0 || winner%2 == 1, "round %d: body of a deleter", round)
+	}
+}
@@ -0,0 +1,262 @@
+package s3test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// multipartPayloads are the parts of mymultipart in the ObjectSuite
+// fixture: a minimum-size part and a short last one.
+var multipartPayloads = []string{strings.Repeat("a", 5*1024*1024), strings.Repeat("b", 1024)}
+
+// assertRange reads rangeValue of bucket/key and checks status, headers and
+// body against content as RFC 7233 prescribes for a single range.
+func (suite *baseSuite) assertRange(bucket string, key string, content string, rangeValue string) {
+
+	assert := suite
+	size := int64(len(content))
+
+	start, end, ok, err := helpers.ParseByteRange(rangeValue, size)
+	suite.Require().Nil(err)
+
+	resp, err := helpers.GetRange(svc, bucket, key, helpers.RangeRead{Range: rangeValue})
+	assert.Nil(err, rangeValue)
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		assert.Equal(http.StatusRequestedRangeNotSatisfiable, resp.Status, rangeValue)
+		assert.Equal("InvalidRange", resp.Code, rangeValue)
+		return
+	}
+
+	assert.Equal(http.StatusPartialContent, resp.Status, rangeValue)
+	assert.Equal(helpers.ContentRange(start, end, size), resp.ContentRange, rangeValue)
+	assert.Equal(end-start+1, resp.ContentLength, rangeValue)
+	assert.True(resp.Body == content[start:end+1], rangeValue)
+}
+
+func (suite *ObjectSuite) TestRangeSingleRanges() {
+
+	/*
+		Resource : object, method: get
+		Scenario : closed, open-ended, suffix and out-of-bounds byte ranges.
+		Assertion: 206 with matching Content-Range and Content-Length, or 416 InvalidRange.
+	*/
+
+	bucket := suite.reads
+	key := "key"
+	content := "testcontent"
+
+	ranges := []string{
+		"bytes=0-0",
+		"bytes=4-7",
+		"bytes=4-",
+		"bytes=10-",
+		"bytes=4-100",
+		"bytes=-8",
+		"bytes=-11",
+		"bytes=-100",
+		"bytes=11-",
+		"bytes=40-50",
+	}
+	for _, r := range ranges {
+		suite.assertRange(bucket, key, content, r)
+	}
+}
+
+func (suite *ObjectSuite) TestRangeZeroByteObject() {
+
+	/*
+		Resource : object, method: get
+		Scenario : byte ranges, including suffix ranges, on a zero-byte object.
+		Assertion: every range is unsatisfiable; a plain GET still returns 200.
+	*/
+
+	assert := suite
+	bucket := suite.reads
+	key := "empty"
+
+	for _, r := range []string{"bytes=0-", "bytes=0-0", "bytes=-1", "bytes=-100"} {
+		suite.assertRange(bucket, key, "", r)
+	}
+
+	resp, err := helpers.GetRange(svc, bucket, key, helpers.RangeRead{})
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	assert.Equal(int64(0), resp.ContentLength)
+}
+
+func (suite *ObjectSuite) TestRangeMultipartObject() {
+
+	/*
+		Resource : object, method: get
+		Scenario : byte ranges inside and across the parts of a multipart object.
+		Assertion: ranges are resolved against the whole object, not per part.
+	*/
+
+	bucket := suite.reads
+	key := "mymultipart"
+	payloads := multipartPayloads
+	content := payloads[0] + payloads[1]
+
+	boundary := len(payloads[0])
+	ranges := []string{
+		"bytes=0-9",
+		"bytes=" + strconv.Itoa(boundary-10) + "-" + strconv.Itoa(boundary+9),
+		"bytes=" + strconv.Itoa(boundary) + "-",
+		"bytes=-2000",
+		"bytes=" + strconv.Itoa(len(content)) + "-",
+	}
+	for _, r := range ranges {
+		suite.assertRange(bucket, key, content, r)
+	}
+}
+
+func (suite *ObjectSuite) TestRangePartNumber() {
+
+	/*
+		Resource : object, method: get, head
+		Scenario : GET and HEAD with partNumber on a multipart object.
+		Assertion: 206 with the part's Content-Range and Content-Length and x-amz-mp-parts-count;
+			a part number past the last part is 416 InvalidPartNumber.
+	*/
+
+	assert := suite
+	bucket := suite.reads
+	key := "mymultipart"
+	payloads := multipartPayloads
+	size := int64(len(payloads[0]) + len(payloads[1]))
+
+	var start int64
+	for i, payload := range payloads {
+		end := start + int64(len(payload)) - 1
+		read := helpers.RangeRead{PartNumber: int64(i + 1)}
+
+		got, err := helpers.GetRange(svc, bucket, key, read)
+		assert.Nil(err)
+		assert.Equal(http.StatusPartialContent, got.Status)
+		assert.Equal(helpers.ContentRange(start, end, size), got.ContentRange)
+		assert.Equal(int64(len(payload)), got.ContentLength)
+		assert.Equal(int64(len(payloads)), got.PartsCount)
+		assert.True(got.Body == payload)
+
+		head, err := helpers.HeadRange(svc, bucket, key, read)
+		assert.Nil(err)
+		assert.Equal(http.StatusPartialContent, head.Status)
+		assert.Equal(helpers.ContentRange(start, end, size), head.ContentRange)
+		assert.Equal(int64(len(payload)), head.ContentLength)
+		assert.Equal(int64(len(payloads)), head.PartsCount)
+
+		start = end + 1
+	}
+
+	got, err := helpers.GetRange(svc, bucket, key, helpers.RangeRead{PartNumber: int64(len(payloads) + 1)})
+	assert.Nil(err)
+	assert.Equal(http.StatusRequestedRangeNotSatisfiable, got.Status)
+	assert.Equal("InvalidPartNumber", got.Code)
+}
+
+func (suite *ObjectSuite) TestRangePartNumberSinglePartObject() {
+
+	/*
+		Resource : object, method: get
+		Scenario : partNumber=1 on an object written by a plain PUT.
+		Assertion: the whole object is returned with a parts count of at most 1.
+	*/
+
+	assert := suite
+	bucket := suite.reads
+	key := "key"
+	content := "testcontent"
+
+	got, err := helpers.GetRange(svc, bucket, key, helpers.RangeRead{PartNumber: 1})
+	assert.Nil(err)
+	assert.True(got.Status == http.StatusOK || got.Status == http.StatusPartialContent)
+	assert.Equal(int64(len(content)), got.ContentLength)
+	assert.True(got.PartsCount <= 1)
+	assert.Equal(content, got.Body)
+}
+
+func (suite *ObjectSuite) TestRangeWithConditions() {
+
+	/*
+		Resource : object, method: get
+		Scenario : a byte range combined with If-Match and If-None-Match.
+		Assertion: preconditions are checked before the range: 206, 412 or 304.
+	*/
+
+	assert := suite
+	bucket := suite.reads
+	key := "key"
+	content := "testcontent"
+
+	head, err := helpers.HeadObject(svc, bucket, key)
+	suite.Require().Nil(err)
+	etag := aws.StringValue(head.ETag)
+
+	cases := []struct {
+		rangeValue string
+		cond       helpers.Conditions
+		status     int
+	}{
+		{"bytes=4-7", helpers.Conditions{IfMatch: etag}, http.StatusPartialContent},
+		{"bytes=4-7", helpers.Conditions{IfMatch: `"ABCORZ"`}, http.StatusPreconditionFailed},
+		{"bytes=4-7", helpers.Conditions{IfNoneMatch: etag}, http.StatusNotModified},
+		{"bytes=4-7", helpers.Conditions{IfNoneMatch: `"ABCORZ"`}, http.StatusPartialContent},
+		// The precondition fails before the unsatisfiable range is looked at.
+		{"bytes=40-50", helpers.Conditions{IfMatch: `"ABCORZ"`}, http.StatusPreconditionFailed},
+		{"bytes=40-50", helpers.Conditions{IfMatch: etag}, http.StatusRequestedRangeNotSatisfiable},
+	}
+
+	for _, c := range cases {
+		got, err := helpers.GetRange(svc, bucket, key, helpers.RangeRead{Range: c.rangeValue, Conditions: c.cond})
+		assert.Nil(err)
+		assert.Equal(c.status, got.Status, "%s %v", c.rangeValue, c.cond.Headers())
+		if c.status == http.StatusPartialContent {
+			assert.Equal(helpers.ContentRange(4, 7, int64(len(content))), got.ContentRange)
+			assert.Equal(content[4:8], got.Body)
+		}
+	}
+}
+
+func (suite *ObjectSuite) TestRangeMultipleRanges() {
+
+	/*
+		Resource : object, method: get
+		Scenario : a Range header with more than one byte range.
+		Assertion: the target either ignores it (200, whole object), serves it
+			(206 multipart/byteranges) or rejects it with a 4xx; which one is logged.
+	*/
+
+	assert := suite
+	bucket := suite.reads
+	key := "key"
+	content := "testcontent"
+
+	got, err := helpers.GetRange(svc, bucket, key, helpers.RangeRead{Range: "bytes=0-1,4-5"})
+	assert.Nil(err)
+
+	switch {
+	case got.Status == http.StatusOK:
+		suite.T().Logf("multi-range ignored: 200 with the whole object")
+		assert.Equal(content, got.Body)
+		assert.Equal(int64(len(content)), got.ContentLength)
+	case got.Status == http.StatusPartialContent && strings.HasPrefix(got.ContentType, "multipart/byteranges"):
+		suite.T().Logf("multi-range served as %s", got.ContentType)
+		assert.True(strings.Contains(got.Body, "te"))
+		assert.True(strings.Contains(got.Body, "co"))
+	case got.Status == http.StatusPartialContent:
+		suite.T().Logf("multi-range reduced to one range: %s", got.ContentRange)
+		assert.Equal(helpers.ContentRange(0, 1, int64(len(content))), got.ContentRange)
+		assert.Equal("te", got.Body)
+	default:
+		suite.T().Logf("multi-range rejected: %d %s", got.Status, got.Code)
+		assert.True(got.Status >= 400 && got.Status < 500)
+	}
+}
@@ -0,0 +1,179 @@
+package s3test
+
+import (
+	"net/http"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// rawClient signs as the s3main user but writes requests byte for byte,
+// bypassing the header checks of the SDK and net/http.
+var rawClient = helpers.NewRawClient(helpers.Creds)
+
+func (suite *ObjectSuite) TestRawGetMissingKeyError() {
+
+	/*
+		Resource : object, method: get
+		Scenario : raw GET of a key that does not exist.
+		Assertion: 404 with an XML error document carrying NoSuchKey and the resource.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewRawRequest("GET", bucket, "missing", ""))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("NoSuchKey").WithResource("/"+bucket+"/missing"))
+}
+
+func (suite *ObjectSuite) TestRawDuplicateContentLength() {
+
+	/*
+		Resource : object, method: put
+		Scenario : two Content-Length headers with different values.
+		Assertion: fails with 400, nothing stored.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	req := helpers.NewRawRequest("PUT", bucket, key, "bar").
+		Header("Content-Length", "3").
+		Header("Content-Length", "2")
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusBadRequest, resp.Status)
+	suite.assertNotFound(bucket, key)
+}
+
+func (suite *ObjectSuite) TestRawDuplicateMetadataHeader() {
+
+	/*
+		Resource : object, method: put
+		Scenario : the same x-amz-meta header sent twice.
+		Assertion: succeeds and both values are kept, comma-joined.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	req := helpers.NewRawRequest("PUT", bucket, key, "bar").
+		Header("X-Amz-Meta-Foo", "one").
+		Header("X-Amz-Meta-Foo", "two")
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	head, err := helpers.HeadObject(svc, bucket, key)
+	assert.Nil(err)
+	assert.Equal("one,two", helpers.NormalizeMetadata(head.Metadata)["foo"])
+}
+
+func (suite *ObjectSuite) TestRawMissingHost() {
+
+	/*
+		Resource : object, method: get
+		Scenario : HTTP/1.1 request without a Host header.
+		Assertion: fails with 400 as RFC 7230 requires.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	req := helpers.NewRawRequest("GET", bucket, "", "")
+	req.NoHost = true
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusBadRequest, resp.Status)
+}
+
+func (suite *ObjectSuite) TestRawHTTP10() {
+
+	/*
+		Resource : object, method: put, get
+		Scenario : PUT and GET over HTTP/1.0.
+		Assertion: both succeed and the object round-trips.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	req := helpers.NewRawRequest("PUT", bucket, key, "bar")
+	req.Proto = "HTTP/1.0"
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	req = helpers.NewRawRequest("GET", bucket, key, "")
+	req.Proto = "HTTP/1.0"
+	resp, err = rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	assert.Equal("bar", string(resp.Body))
+}
+
+func (suite *ObjectSuite) TestRawExpectContinue() {
+
+	/*
+		Resource : object, method: put
+		Scenario : PUT with Expect: 100-continue.
+		Assertion: the gateway sends 100 Continue before the body, then 200.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewRawRequest("PUT", bucket, key, "bar").Header("Expect", "100-continue"))
+	assert.Nil(err)
+	assert.True(resp.Continue)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	data, err := helpers.GetObject(svc, bucket, key)
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
+
+func (suite *ObjectSuite) TestRawExpectContinueRejected() {
+
+	/*
+		Resource : object, method: put
+		Scenario : unsigned PUT with Expect: 100-continue to a private bucket.
+		Assertion: 403 AccessDenied; the body is not needed to reject it.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "key1"
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	anonymous := helpers.NewRawClient(nil)
+	resp, err := anonymous.Do(helpers.NewRawRequest("PUT", bucket, key, "bar").Header("Expect", "100-continue"))
+	assert.Nil(err)
+	assert.Equal(http.StatusForbidden, resp.Status)
+	assert.Equal("AccessDenied", resp.Code())
+	suite.assertNotFound(bucket, key)
+}
@@ -1,38 +1,278 @@
 package s3test
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/huangnauh/go_s3tests/helpers"
 	"github.com/stretchr/testify/suite"
 )
 
 var svc = helpers.GetConn()
+var altSvc = helpers.GetAltConn()
 
-type S3Suite struct {
-	suite.Suite
+// currentStyle is the addressing style of the pass TestSuite is running;
+// clients a test builds itself should use it.
+var currentStyle = helpers.AddressingStyles()[0]
+
+// testResults collects the outcome of every test for -s3tests.report.
+var testResults *helpers.TestResults
+
+// TestMain stops before any test runs when the config is missing or
+// invalid, listing every problem with it. It then narrows the run to the
+// tests picked by the selection flags, and writes the report of the
+// results when asked to.
+func TestMain(m *testing.M) {
+
+	if err := helpers.ConfigErr(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	flag.Parse()
+	infos, err := helpers.ParseTestInfo(".")
+	if err == nil {
+		err = selectTests(infos)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if helpers.ListTests() {
+		os.Exit(0)
+	}
+
+	testResults = helpers.NewTestResults(infos)
+	code := m.Run()
+
+	if path := helpers.ReportPath(); path != "" {
+		if err := testResults.WriteReport(path); err != nil {
+			fmt.Fprintln(os.Stderr, "report not written:", err)
+		}
+	}
+
+	os.Exit(code)
 }
 
-func (suite *S3Suite) SetupTest() {
+// selectTests sets -testify.m to the tests of the selection, and prints
+// them with their operations for -s3tests.list.
+func selectTests(infos map[string]helpers.TestInfo) error {
+
+	sel, err := helpers.GetSelection()
+	if err != nil {
+		return err
+	}
 
+	names := sel.Select(infos)
+	if helpers.ListTests() {
+		for _, name := range names {
+			fmt.Printf("%s\t%s\t%s\n", name, strings.Join(infos[name].Operations(), ", "), strings.Join(infos[name].Tags, ", "))
+		}
+		return nil
+	}
+	if sel.Empty() {
+		return nil
+	}
+
+	match := flag.Lookup("testify.m")
+	if match.Value.String() != "" {
+		return fmt.Errorf("-testify.m cannot be combined with the selection flags")
+	}
+	if run := flag.Lookup("test.run"); run.Value.String() == "" {
+		run.Value.Set("^TestSuite$")
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "no tests match the selection")
+	}
+
+	return match.Value.Set(helpers.MethodPattern(names))
 }
 
-type HeadSuite struct {
+// baseSuite holds what the feature suites share: the cassette of each
+// test, the buckets the test created and deletes when it ends, and the
+// fixtures of the suite.
+type baseSuite struct {
 	suite.Suite
+	buckets  []string
+	fixtures []string
 }
 
+// The feature suites. Each runs its tests against the buckets they create;
+// the suites with a SetupSuite also share read-only fixture buckets.
+type (
+	BucketSuite     struct{ baseSuite }
+	EncryptionSuite struct{ baseSuite }
+	ResilienceSuite struct{ baseSuite }
+	// AuthSuite has no fixtures so that its signer tests run without a
+	// gateway.
+	AuthSuite   struct{ baseSuite }
+	ObjectSuite struct {
+		baseSuite
+		reads string
+	}
+	MultipartSuite struct {
+		baseSuite
+		uploads string
+	}
+	ConditionalSuite struct {
+		baseSuite
+		target string
+	}
+	ListingSuite struct {
+		baseSuite
+		markers  string
+		maxKeys  string
+		prefixes string
+	}
+)
+
+// TestSuite runs the suites once per configured addressing style, as
+// subtests named after the style when there is more than one.
 func TestSuite(t *testing.T) {
 
-	suite.Run(t, new(HeadSuite))
-	suite.Run(t, new(S3Suite))
+	styles := helpers.AddressingStyles()
+	for _, style := range styles {
+		currentStyle = style
+		svc = helpers.NewStyledConn(helpers.Creds, style)
+		altSvc = helpers.NewStyledConn(helpers.AltCreds, style)
+		anonSvc = helpers.NewStyledConn(credentials.AnonymousCredentials, style)
+		if len(styles) == 1 {
+			runSuites(t)
+			continue
+		}
+		t.Run(string(style), runSuites)
+	}
+}
+
+func runSuites(t *testing.T) {
+
+	suite.Run(t, new(ObjectSuite))
+	suite.Run(t, new(BucketSuite))
+	suite.Run(t, new(MultipartSuite))
+	suite.Run(t, new(ListingSuite))
+	suite.Run(t, new(ConditionalSuite))
+	suite.Run(t, new(AuthSuite))
+	suite.Run(t, new(EncryptionSuite))
+	suite.Run(t, new(ResilienceSuite))
+}
+
+// fixture creates a bucket holding objects for the tests of the suite to
+// read, kept until the suite ends. Replay finds it in the cassettes.
+func (suite *baseSuite) fixture(name string, objects map[string]string) string {
+
+	bucket := helpers.FixtureBucketName(name)
+	if helpers.Cassettes.Mode != helpers.Replay {
+		err := helpers.CreateFixtureBucket(svc, bucket, objects)
+		suite.Require().Nil(err, "fixture %s", bucket)
+	}
+	suite.fixtures = append(suite.fixtures, bucket)
+
+	return bucket
+}
+
+// bucketName returns a new bucket name for the test; the bucket is
+// deleted with its objects when the test ends.
+func (suite *baseSuite) bucketName() string {
+
+	return suite.trackBucket(helpers.GetBucketName())
+}
+
+// trackBucket deletes bucket, if the test creates it, when the test ends.
+func (suite *baseSuite) trackBucket(bucket string) string {
+
+	suite.buckets = append(suite.buckets, bucket)
+
+	return bucket
+}
+
+func (suite *baseSuite) SetupTest() {
+
+	suite.buckets = nil
+	suite.Require().Nil(helpers.Cassettes.Start(suite.T().Name()))
+}
+
+// TearDownTest deletes the buckets of the test, trying the alt user for
+// those the main user cannot delete.
+func (suite *baseSuite) TearDownTest() {
+
+	defer recordResult(&suite.Suite)
+	left, errs := helpers.DeleteBuckets(svc, suite.buckets...)
+	if len(left) > 0 && helpers.HasAltUser() {
+		left, errs = helpers.DeleteBuckets(altSvc, left...)
+	}
+	for _, err := range errs {
+		suite.T().Logf("cleanup: %v", err)
+	}
+	suite.buckets = nil
+	stopCassette(&suite.Suite)
+}
+
+func (suite *baseSuite) TearDownSuite() {
+
+	if helpers.Cassettes.Mode != helpers.Replay {
+		_, errs := helpers.DeleteBuckets(svc, suite.fixtures...)
+		for _, err := range errs {
+			suite.T().Logf("cleanup: %v", err)
+		}
+	}
+	suite.fixtures = nil
+}
+
+// SetupSuite creates the bucket the read tests share: a small object, an
+// empty one and a two-part multipart object.
+func (suite *ObjectSuite) SetupSuite() {
+
+	suite.reads = suite.fixture("object-reads", map[string]string{"key": "testcontent", "empty": ""})
+	if helpers.Cassettes.Mode != helpers.Replay {
+		_, err := helpers.MultipartUploadParts(svc, suite.reads, "mymultipart", multipartPayloads)
+		suite.Require().Nil(err, "fixture %s", suite.reads)
+	}
+}
+
+// SetupSuite creates an empty bucket for the tests on uploads that do not
+// exist.
+func (suite *MultipartSuite) SetupSuite() {
+
+	suite.uploads = suite.fixture("multipart", nil)
+}
+
+// SetupSuite creates the object the conditional reads are checked against.
+func (suite *ConditionalSuite) SetupSuite() {
+
+	suite.target = suite.fixture("conditional", map[string]string{"foo": "bar"})
+}
+
+// SetupSuite creates the buckets the listing tests read: each holds the
+// objects of a family of listing tests.
+func (suite *ListingSuite) SetupSuite() {
+
+	suite.markers = suite.fixture("listing-markers", map[string]string{"bar": "echo", "baz": "lima", "quux": "golf"})
+	suite.maxKeys = suite.fixture("listing-maxkeys", map[string]string{"key1": "echo", "key2": "lima", "key3": "golf"})
+	suite.prefixes = suite.fixture("listing-prefixes", map[string]string{"foo/bar": "echo", "foo/baz": "lima", "quux": "golf"})
 }
 
-func (suite *S3Suite) TearDownTest() {
+// stopCassette closes the cassette of the finished test, after the cleanup
+// so that replay sees the same requests.
+func stopCassette(s *suite.Suite) {
 
-	helpers.DeletePrefixedBuckets(svc)
+	path, err := helpers.Cassettes.Stop(s.T().Failed())
+	if err != nil {
+		s.T().Logf("cassette not written: %v", err)
+	} else if path != "" {
+		s.T().Logf("cassette written to %s", path)
+	}
 }
 
-func (suite *HeadSuite) TearDownTest() {
+// recordResult notes the outcome of the finished test for the report,
+// failing the test when its cleanup panicked.
+func recordResult(s *suite.Suite) {
 
-	helpers.DeletePrefixedBuckets(svc)
+	if r := recover(); r != nil {
+		s.T().Errorf("cleanup: %v", r)
+	}
+	testResults.Record(s.T().Name(), s.T().Failed(), s.T().Skipped())
 }
@@ -0,0 +1,104 @@
+package s3test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+func (suite *MultipartSuite) TestLargeObjectStreamingIntegrity() {
+
+	/*
+		Resource : object, method: put/get
+		Scenario : stream seeded pseudo-random objects with the managed uploader and downloader
+			across the configured size tier, part sizes and concurrency.
+		Assertion: size, multipart etag and streaming hashes match the generated content.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+
+	conf, err := helpers.GetStreamConfig()
+	assert.Nil(err)
+	suite.T().Logf("stream seed %d, sizes %v", conf.Seed, conf.Sizes)
+
+	err = helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	for _, size := range conf.Sizes {
+		for _, partSize := range conf.PartSizes {
+			for _, concurrency := range conf.Concurrency {
+				key := fmt.Sprintf("stream/%d-%d-%d", size, partSize, concurrency)
+				seed := conf.Seed + size
+
+				_, err = helpers.UploadPattern(svc, bucket, key, seed, size, partSize, concurrency)
+				if !assert.Nil(err, key) {
+					continue
+				}
+
+				head, err := svc.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+				assert.Nil(err, key)
+				assert.Equal(size, aws.Int64Value(head.ContentLength), key)
+
+				etag := strings.Trim(aws.StringValue(head.ETag), `"`)
+				if size > partSize {
+					parts := (size + partSize - 1) / partSize
+					assert.True(strings.HasSuffix(etag, fmt.Sprintf("-%d", parts)), "%s: etag %s", key, etag)
+				} else {
+					assert.False(strings.Contains(etag, "-"), "%s: etag %s", key, etag)
+				}
+
+				err = helpers.DownloadPattern(svc, bucket, key, seed, size, partSize, concurrency)
+				assert.Nil(err, key)
+
+				hash, n, err := helpers.GetObjectHash(svc, bucket, key)
+				assert.Nil(err, key)
+				assert.Equal(size, n, key)
+				assert.Equal(helpers.PatternHash(seed, size), hash, key)
+
+				err = helpers.DeleteObject(svc, bucket, key)
+				assert.Nil(err, key)
+			}
+		}
+	}
+}
+
+func (suite *MultipartSuite) TestLargeObjectRangedReads() {
+
+	/*
+		Resource : object, method: get
+		Scenario : ranged GETs across part boundaries of a streamed multipart object.
+		Assertion: every range matches the generated content at that offset.
+		Tags     : slow
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	key := "stream/ranged"
+
+	conf, err := helpers.GetStreamConfig()
+	assert.Nil(err)
+	partSize := conf.PartSizes[0]
+	size := 2*partSize + 1
+
+	err = helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	_, err = helpers.UploadPattern(svc, bucket, key, conf.Seed, size, partSize, 2)
+	assert.Nil(err)
+
+	pattern := helpers.NewPatternReader(conf.Seed, size)
+	for _, r := range [][2]int64{{0, 0}, {partSize - 1, partSize}, {partSize - 100, 2 * partSize}, {size - 1, size - 1}} {
+		want := make([]byte, r[1]-r[0]+1)
+		_, err = pattern.ReadAt(want, r[0])
+		assert.Nil(err)
+
+		_, data, err := helpers.GetObjectWithRange(svc, bucket, key, fmt.Sprintf("bytes=%d-%d", r[0], r[1]))
+		assert.Nil(err)
+		assert.True(data == string(want), "range %d-%d differs", r[0], r[1])
+	}
+}
@@ -0,0 +1,172 @@
+package s3test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+)
+
+// temporaryCredentials returns session credentials for the s3main user,
+// skipping the test when the config gives no way to obtain them.
+func (suite *baseSuite) temporaryCredentials() helpers.TemporaryCredentials {
+
+	creds, err := helpers.GetTemporaryCredentials()
+	if err == helpers.ErrNoTemporaryCredentials {
+		suite.T().Skip(err)
+	}
+	suite.Require().Nil(err)
+
+	return creds
+}
+
+// tokenRejections are the errors a gateway may refuse mismatched session
+// credentials with.
+var tokenRejections = []helpers.ErrorSpec{
+	helpers.ExpectError("InvalidToken").WithStatus(http.StatusForbidden),
+	helpers.ExpectError("InvalidAccessKeyId"),
+	helpers.ExpectError("SignatureDoesNotMatch"),
+}
+
+// assertTokenRejected fails the test unless err matches one of
+// tokenRejections, status included.
+func (suite *baseSuite) assertTokenRejected(err error) bool {
+
+	var diffs []string
+	for _, spec := range tokenRejections {
+		diff := helpers.CheckError(err, spec)
+		if diff == "" {
+			return true
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return suite.Fail("expected a rejected token", strings.Join(diffs, "\n"))
+}
+
+func (suite *AuthSuite) TestSessionTokenValid() {
+
+	/*
+		Resource : object, method: put, get, list
+		Scenario : use session credentials from AssumeRole on a bucket of the s3main user.
+		Assertion: the session credentials act as the user: objects are written, read and listed.
+	*/
+
+	assert := suite
+	temp := suite.temporaryCredentials()
+	tempSvc := helpers.NewStyledConn(temp.Credentials(), currentStyle)
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	err = helpers.PutObjectToBucket(tempSvc, bucket, "foo", "bar")
+	assert.Nil(err)
+
+	data, err := helpers.GetObject(tempSvc, bucket, "foo")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+
+	objects, err := helpers.ListObjects(tempSvc, bucket)
+	assert.Nil(err)
+	assert.Equal(1, len(objects))
+
+	data, err = helpers.GetObject(svc, bucket, "foo")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
+
+func (suite *AuthSuite) TestSessionTokenMismatch() {
+
+	/*
+		Resource : object, method: put
+		Scenario : sign with a tampered session token, and with the long-term key of the user
+			together with a valid session token.
+		Assertion: both requests are refused for their credentials and no object is written.
+	*/
+
+	assert := suite
+	temp := suite.temporaryCredentials()
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	tampered := temp
+	tampered.SessionToken += "x"
+
+	longTerm, err := helpers.Creds.Get()
+	suite.Require().Nil(err)
+	mixed := helpers.TemporaryCredentials{AccessKey: longTerm.AccessKeyID, SecretKey: longTerm.SecretAccessKey, SessionToken: temp.SessionToken}
+
+	for name, creds := range map[string]helpers.TemporaryCredentials{"tampered": tampered, "mixed": mixed} {
+		client := helpers.NewStyledConn(creds.Credentials(), currentStyle)
+		err = helpers.PutObjectToBucket(client, bucket, name, "bar")
+		suite.assertTokenRejected(err)
+		suite.assertNotFound(bucket, name)
+	}
+}
+
+func (suite *AuthSuite) TestSessionTokenExpired() {
+
+	/*
+		Resource : object, method: get
+		Scenario : sign with session credentials that expired, from sts.expired_*.
+		Assertion: the request fails with ExpiredToken.
+	*/
+
+	assert := suite
+	expired, ok := helpers.ExpiredCredentials()
+	if !ok {
+		suite.T().Skip("sts.expired_access_key, sts.expired_access_secret and sts.expired_session_token are not set")
+	}
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "foo", "bar")
+	assert.Nil(err)
+
+	client := helpers.NewStyledConn(expired.Credentials(), currentStyle)
+	_, err = helpers.GetObject(client, bucket, "foo")
+	suite.assertS3Error(err, helpers.ExpectError("ExpiredToken"))
+}
+
+func (suite *AuthSuite) TestSessionTokenPresignedURL() {
+
+	/*
+		Resource : object, method: get
+		Scenario : presign a GET with session credentials, then fetch the URL anonymously with
+			and without its X-Amz-Security-Token parameter.
+		Assertion: the URL carries the token and returns the object; without the token it is refused.
+	*/
+
+	assert := suite
+	temp := suite.temporaryCredentials()
+	tempSvc := helpers.NewStyledConn(temp.Credentials(), currentStyle)
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "foo", "bar")
+	assert.Nil(err)
+
+	presigned, err := helpers.GeneratePresignedUrlGetObject(tempSvc, bucket, "foo")
+	suite.Require().Nil(err)
+	u, err := url.Parse(presigned)
+	suite.Require().Nil(err)
+	assert.Equal(temp.SessionToken, u.Query().Get("X-Amz-Security-Token"))
+
+	resp, err := anonRaw.Do(helpers.NewURLRawRequest("GET", u, ""))
+	suite.Require().Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	assert.Equal("bar", string(resp.Body))
+
+	query := u.Query()
+	query.Del("X-Amz-Security-Token")
+	u.RawQuery = query.Encode()
+	resp, err = anonRaw.Do(helpers.NewURLRawRequest("GET", u, ""))
+	suite.Require().Nil(err)
+	assert.Equal(http.StatusForbidden, resp.Status)
+}
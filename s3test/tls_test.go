@@ -0,0 +1,108 @@
+package s3test
+
+import (
+	"github.com/huangnauh/go_s3tests/helpers"
+	"github.com/spf13/viper"
+)
+
+// requireTLS skips the test unless the suite talks TLS to the gateway.
+func (suite *baseSuite) requireTLS() {
+
+	if !viper.GetBool("s3main.is_secure") {
+		suite.T().Skip("s3main.is_secure is off")
+	}
+}
+
+func (suite *AuthSuite) TestTLSPlainHTTPRejected() {
+
+	/*
+		Resource : object, method: put
+		Scenario : send a signed plain-HTTP PUT to the TLS endpoint.
+		Assertion: the connection fails or the request is refused, and no object is written.
+	*/
+
+	assert := suite
+	suite.requireTLS()
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	plain := *rawClient
+	plain.Secure = false
+	resp, err := plain.Do(helpers.NewRawRequest("PUT", bucket, "plain", "bar"))
+	if err == nil {
+		assert.True(resp.Status >= 400, "plain HTTP answered %d", resp.Status)
+	}
+
+	suite.assertNotFound(bucket, "plain")
+}
+
+func (suite *AuthSuite) TestTLSVerifiesServerName() {
+
+	/*
+		Resource : service, method: list buckets
+		Scenario : connect over TLS expecting a server name the gateway certificate does not cover.
+		Assertion: the handshake fails; certificates are verified unless insecure mode is on.
+	*/
+
+	assert := suite
+	suite.requireTLS()
+	if viper.GetBool("tls.insecure_skip_verify") {
+		suite.T().Skip("tls.insecure_skip_verify is on")
+	}
+
+	client := *rawClient
+	client.TLS = rawClient.TLS.Clone()
+	client.TLS.ServerName = "name-not-in-certificate.invalid"
+
+	_, err := client.Do(helpers.NewRawRequest("GET", "", "", ""))
+	assert.NotNil(err)
+
+	_, err = rawClient.Do(helpers.NewRawRequest("GET", "", "", ""))
+	assert.Nil(err)
+}
+
+func (suite *AuthSuite) TestBucketPolicySecureTransport() {
+
+	/*
+		Resource : bucket, method: put policy
+		Resource : object, method: get, put
+		Scenario : deny GetObject and PutObject when aws:SecureTransport is true, then when
+			it is false, sending requests over the configured transport.
+		Assertion: only the policy matching the transport in use denies the requests;
+			removing it restores access.
+	*/
+
+	assert := suite
+	bucket := suite.bucketName()
+	secure := viper.GetBool("s3main.is_secure")
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+	err = helpers.PutObjectToBucket(svc, bucket, "key1", "bar")
+	assert.Nil(err)
+
+	for _, condition := range []bool{true, false} {
+		policy := helpers.SecureTransportPolicy(bucket, condition, "s3:GetObject", "s3:PutObject")
+		err = helpers.PutBucketPolicy(svc, bucket, policy)
+		suite.Require().Nil(err)
+
+		_, getErr := helpers.GetObject(svc, bucket, "key1")
+		putErr := helpers.PutObjectToBucket(svc, bucket, "key2", "bar")
+		if condition == secure {
+			suite.assertS3Error(getErr, helpers.ExpectError("AccessDenied"))
+			suite.assertS3Error(putErr, helpers.ExpectError("AccessDenied"))
+		} else {
+			assert.Nil(getErr, "aws:SecureTransport=%v", condition)
+			assert.Nil(putErr, "aws:SecureTransport=%v", condition)
+		}
+
+		err = helpers.DeleteBucketPolicy(svc, bucket)
+		assert.Nil(err)
+	}
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
@@ -0,0 +1,146 @@
+package s3test
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/huangnauh/go_s3tests/helpers"
+	"github.com/spf13/viper"
+)
+
+// requireVirtualHosting skips tests that need bucket hostnames when the
+// gateway is only reachable by IP.
+func (suite *baseSuite) requireVirtualHosting() {
+
+	if !helpers.HasVirtualHosting() {
+		suite.T().Skip("virtual-hosted style needs s3main.vhost_domain or a named endpoint")
+	}
+}
+
+func (suite *BucketSuite) TestVirtualHostedBucketWithDots() {
+
+	/*
+		Resource : object, method: put, get
+		Resource : bucket, method: create
+		Scenario : object written and read through a dotted bucket hostname.
+		Assertion: the object is stored in the dotted bucket and visible path-style.
+	*/
+
+	assert := suite
+	suite.requireVirtualHosting()
+	if viper.GetBool("s3main.is_secure") {
+		suite.T().Skip("wildcard certificates do not cover dotted bucket hostnames")
+	}
+	bucket := suite.trackBucket(helpers.GetBucketName() + ".with.dots")
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	resp, err := rawClient.Do(helpers.NewVirtualRawRequest("PUT", bucket, "key1", "bar"))
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	resp, err = rawClient.Do(helpers.NewVirtualRawRequest("GET", bucket, "key1", ""))
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	assert.Equal("bar", string(resp.Body))
+
+	data, err := helpers.GetObject(svc, bucket, "key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+}
+
+func (suite *BucketSuite) TestVirtualHostedCreateDottedBucket() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create a dotted bucket with a virtual-hosted client.
+		Assertion: succeeds and the bucket is listed.
+	*/
+
+	assert := suite
+	suite.requireVirtualHosting()
+	bucket := suite.trackBucket(helpers.GetBucketName() + ".a.b")
+	vhost := helpers.NewStyledConn(helpers.Creds, helpers.VirtualHostedStyle)
+
+	err := helpers.CreateBucket(vhost, bucket)
+	assert.Nil(err)
+
+	buckets, err := helpers.ListBuckets(svc)
+	assert.Nil(err)
+	assert.True(helpers.Contains(buckets, bucket))
+}
+
+func (suite *BucketSuite) TestVirtualHostedUppercaseBucket() {
+
+	/*
+		Resource : bucket, method: create
+		Scenario : create a bucket whose hostname label has uppercase letters.
+		Assertion: fails with InvalidBucketName; neither spelling is created.
+	*/
+
+	assert := suite
+	suite.requireVirtualHosting()
+	bucket := suite.bucketName()
+	upper := strings.ToUpper(bucket[:1]) + bucket[1:] + "Upper"
+
+	resp, err := rawClient.Do(helpers.NewVirtualRawRequest("PUT", upper, "", ""))
+	assert.Nil(err)
+	suite.assertRawError(resp, helpers.ExpectError("InvalidBucketName"))
+
+	buckets, err := helpers.ListBuckets(svc)
+	assert.Nil(err)
+	assert.False(helpers.Contains(buckets, upper))
+	assert.False(helpers.Contains(buckets, strings.ToLower(upper)))
+}
+
+func (suite *BucketSuite) TestVirtualHostedHostPathMismatch() {
+
+	/*
+		Resource : object, method: put
+		Scenario : Host names one bucket and the path starts with another.
+		Assertion: the Host wins; the path is the key inside the Host bucket.
+	*/
+
+	assert := suite
+	suite.requireVirtualHosting()
+	hostBucket := suite.bucketName()
+	pathBucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, hostBucket)
+	assert.Nil(err)
+	err = helpers.CreateBucket(svc, pathBucket)
+	assert.Nil(err)
+
+	req := helpers.NewVirtualRawRequest("PUT", hostBucket, pathBucket+"/key1", "bar")
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+
+	data, err := helpers.GetObject(svc, hostBucket, pathBucket+"/key1")
+	assert.Nil(err)
+	assert.Equal("bar", data)
+	suite.assertNotFound(pathBucket, "key1")
+}
+
+func (suite *BucketSuite) TestVirtualHostedListBucketsOnDomain() {
+
+	/*
+		Resource : service, method: list buckets
+		Scenario : GET / on the bare virtual host domain.
+		Assertion: lists the buckets of the user, like the path-style endpoint.
+	*/
+
+	assert := suite
+	suite.requireVirtualHosting()
+	bucket := suite.bucketName()
+
+	err := helpers.CreateBucket(svc, bucket)
+	assert.Nil(err)
+
+	req := helpers.NewVirtualRawRequest("GET", "", "", "")
+	resp, err := rawClient.Do(req)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.Status)
+	assert.Contains(string(resp.Body), "<Name>"+bucket+"</Name>")
+}